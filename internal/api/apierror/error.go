@@ -40,6 +40,30 @@ func NotFound(message string, err error) *APIError {
 	}
 }
 
+func Forbidden(message string, err error) *APIError {
+	return &APIError{
+		StatusCode: http.StatusForbidden,
+		Message:    message,
+		Err:        err,
+	}
+}
+
+func Conflict(message string, err error) *APIError {
+	return &APIError{
+		StatusCode: http.StatusConflict,
+		Message:    message,
+		Err:        err,
+	}
+}
+
+func UnprocessableEntity(message string, err error) *APIError {
+	return &APIError{
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    message,
+		Err:        err,
+	}
+}
+
 func InternalError(message string, err error) *APIError {
 	return &APIError{
 		StatusCode: http.StatusInternalServerError,
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// CustomFieldRequest — создать пользовательское поле или изменить его тип (имя не меняется)
+type CustomFieldRequest struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`                     // string, number, boolean, date или enum
+	AllowedValues []string `json:"allowed_values,omitempty"` // только для enum
+}
+
+// CustomFieldResponse — пользовательское поле в ответе
+type CustomFieldResponse struct {
+	ID            int       `json:"id"`
+	TreeID        int       `json:"tree_id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	AllowedValues []string  `json:"allowed_values,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CustomFieldListResponse — схема пользовательских полей дерева
+type CustomFieldListResponse struct {
+	Fields []CustomFieldResponse `json:"fields"`
+	Total  int                   `json:"total"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// EventRequest — создать или обновить событие
+type EventRequest struct {
+	Type          string     `json:"type"`                     // birth, baptism, residence... Не меняется при обновлении
+	PartnershipID *int       `json:"partnership_id,omitempty"` // событие союза (marriage, divorce...), только при создании
+	Date          *time.Time `json:"date,omitempty"`
+	DateText      string     `json:"date_text,omitempty"` // фраза GEDCOM, важнее date
+	PlaceID       *int       `json:"place_id,omitempty"`
+	Place         string     `json:"place,omitempty"` // название места, если place_id не задан; создаётся при необходимости
+	Description   string     `json:"description,omitempty"`
+}
+
+// EventResponse — событие в ответе
+type EventResponse struct {
+	ID            int        `json:"id"`
+	PersonID      *int       `json:"person_id,omitempty"`
+	PartnershipID *int       `json:"partnership_id,omitempty"`
+	Type          string     `json:"type"`
+	Date          *time.Time `json:"date,omitempty"`
+	DateText      string     `json:"date_text,omitempty"`
+	PlaceID       *int       `json:"place_id,omitempty"`
+	Place         string     `json:"place,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// EventListResponse — события персоны
+type EventListResponse struct {
+	Events []EventResponse `json:"events"`
+	Total  int             `json:"total"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+// ImportIssueResponse — пропущенная запись или ошибка валидации
+type ImportIssueResponse struct {
+	XRef    string `json:"xref,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// GedcomImportResponse — отчёт об импорте GEDCOM
+type GedcomImportResponse struct {
+	PersonsImported       int                   `json:"persons_imported"`
+	RelationshipsImported int                   `json:"relationships_imported"`
+	Skipped               []ImportIssueResponse `json:"skipped"`
+	Failures              []ImportIssueResponse `json:"failures"`
+	Warnings              []ImportIssueResponse `json:"warnings"`
+}
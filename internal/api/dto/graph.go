@@ -4,23 +4,41 @@ import "time"
 
 // GraphNodeResponse — узел графа (персона)
 type GraphNodeResponse struct {
-	ID        int        `json:"id"`
-	FirstName string     `json:"first_name"`
-	LastName  string     `json:"last_name"`
-	BirthDate *time.Time `json:"birth_date,omitempty"`
-	DeathDate *time.Time `json:"death_date,omitempty"`
-	IsMale    bool       `json:"is_male"`
+	ID            int        `json:"id"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	BirthDate     *time.Time `json:"birth_date,omitempty"`
+	BirthDateText string     `json:"birth_date_text,omitempty"`
+	DeathDate     *time.Time `json:"death_date,omitempty"`
+	DeathDateText string     `json:"death_date_text,omitempty"`
+	PortraitURL   string     `json:"portrait_url,omitempty"`
+	ThumbnailURL  string     `json:"thumbnail_url,omitempty"` // квадратное превью портрета для узла графа
+	Sex           string     `json:"sex"`
+	IsMale        bool       `json:"is_male"` // устарело: sex == "male"
 }
 
 // GraphEdgeResponse — связь (ребро графа)
 type GraphEdgeResponse struct {
+	ID               int    `json:"id"`
 	ParentID         int    `json:"parent_id"`
 	ChildID          int    `json:"child_id"`
 	RelationshipType string `json:"relationship_type"`
 }
 
+// GraphPartnershipResponse — союз двух персон (ребро графа между партнёрами)
+type GraphPartnershipResponse struct {
+	ID        int        `json:"id"`
+	Person1ID int        `json:"person1_id"`
+	Person2ID int        `json:"person2_id"`
+	Type      string     `json:"type"`
+	Status    string     `json:"status"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
 // GraphResponse — полный граф дерева
 type GraphResponse struct {
-	Nodes []GraphNodeResponse `json:"nodes"`
-	Edges []GraphEdgeResponse `json:"edges"`
+	Nodes        []GraphNodeResponse        `json:"nodes"`
+	Edges        []GraphEdgeResponse        `json:"edges"`
+	Partnerships []GraphPartnershipResponse `json:"partnerships"`
 }
@@ -0,0 +1,14 @@
+package dto
+
+// CycleResponse — цикл в связях: каждая следующая персона — ребенок предыдущей,
+// а первая — ребенок последней
+type CycleResponse struct {
+	PersonIDs []int `json:"person_ids"`
+}
+
+// IntegrityReportResponse — отчёт о целостности дерева
+type IntegrityReportResponse struct {
+	TreeID int             `json:"tree_id"`
+	Valid  bool            `json:"valid"`
+	Cycles []CycleResponse `json:"cycles"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// KinshipResponse — родство между двумя персонами дерева
+type KinshipResponse struct {
+	PersonAID       int    `json:"person_a_id"`
+	PersonBID       int    `json:"person_b_id"`
+	Related         bool   `json:"related"`
+	Relationship    string `json:"relationship,omitempty"` // кем B приходится A
+	GenerationsA    int    `json:"generations_a"`
+	GenerationsB    int    `json:"generations_b"`
+	CommonAncestors []int  `json:"common_ancestors"`
+	Path            []int  `json:"path"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// MediaResponse — файл персоны в ответе
+type MediaResponse struct {
+	ID          int    `json:"id"`
+	PersonID    int    `json:"person_id"`
+	URL         string `json:"url"` // адрес содержимого файла
+	ContentHash string `json:"content_hash"`
+	MimeType    string `json:"mime_type"`
+	Size        int64  `json:"size"`
+	FileName    string `json:"file_name,omitempty"`
+	Title       string `json:"title,omitempty"`
+	// Thumbnails — адреса превью по размеру в пикселях; есть только у JPEG и PNG
+	Thumbnails map[int]string `json:"thumbnails,omitempty"`
+	IsPortrait bool           `json:"is_portrait"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// MediaListResponse — файлы персоны
+type MediaListResponse struct {
+	Media []MediaResponse `json:"media"`
+	Total int             `json:"total"`
+}
+
+// SetPortraitRequest — выбрать основной портрет; null убирает портрет
+type SetPortraitRequest struct {
+	MediaID *int `json:"media_id"`
+}
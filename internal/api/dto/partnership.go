@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+// AddPartnerRequest — создать союз с существующей персоной
+type AddPartnerRequest struct {
+	PartnerID int        `json:"partner_id"`
+	Type      string     `json:"type"`   // по умолчанию "marriage"
+	Status    string     `json:"status"` // по умолчанию "active"
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
+// UpdatePartnerRequest — обновить тип, статус и даты союза
+type UpdatePartnerRequest struct {
+	Type      string     `json:"type"`
+	Status    string     `json:"status"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
+// PartnershipResponse — данные союза в ответе
+type PartnershipResponse struct {
+	ID        int        `json:"id"`
+	Person1ID int        `json:"person1_id"`
+	Person2ID int        `json:"person2_id"`
+	Type      string     `json:"type"`
+	Status    string     `json:"status"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// PartnerResponse — партнёр персоны вместе с союзом
+type PartnerResponse struct {
+	Person      PersonBriefResponse `json:"person"`
+	Partnership PartnershipResponse `json:"partnership"`
+}
+
+// PartnerListResponse — список партнёров персоны
+type PartnerListResponse struct {
+	Partners []PartnerResponse `json:"partners"`
+	Total    int               `json:"total"`
+}
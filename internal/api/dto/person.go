@@ -4,51 +4,79 @@ import "time"
 
 // CreatePersonRequest — данные для создания персоны
 type CreatePersonRequest struct {
-	FirstName string     `json:"first_name"`
-	LastName  string     `json:"last_name"`
-	BirthDate *time.Time `json:"birth_date,omitempty"`
-	DeathDate *time.Time `json:"death_date,omitempty"`
-	IsMale    bool       `json:"is_male"`
-	Biography string     `json:"biography,omitempty"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	BirthDate     *time.Time `json:"birth_date,omitempty"`
+	BirthDateText string     `json:"birth_date_text,omitempty"` // фраза GEDCOM: "ABT 1850", "BET 1820 AND 1825"; важнее birth_date
+	DeathDate     *time.Time `json:"death_date,omitempty"`
+	DeathDateText string     `json:"death_date_text,omitempty"`
+	BirthPlaceID  *int       `json:"birth_place_id,omitempty"`
+	DeathPlaceID  *int       `json:"death_place_id,omitempty"`
+	Sex           string     `json:"sex,omitempty"`     // male, female, unknown или other; по умолчанию unknown
+	IsMale        *bool      `json:"is_male,omitempty"` // устарело: используется, только если sex не задан
+	Biography     string     `json:"biography,omitempty"`
+	Privacy       string     `json:"privacy,omitempty"` // auto (по умолчанию), public или private
+	// Metadata — значения пользовательских полей дерева, ключи — имена полей схемы
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 // UpdatePersonRequest — данные для обновления персоны
 type UpdatePersonRequest struct {
-	FirstName string     `json:"first_name"`
-	LastName  string     `json:"last_name"`
-	BirthDate *time.Time `json:"birth_date,omitempty"`
-	DeathDate *time.Time `json:"death_date,omitempty"`
-	IsMale    bool       `json:"is_male"`
-	Biography string     `json:"biography,omitempty"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	BirthDate     *time.Time `json:"birth_date,omitempty"`
+	BirthDateText string     `json:"birth_date_text,omitempty"`
+	DeathDate     *time.Time `json:"death_date,omitempty"`
+	DeathDateText string     `json:"death_date_text,omitempty"`
+	BirthPlaceID  *int       `json:"birth_place_id,omitempty"`
+	DeathPlaceID  *int       `json:"death_place_id,omitempty"`
+	Sex           string     `json:"sex,omitempty"`     // male, female, unknown или other; по умолчанию unknown
+	IsMale        *bool      `json:"is_male,omitempty"` // устарело: используется, только если sex не задан
+	Biography     string     `json:"biography,omitempty"`
+	Privacy       string     `json:"privacy,omitempty"` // auto (по умолчанию), public или private
+	// Metadata заменяет все значения пользовательских полей; без поля — без изменений,
+	// null у ключа стирает значение
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 // PersonResponse — данные персоны в ответе
 type PersonResponse struct {
-	ID        int        `json:"id"`
-	FirstName string     `json:"first_name"`
-	LastName  string     `json:"last_name"`
-	BirthDate *time.Time `json:"birth_date,omitempty"`
-	DeathDate *time.Time `json:"death_date,omitempty"`
-	IsMale    bool       `json:"is_male"`
-	Biography string     `json:"biography,omitempty"`
-	TreeID    int        `json:"tree_id"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID            int            `json:"id"`
+	FirstName     string         `json:"first_name"`
+	LastName      string         `json:"last_name"`
+	BirthDate     *time.Time     `json:"birth_date,omitempty"` // только для точных дат
+	BirthDateText string         `json:"birth_date_text,omitempty"`
+	DeathDate     *time.Time     `json:"death_date,omitempty"`
+	DeathDateText string         `json:"death_date_text,omitempty"`
+	BirthPlaceID  *int           `json:"birth_place_id,omitempty"`
+	DeathPlaceID  *int           `json:"death_place_id,omitempty"`
+	PortraitURL   string         `json:"portrait_url,omitempty"`
+	Sex           string         `json:"sex"`
+	IsMale        bool           `json:"is_male"` // устарело: sex == "male"
+	Biography     string         `json:"biography,omitempty"`
+	Privacy       string         `json:"privacy"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+	TreeID        int            `json:"tree_id"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
 }
 
 // PersonListResponse — для списка персон
 type PersonListResponse struct {
-	Persons []PersonResponse `json:"persons"`
-	Total   int              `json:"total"`
+	Persons    []PersonResponse `json:"persons"`
+	Total      int              `json:"total"`                 // всего персон под фильтрами, а не на странице
+	NextCursor string           `json:"next_cursor,omitempty"` // нет на последней странице
 }
 
 // PersonBriefResponse — краткая информация о персоне для списков
 type PersonBriefResponse struct {
-	ID        int        `json:"id"`
-	FirstName string     `json:"first_name"`
-	LastName  string     `json:"last_name"`
-	BirthDate *time.Time `json:"birth_date,omitempty"`
-	IsMale    bool       `json:"is_male"`
+	ID            int        `json:"id"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	BirthDate     *time.Time `json:"birth_date,omitempty"`
+	BirthDateText string     `json:"birth_date_text,omitempty"`
+	Sex           string     `json:"sex"`
+	IsMale        bool       `json:"is_male"` // устарело: sex == "male"
 }
 
 // PersonBriefListResponse — список кратких данных
@@ -57,6 +85,38 @@ type PersonBriefListResponse struct {
 	Total   int                   `json:"total"`
 }
 
+// PersonMatchResponse — персона, найденная поиском
+type PersonMatchResponse struct {
+	PersonBriefResponse
+	Score int `json:"score"` // 1–100, выше — точнее
+}
+
+// PersonSearchResponse — результаты поиска, лучшие первыми
+type PersonSearchResponse struct {
+	Results []PersonMatchResponse `json:"results"`
+	Total   int                   `json:"total"` // всего совпадений, включая не попавшие в limit
+}
+
+// MergePersonsRequest — слить персону source_id в персону из URL
+type MergePersonsRequest struct {
+	SourceID int `json:"source_id"`
+	// Fields — чьё значение поля оставить: "target" (по умолчанию) или "source".
+	// Поля: first_name, last_name, sex, birth, death, biography, privacy, portrait, metadata
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// MergeConflictResponse — правило дерева, которое нарушило бы слияние
+type MergeConflictResponse struct {
+	PersonID int    `json:"person_id"`
+	Reason   string `json:"reason"`
+}
+
+// MergeErrorResponse — ошибка слияния со списком нарушений
+type MergeErrorResponse struct {
+	Error     string                  `json:"error"`
+	Conflicts []MergeConflictResponse `json:"conflicts"`
+}
+
 // ErrorResponse — структура для ошибок
 type ErrorResponse struct {
 	Error string `json:"error"`
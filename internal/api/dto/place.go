@@ -0,0 +1,70 @@
+package dto
+
+import "time"
+
+// PlaceNameDTO — историческое название места
+type PlaceNameDTO struct {
+	Name     string `json:"name"`
+	FromYear *int   `json:"from_year,omitempty"`
+	ToYear   *int   `json:"to_year,omitempty"`
+}
+
+// PlaceRequest — создать или обновить место
+type PlaceRequest struct {
+	ParentID  *int           `json:"parent_id,omitempty"`
+	Name      string         `json:"name"`
+	Type      string         `json:"type"` // village, city, district, region, country или other (по умолчанию)
+	Latitude  *float64       `json:"latitude,omitempty"`
+	Longitude *float64       `json:"longitude,omitempty"`
+	Names     []PlaceNameDTO `json:"names,omitempty"` // при обновлении заменяют прежние
+}
+
+// PlaceResponse — место в ответе
+type PlaceResponse struct {
+	ID        int            `json:"id"`
+	TreeID    int            `json:"tree_id"`
+	ParentID  *int           `json:"parent_id,omitempty"`
+	Name      string         `json:"name"`
+	Type      string         `json:"type"`
+	Latitude  *float64       `json:"latitude,omitempty"`
+	Longitude *float64       `json:"longitude,omitempty"`
+	Names     []PlaceNameDTO `json:"names"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// PlaceListResponse — места дерева
+type PlaceListResponse struct {
+	Places []PlaceResponse `json:"places"`
+	Total  int             `json:"total"`
+}
+
+// GeoJSONFeatureCollection — коллекция точек GeoJSON (RFC 7946)
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"` // всегда "FeatureCollection"
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature — рождение или смерть персоны на карте
+type GeoJSONFeature struct {
+	Type       string               `json:"type"` // всегда "Feature"
+	Geometry   GeoJSONPoint         `json:"geometry"`
+	Properties VitalPlaceProperties `json:"properties"`
+}
+
+// GeoJSONPoint — точка; координаты в порядке [долгота, широта]
+type GeoJSONPoint struct {
+	Type        string     `json:"type"` // всегда "Point"
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// VitalPlaceProperties — свойства точки рождения или смерти
+type VitalPlaceProperties struct {
+	PersonID  int    `json:"person_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Event     string `json:"event"` // birth или death
+	DateText  string `json:"date_text,omitempty"`
+	PlaceID   int    `json:"place_id"`
+	Place     string `json:"place"`
+}
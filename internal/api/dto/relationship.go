@@ -5,7 +5,7 @@ import "time"
 // AddChildRequest — связать существующего ребенка
 type AddChildRequest struct {
 	ChildID          int    `json:"child_id"`
-	RelationshipType string `json:"relationship_type"` // "biological" или "not_biological"
+	RelationshipType string `json:"relationship_type"` // код из реестра типов связей, по умолчанию "biological"
 }
 
 // AddParentRequest — связать существующего родителя
@@ -19,8 +19,11 @@ type CreateChildRequest struct {
 	FirstName        string     `json:"first_name"`
 	LastName         string     `json:"last_name"`
 	BirthDate        *time.Time `json:"birth_date,omitempty"`
+	BirthDateText    string     `json:"birth_date_text,omitempty"`
 	DeathDate        *time.Time `json:"death_date,omitempty"`
-	IsMale           bool       `json:"is_male"`
+	DeathDateText    string     `json:"death_date_text,omitempty"`
+	Sex              string     `json:"sex,omitempty"`     // male, female, unknown или other; по умолчанию unknown
+	IsMale           *bool      `json:"is_male,omitempty"` // устарело: используется, только если sex не задан
 	Biography        string     `json:"biography,omitempty"`
 	RelationshipType string     `json:"relationship_type"` // по умолчанию "biological"
 }
@@ -30,8 +33,11 @@ type CreateParentRequest struct {
 	FirstName        string     `json:"first_name"`
 	LastName         string     `json:"last_name"`
 	BirthDate        *time.Time `json:"birth_date,omitempty"`
+	BirthDateText    string     `json:"birth_date_text,omitempty"`
 	DeathDate        *time.Time `json:"death_date,omitempty"`
-	IsMale           bool       `json:"is_male"`
+	DeathDateText    string     `json:"death_date_text,omitempty"`
+	Sex              string     `json:"sex,omitempty"`     // male, female, unknown или other; по умолчанию unknown
+	IsMale           *bool      `json:"is_male,omitempty"` // устарело: используется, только если sex не задан
 	Biography        string     `json:"biography,omitempty"`
 	RelationshipType string     `json:"relationship_type"`
 }
@@ -43,3 +49,25 @@ type RelationshipResponse struct {
 	ChildID          int    `json:"child_id"`
 	RelationshipType string `json:"relationship_type"`
 }
+
+// RelativeResponse — предок или потомок с номером поколения
+type RelativeResponse struct {
+	ID            int        `json:"id"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	BirthDate     *time.Time `json:"birth_date,omitempty"`
+	BirthDateText string     `json:"birth_date_text,omitempty"`
+	DeathDate     *time.Time `json:"death_date,omitempty"`
+	DeathDateText string     `json:"death_date_text,omitempty"`
+	Sex           string     `json:"sex"`
+	IsMale        bool       `json:"is_male"` // устарело: sex == "male"
+	Generation    int        `json:"generation"`
+}
+
+// RelativesResponse — предки или потомки персоны вместе со связями между ними
+type RelativesResponse struct {
+	PersonID  int                 `json:"person_id"`
+	Relatives []RelativeResponse  `json:"relatives"`
+	Edges     []GraphEdgeResponse `json:"edges"`
+	Total     int                 `json:"total"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// RelationshipTypeRequest — добавить тип связи в реестр дерева
+type RelationshipTypeRequest struct {
+	Code                string `json:"code"`
+	Name                string `json:"name"`
+	CountsTowardParents bool   `json:"counts_toward_parents"` // входит в лимит двух родителей
+	Blood               bool   `json:"blood"`                 // учитывается в кровном родстве
+}
+
+// RelationshipTypeResponse — тип связи в ответе
+type RelationshipTypeResponse struct {
+	ID                  int       `json:"id"`
+	TreeID              *int      `json:"tree_id,omitempty"` // нет у встроенных типов
+	Code                string    `json:"code"`
+	Name                string    `json:"name"`
+	CountsTowardParents bool      `json:"counts_toward_parents"`
+	Blood               bool      `json:"blood"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// RelationshipTypeListResponse — реестр типов связей
+type RelationshipTypeListResponse struct {
+	Types []RelationshipTypeResponse `json:"types"`
+	Total int                        `json:"total"`
+}
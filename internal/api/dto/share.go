@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// CreateShareLinkRequest — создать публичную ссылку на дерево
+type CreateShareLinkRequest struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // без срока, если не указано
+}
+
+// ShareLinkResponse — публичная ссылка в ответе.
+// Token заполняется только при создании.
+type ShareLinkResponse struct {
+	ID        int        `json:"id"`
+	TreeID    int        `json:"tree_id"`
+	Token     string     `json:"token,omitempty"`
+	CreatedBy int        `json:"created_by"`
+	Active    bool       `json:"active"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ShareLinkListResponse — список ссылок дерева
+type ShareLinkListResponse struct {
+	Links []ShareLinkResponse `json:"links"`
+	Total int                 `json:"total"`
+}
@@ -0,0 +1,80 @@
+package dto
+
+import "time"
+
+// SourceRequest — создать или обновить источник
+type SourceRequest struct {
+	Title      string `json:"title"`
+	Author     string `json:"author,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	URL        string `json:"url,omitempty"`
+	ArchiveRef string `json:"archive_ref,omitempty"`
+}
+
+// SourceResponse — источник в ответе
+type SourceResponse struct {
+	ID         int       `json:"id"`
+	TreeID     int       `json:"tree_id"`
+	Title      string    `json:"title"`
+	Author     string    `json:"author,omitempty"`
+	Repository string    `json:"repository,omitempty"`
+	URL        string    `json:"url,omitempty"`
+	ArchiveRef string    `json:"archive_ref,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// SourceListResponse — источники дерева
+type SourceListResponse struct {
+	Sources []SourceResponse `json:"sources"`
+	Total   int              `json:"total"`
+}
+
+// CreateCitationRequest — прикрепить источник к персоне или связи (ровно одно из person_id и relationship_id)
+type CreateCitationRequest struct {
+	SourceID       int    `json:"source_id"`
+	PersonID       *int   `json:"person_id,omitempty"`
+	RelationshipID *int   `json:"relationship_id,omitempty"`
+	Field          string `json:"field,omitempty"` // только для персоны: name, sex, birth_date...
+	Page           string `json:"page,omitempty"`
+	Quality        *int   `json:"quality,omitempty"` // 0..3
+}
+
+// UpdateCitationRequest — обновить страницу и качество цитаты
+type UpdateCitationRequest struct {
+	Page    string `json:"page,omitempty"`
+	Quality *int   `json:"quality,omitempty"`
+}
+
+// CitationResponse — цитата в ответе
+type CitationResponse struct {
+	ID             int       `json:"id"`
+	SourceID       int       `json:"source_id"`
+	PersonID       *int      `json:"person_id,omitempty"`
+	RelationshipID *int      `json:"relationship_id,omitempty"`
+	Field          string    `json:"field,omitempty"`
+	Page           string    `json:"page,omitempty"`
+	Quality        *int      `json:"quality,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CitationListResponse — цитаты дерева
+type CitationListResponse struct {
+	Citations []CitationResponse `json:"citations"`
+	Total     int                `json:"total"`
+}
+
+// FactResponse — факт персоны или связь родитель-ребенок
+type FactResponse struct {
+	Field        string               `json:"field"` // name, sex, birth_date... или relationship
+	Person       *PersonBriefResponse `json:"person,omitempty"`
+	Relationship *GraphEdgeResponse   `json:"relationship,omitempty"`
+	Citation     *CitationResponse    `json:"citation,omitempty"` // только для фактов источника
+}
+
+// FactListResponse — список фактов
+type FactListResponse struct {
+	Facts []FactResponse `json:"facts"`
+	Total int            `json:"total"`
+}
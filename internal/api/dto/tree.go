@@ -4,29 +4,75 @@ import "time"
 
 // CreateTreeRequest — данные для создания дерева
 type CreateTreeRequest struct {
-	Name string `json:"name"`
+	Name                string `json:"name"`
+	AllowSameSexParents bool   `json:"allow_same_sex_parents"` // по умолчанию запрещено
 	// OwnerID будем брать из JWT токена (после авторизации)
 	// Пока можно временно передавать или захардкодить
 }
 
 // UpdateTreeRequest — данные для обновления дерева
 type UpdateTreeRequest struct {
-	Name string `json:"name"`
+	Name                string `json:"name"`
+	AllowSameSexParents *bool  `json:"allow_same_sex_parents,omitempty"` // без поля — без изменений
 	// ID берётся из URL параметра
 	// OwnerID не меняется
 }
 
 // TreeResponse — данные дерева в ответе
 type TreeResponse struct {
-	ID        int       `json:"id"`
-	OwnerID   int       `json:"owner_id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                  int       `json:"id"`
+	OwnerID             int       `json:"owner_id"`
+	Name                string    `json:"name"`
+	Role                string    `json:"role,omitempty"` // owner, admin, editor или viewer
+	AllowSameSexParents bool      `json:"allow_same_sex_parents"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
 
 // TreeListResponse — для списка деревьев
 type TreeListResponse struct {
-	Trees []TreeResponse `json:"trees"`
-	Total int            `json:"total"`
+	Trees      []TreeResponse `json:"trees"`
+	Total      int            `json:"total"`                 // всего деревьев, а не на странице
+	NextCursor string         `json:"next_cursor,omitempty"` // нет на последней странице
+}
+
+// AddMemberRequest — пригласить существующего пользователя по email
+type AddMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"` // viewer, editor или admin
+}
+
+// UpdateMemberRequest — изменить роль участника
+type UpdateMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// MemberResponse — участник дерева в ответе
+type MemberResponse struct {
+	UserID    int       `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MemberListResponse — список участников дерева
+type MemberListResponse struct {
+	OwnerID int              `json:"owner_id"`
+	Members []MemberResponse `json:"members"`
+	Total   int              `json:"total"`
+}
+
+// DuplicateResponse — две персоны, похожие на одного человека
+type DuplicateResponse struct {
+	Person1 PersonBriefResponse `json:"person1"`
+	Person2 PersonBriefResponse `json:"person2"`
+	Score   int                 `json:"score"`   // 1–100, выше — вероятнее дубль
+	Reasons []string            `json:"reasons"` // same_name, matching_birth_date, shared_parents...
+}
+
+// DuplicateListResponse — возможные дубли, самые вероятные первыми
+type DuplicateListResponse struct {
+	Duplicates []DuplicateResponse `json:"duplicates"`
+	Total      int                 `json:"total"`
 }
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CustomFieldHandler struct {
+	customFieldService *service.CustomFieldService
+}
+
+func NewCustomFieldHandler(customFieldService *service.CustomFieldService) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		customFieldService: customFieldService,
+	}
+}
+
+// GetCustomFields возвращает схему пользовательских полей дерева
+func (h *CustomFieldHandler) GetCustomFields(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	fields, err := h.customFieldService.GetCustomFields(r.Context(), tree.ID)
+	if err != nil {
+		return apierror.InternalError("Failed to get custom fields", err)
+	}
+
+	fieldResponses := make([]dto.CustomFieldResponse, 0, len(fields))
+	for _, f := range fields {
+		fieldResponses = append(fieldResponses, customFieldResponse(&f))
+	}
+
+	response := dto.CustomFieldListResponse{
+		Fields: fieldResponses,
+		Total:  len(fieldResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// CreateCustomField добавляет поле в схему дерева
+func (h *CustomFieldHandler) CreateCustomField(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	var req dto.CustomFieldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	f := &models.CustomField{
+		TreeID:        tree.ID,
+		Name:          req.Name,
+		Type:          req.Type,
+		AllowedValues: req.AllowedValues,
+	}
+
+	if _, err := h.customFieldService.CreateCustomField(r.Context(), f); err != nil {
+		return customFieldError(err, "Failed to create custom field")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(customFieldResponse(f))
+}
+
+// UpdateCustomField меняет тип и допустимые значения поля
+func (h *CustomFieldHandler) UpdateCustomField(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	fieldID, err := strconv.Atoi(chi.URLParam(r, "field_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid custom field ID format", err)
+	}
+
+	var req dto.CustomFieldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	f := &models.CustomField{
+		ID:            fieldID,
+		Type:          req.Type,
+		AllowedValues: req.AllowedValues,
+	}
+
+	if err := h.customFieldService.UpdateCustomField(r.Context(), tree.ID, f); err != nil {
+		return customFieldError(err, "Failed to update custom field")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(customFieldResponse(f))
+}
+
+// DeleteCustomField удаляет поле и его значения у персон
+func (h *CustomFieldHandler) DeleteCustomField(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	fieldID, err := strconv.Atoi(chi.URLParam(r, "field_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid custom field ID format", err)
+	}
+
+	if err := h.customFieldService.DeleteCustomField(r.Context(), tree.ID, fieldID); err != nil {
+		if errors.Is(err, repo.ErrCustomFieldNotFound) {
+			return apierror.NotFound("Custom field not found", err)
+		}
+		return apierror.InternalError("Failed to delete custom field", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func customFieldError(err error, failMessage string) error {
+	switch {
+	case errors.Is(err, repo.ErrCustomFieldNotFound):
+		return apierror.NotFound("Custom field not found", err)
+	case errors.Is(err, repo.ErrCustomFieldAlreadyExists):
+		return apierror.Conflict("Custom field with this name already exists", err)
+	default:
+		return apierror.BadRequest(failMessage, err)
+	}
+}
+
+func customFieldResponse(f *models.CustomField) dto.CustomFieldResponse {
+	return dto.CustomFieldResponse{
+		ID:            f.ID,
+		TreeID:        f.TreeID,
+		Name:          f.Name,
+		Type:          f.Type,
+		AllowedValues: f.AllowedValues,
+		CreatedAt:     f.CreatedAt,
+		UpdatedAt:     f.UpdatedAt,
+	}
+}
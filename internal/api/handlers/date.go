@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/models"
+	"time"
+)
+
+// requestDate собирает дату из запроса: фраза GEDCOM важнее точной даты
+func requestDate(exact *time.Time, text string) (*models.GenDate, error) {
+	if text != "" {
+		date, err := models.ParseGenDate(text)
+		if err != nil {
+			return nil, err
+		}
+		return &date, nil
+	}
+	if exact != nil {
+		date := models.ExactDate(*exact)
+		return &date, nil
+	}
+	return nil, nil
+}
+
+// exactDate возвращает дату для поля birth_date/death_date — только если она точная
+func exactDate(d *models.GenDate) *time.Time {
+	if d == nil || !d.IsExact() {
+		return nil
+	}
+	return &d.Start
+}
+
+// dateText возвращает дату фразой GEDCOM
+func dateText(d *models.GenDate) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type EventHandler struct {
+	eventService       *service.EventService
+	partnershipService *service.PartnershipService
+	privacyService     *service.PrivacyService
+}
+
+func NewEventHandler(eventService *service.EventService, partnershipService *service.PartnershipService, privacyService *service.PrivacyService) *EventHandler {
+	return &EventHandler{
+		eventService:       eventService,
+		partnershipService: partnershipService,
+		privacyService:     privacyService,
+	}
+}
+
+// GetEvents возвращает события персоны и её союзов
+func (h *EventHandler) GetEvents(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	events, err := h.eventService.GetEvents(r.Context(), person.ID)
+	if err != nil {
+		return apierror.InternalError("Failed to get events", err)
+	}
+
+	// У скрытой персоны события не показываем, у видимой — события союзов со скрытым партнёром
+	if helpers.ShouldRedactPrivate(r) {
+		partners, err := h.partnershipService.GetPartners(r.Context(), person.ID)
+		if err != nil {
+			return apierror.InternalError("Failed to get events", err)
+		}
+		events = h.privacyService.RedactEvents(person, partners, events)
+	}
+
+	eventResponses := make([]dto.EventResponse, 0, len(events))
+	for _, event := range events {
+		eventResponses = append(eventResponses, eventResponse(&event))
+	}
+
+	response := dto.EventListResponse{
+		Events: eventResponses,
+		Total:  len(eventResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// CreateEvent добавляет событие персоне или её союзу
+func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	var req dto.EventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	date, err := requestDate(req.Date, req.DateText)
+	if err != nil {
+		return apierror.BadRequest("Invalid event date", err)
+	}
+
+	event := &models.Event{
+		PartnershipID: req.PartnershipID,
+		Type:          req.Type,
+		Date:          date,
+		PlaceID:       req.PlaceID,
+		Description:   req.Description,
+	}
+
+	if _, err := h.eventService.CreateEvent(r.Context(), person, event, req.Place); err != nil {
+		return eventError(err, "Failed to create event")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(eventResponse(event))
+}
+
+// UpdateEvent обновляет дату, место и описание события
+func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	eventIDStr := chi.URLParam(r, "event_id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid event ID format", err)
+	}
+
+	var req dto.EventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	date, err := requestDate(req.Date, req.DateText)
+	if err != nil {
+		return apierror.BadRequest("Invalid event date", err)
+	}
+
+	event := &models.Event{
+		ID:          eventID,
+		Date:        date,
+		PlaceID:     req.PlaceID,
+		Description: req.Description,
+	}
+
+	if err := h.eventService.UpdateEvent(r.Context(), person, event, req.Place); err != nil {
+		return eventError(err, "Failed to update event")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(eventResponse(event))
+}
+
+// DeleteEvent удаляет событие
+func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	eventIDStr := chi.URLParam(r, "event_id")
+	eventID, err := strconv.Atoi(eventIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid event ID format", err)
+	}
+
+	if err := h.eventService.DeleteEvent(r.Context(), person.ID, eventID); err != nil {
+		if errors.Is(err, repo.ErrEventNotFound) {
+			return apierror.NotFound("Event not found", err)
+		}
+		return apierror.InternalError("Failed to delete event", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func eventError(err error, failMessage string) error {
+	switch {
+	case errors.Is(err, repo.ErrEventNotFound):
+		return apierror.NotFound("Event not found", err)
+	case errors.Is(err, repo.ErrPartnershipNotFound):
+		return apierror.NotFound("Partnership not found", err)
+	case errors.Is(err, repo.ErrEventAlreadyExists):
+		return apierror.Conflict("Person already has an event of this type", err)
+	default:
+		return apierror.BadRequest(failMessage, err)
+	}
+}
+
+func eventResponse(e *models.Event) dto.EventResponse {
+	return dto.EventResponse{
+		ID:            e.ID,
+		PersonID:      e.PersonID,
+		PartnershipID: e.PartnershipID,
+		Type:          e.Type,
+		Date:          exactDate(e.Date),
+		DateText:      dateText(e.Date),
+		PlaceID:       e.PlaceID,
+		Place:         e.Place,
+		Description:   e.Description,
+		CreatedAt:     e.CreatedAt,
+		UpdatedAt:     e.UpdatedAt,
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// maxGedcomSize — максимальный размер загружаемого GEDCOM файла
+const maxGedcomSize = 50 << 20
+
+type GedcomHandler struct {
+	gedcomService *service.GedcomService
+}
+
+func NewGedcomHandler(gedcomService *service.GedcomService) *GedcomHandler {
+	return &GedcomHandler{
+		gedcomService: gedcomService,
+	}
+}
+
+// ImportGedcom импортирует GEDCOM файл в дерево.
+// Файл передаётся телом запроса или полем "file" в multipart/form-data.
+func (h *GedcomHandler) ImportGedcom(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxGedcomSize)
+
+	body, err := gedcomBody(r)
+	if err != nil {
+		return apierror.BadRequest("Invalid GEDCOM upload", err)
+	}
+	defer body.Close()
+
+	report, err := h.gedcomService.Import(r.Context(), tree.ID, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return apierror.BadRequest("GEDCOM file is too large", err)
+		}
+		return linkError("Failed to import GEDCOM", err)
+	}
+
+	response := dto.GedcomImportResponse{
+		PersonsImported:       report.PersonsImported,
+		RelationshipsImported: report.RelationshipsImported,
+		Skipped:               importIssuesResponse(report.Skipped),
+		Failures:              importIssuesResponse(report.Failures),
+		Warnings:              importIssuesResponse(report.Warnings),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// ExportGedcom отдаёт всё дерево файлом GEDCOM
+func (h *GedcomHandler) ExportGedcom(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/vnd.familysearch.gedcom; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="tree-%d.ged"`, tree.ID))
+
+	// Данные читаются целиком до первой записи, поэтому ошибка БД
+	// ещё может вернуться обычным JSON-ответом
+	if err := h.gedcomService.Export(r.Context(), tree.ID, w, helpers.ShouldRedactPrivate(r)); err != nil {
+		w.Header().Del("Content-Disposition")
+		return apierror.InternalError("Failed to export GEDCOM", err)
+	}
+
+	return nil
+}
+
+// gedcomBody возвращает содержимое файла из multipart или тело запроса целиком
+func gedcomBody(r *http.Request) (io.ReadCloser, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func importIssuesResponse(issues []models.ImportIssue) []dto.ImportIssueResponse {
+	result := make([]dto.ImportIssueResponse, 0, len(issues))
+	for _, issue := range issues {
+		result = append(result, dto.ImportIssueResponse{
+			XRef:    issue.XRef,
+			Line:    issue.Line,
+			Message: issue.Message,
+		})
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+type KinshipHandler struct {
+	kinshipService *service.KinshipService
+}
+
+func NewKinshipHandler(kinshipService *service.KinshipService) *KinshipHandler {
+	return &KinshipHandler{
+		kinshipService: kinshipService,
+	}
+}
+
+// GetKinship вычисляет родство между персонами ?a= и ?b=
+func (h *KinshipHandler) GetKinship(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	personAID, err := strconv.Atoi(r.URL.Query().Get("a"))
+	if err != nil {
+		return apierror.BadRequest("Invalid person A ID format", err)
+	}
+
+	personBID, err := strconv.Atoi(r.URL.Query().Get("b"))
+	if err != nil {
+		return apierror.BadRequest("Invalid person B ID format", err)
+	}
+
+	// Остальные ошибки сервиса — внутренние, поэтому ID проверяются здесь
+	if personAID <= 0 || personBID <= 0 {
+		return apierror.BadRequest("Invalid person ID", errors.New("person ids must be positive"))
+	}
+
+	kinship, err := h.kinshipService.GetKinship(r.Context(), tree.ID, personAID, personBID)
+	if err != nil {
+		if errors.Is(err, repo.ErrPersonNotFound) {
+			return apierror.NotFound("Person not found", err)
+		}
+		return apierror.InternalError("Failed to get kinship", err)
+	}
+
+	response := dto.KinshipResponse{
+		PersonAID:       kinship.PersonAID,
+		PersonBID:       kinship.PersonBID,
+		Related:         kinship.Related,
+		Relationship:    kinship.Relationship,
+		GenerationsA:    kinship.GenerationsA,
+		GenerationsB:    kinship.GenerationsB,
+		CommonAncestors: kinship.CommonAncestors,
+		Path:            kinship.Path,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
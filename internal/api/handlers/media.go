@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// multipartMemory — сколько формы держать в памяти, остальное уходит во временные файлы
+const multipartMemory = 8 << 20
+
+type MediaHandler struct {
+	mediaService   *service.MediaService
+	privacyService *service.PrivacyService
+}
+
+func NewMediaHandler(mediaService *service.MediaService, privacyService *service.PrivacyService) *MediaHandler {
+	return &MediaHandler{
+		mediaService:   mediaService,
+		privacyService: privacyService,
+	}
+}
+
+// UploadMedia прикрепляет файл к персоне (multipart: file и необязательный title)
+func (h *MediaHandler) UploadMedia(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	// Запас на заголовки и остальные поля формы
+	r.Body = http.MaxBytesReader(w, r.Body, h.mediaService.MaxUploadSize()+multipartMemory)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		return apierror.BadRequest("Invalid multipart form", err)
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return apierror.BadRequest("File is required", err)
+	}
+	defer file.Close()
+
+	m, err := h.mediaService.UploadMedia(r.Context(), person, file, header.Size, header.Filename, r.FormValue("title"))
+	if err != nil {
+		return mediaError(err, "Failed to upload media")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(mediaResponse(r, person, m))
+}
+
+// GetMedia возвращает файлы персоны
+func (h *MediaHandler) GetMedia(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	media, err := h.mediaService.GetMedia(r.Context(), person.ID)
+	if err != nil {
+		return apierror.InternalError("Failed to get media", err)
+	}
+
+	// У скрытой персоны файлы не показываем
+	if helpers.ShouldRedactPrivate(r) && h.privacyService.IsHidden(person, time.Now()) {
+		media = nil
+	}
+
+	mediaResponses := make([]dto.MediaResponse, 0, len(media))
+	for _, m := range media {
+		mediaResponses = append(mediaResponses, mediaResponse(r, person, &m))
+	}
+
+	response := dto.MediaListResponse{
+		Media: mediaResponses,
+		Total: len(mediaResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// GetMediaContent отдаёт содержимое файла персоны
+func (h *MediaHandler) GetMediaContent(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	mediaID, err := strconv.Atoi(chi.URLParam(r, "media_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid media ID format", err)
+	}
+
+	m, err := h.mediaService.GetPersonMedia(r.Context(), person.ID, mediaID)
+	if err != nil {
+		return mediaError(err, "Failed to get media")
+	}
+
+	return h.writeContent(w, r, person, `"`+m.ContentHash+`"`, m.MimeType, m.Size,
+		func() (io.ReadCloser, error) { return h.mediaService.OpenMedia(r.Context(), m) })
+}
+
+// GetSharedMediaContent отдаёт содержимое файла по публичной ссылке
+func (h *MediaHandler) GetSharedMediaContent(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	mediaID, err := strconv.Atoi(chi.URLParam(r, "media_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid media ID format", err)
+	}
+
+	m, person, err := h.mediaService.GetTreeMedia(r.Context(), tree.ID, mediaID)
+	if err != nil {
+		return mediaError(err, "Failed to get media")
+	}
+
+	return h.writeContent(w, r, person, `"`+m.ContentHash+`"`, m.MimeType, m.Size,
+		func() (io.ReadCloser, error) { return h.mediaService.OpenMedia(r.Context(), m) })
+}
+
+// GetThumbnail отдаёт превью файла персоны
+func (h *MediaHandler) GetThumbnail(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	mediaID, err := strconv.Atoi(chi.URLParam(r, "media_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid media ID format", err)
+	}
+
+	m, err := h.mediaService.GetPersonMedia(r.Context(), person.ID, mediaID)
+	if err != nil {
+		return mediaError(err, "Failed to get media")
+	}
+
+	return h.writeThumbnail(w, r, person, m)
+}
+
+// GetSharedThumbnail отдаёт превью файла по публичной ссылке
+func (h *MediaHandler) GetSharedThumbnail(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	mediaID, err := strconv.Atoi(chi.URLParam(r, "media_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid media ID format", err)
+	}
+
+	m, person, err := h.mediaService.GetTreeMedia(r.Context(), tree.ID, mediaID)
+	if err != nil {
+		return mediaError(err, "Failed to get media")
+	}
+
+	return h.writeThumbnail(w, r, person, m)
+}
+
+// UploadPortrait загружает фото JPEG/PNG и делает его портретом персоны (multipart: file)
+func (h *MediaHandler) UploadPortrait(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.mediaService.MaxUploadSize()+multipartMemory)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		return apierror.BadRequest("Invalid multipart form", err)
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return apierror.BadRequest("File is required", err)
+	}
+	defer file.Close()
+
+	m, err := h.mediaService.UploadPortrait(r.Context(), person, file, header.Size, header.Filename)
+	if err != nil {
+		return mediaError(err, "Failed to upload portrait")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(mediaResponse(r, person, m))
+}
+
+func (h *MediaHandler) writeThumbnail(w http.ResponseWriter, r *http.Request, person *models.Person, m *models.Media) error {
+	size, err := strconv.Atoi(chi.URLParam(r, "size"))
+	if err != nil {
+		return apierror.BadRequest("Invalid thumbnail size format", err)
+	}
+
+	return h.writeContent(w, r, person, fmt.Sprintf(`"%s-%d"`, m.ContentHash, size), "image/jpeg", 0,
+		func() (io.ReadCloser, error) { return h.mediaService.OpenThumbnail(r.Context(), m, size) })
+}
+
+// writeContent отдаёт содержимое файла; файлы скрытых персон считаются ненайденными.
+// Содержимое по ключу не меняется, поэтому etag строится из хэша.
+// length 0 — размер заранее неизвестен.
+func (h *MediaHandler) writeContent(w http.ResponseWriter, r *http.Request, person *models.Person, etag, mimeType string, length int64, open func() (io.ReadCloser, error)) error {
+	if helpers.ShouldRedactPrivate(r) && h.privacyService.IsHidden(person, time.Now()) {
+		return apierror.NotFound("Media not found", nil)
+	}
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	content, err := open()
+	if err != nil {
+		return mediaError(err, "Failed to open media")
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", mimeType)
+	if length > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, err = io.Copy(w, content)
+	return err
+}
+
+// DeleteMedia удаляет файл персоны
+func (h *MediaHandler) DeleteMedia(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	mediaID, err := strconv.Atoi(chi.URLParam(r, "media_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid media ID format", err)
+	}
+
+	if err := h.mediaService.DeleteMedia(r.Context(), person.ID, mediaID); err != nil {
+		if errors.Is(err, repo.ErrMediaNotFound) {
+			return apierror.NotFound("Media not found", err)
+		}
+		return apierror.InternalError("Failed to delete media", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// SetPortrait выбирает основной портрет персоны
+func (h *MediaHandler) SetPortrait(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	var req dto.SetPortraitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	if err := h.mediaService.SetPortrait(r.Context(), person, req.MediaID); err != nil {
+		return mediaError(err, "Failed to set portrait")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(map[string]string{
+		"portrait_url": portraitURL(r, person),
+	})
+}
+
+func mediaError(err error, failMessage string) error {
+	switch {
+	case errors.Is(err, repo.ErrMediaNotFound):
+		return apierror.NotFound("Media not found", err)
+	case errors.Is(err, repo.ErrPersonNotFound):
+		return apierror.NotFound("Person not found", err)
+	case errors.Is(err, repo.ErrMediaAlreadyExists):
+		return apierror.Conflict("This file is already attached to the person", err)
+	default:
+		return apierror.BadRequest(failMessage, err)
+	}
+}
+
+func mediaResponse(r *http.Request, person *models.Person, m *models.Media) dto.MediaResponse {
+	return dto.MediaResponse{
+		ID:          m.ID,
+		PersonID:    m.PersonID,
+		URL:         mediaURL(r, m.PersonID, m.ID),
+		ContentHash: m.ContentHash,
+		MimeType:    m.MimeType,
+		Size:        m.Size,
+		FileName:    m.FileName,
+		Title:       m.Title,
+		Thumbnails:  thumbnailURLs(r, m),
+		IsPortrait:  person.PortraitID != nil && *person.PortraitID == m.ID,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// thumbnailURLs — адреса превью по размерам; nil, если превью у файла нет
+func thumbnailURLs(r *http.Request, m *models.Media) map[int]string {
+	if !m.HasThumbnails() {
+		return nil
+	}
+
+	urls := make(map[int]string, len(models.ThumbnailSizes))
+	for _, size := range models.ThumbnailSizes {
+		urls[size] = thumbnailURL(r, m.PersonID, m.ID, size)
+	}
+	return urls
+}
+
+// mediaURL — адрес содержимого файла; по публичной ссылке — через её токен
+func mediaURL(r *http.Request, personID, mediaID int) string {
+	if token := chi.URLParam(r, "token"); token != "" {
+		return fmt.Sprintf("/api/shared/%s/media/%d/content", token, mediaID)
+	}
+	return fmt.Sprintf("/api/persons/%d/media/%d/content", personID, mediaID)
+}
+
+// thumbnailURL — адрес превью файла размера size
+func thumbnailURL(r *http.Request, personID, mediaID, size int) string {
+	return fmt.Sprintf("%s/thumbnails/%d", strings.TrimSuffix(mediaURL(r, personID, mediaID), "/content"), size)
+}
+
+// portraitThumbnailURL — адрес превью портрета персоны или пустая строка
+func portraitThumbnailURL(r *http.Request, p *models.Person, size int) string {
+	if p.PortraitID == nil {
+		return ""
+	}
+	return thumbnailURL(r, p.ID, *p.PortraitID, size)
+}
+
+// portraitURL — адрес основного портрета персоны или пустая строка
+func portraitURL(r *http.Request, p *models.Person) string {
+	if p.PortraitID == nil {
+		return ""
+	}
+	return mediaURL(r, p.ID, *p.PortraitID)
+}
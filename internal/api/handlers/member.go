@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type MemberHandler struct {
+	memberService *service.MemberService
+}
+
+func NewMemberHandler(memberService *service.MemberService) *MemberHandler {
+	return &MemberHandler{
+		memberService: memberService,
+	}
+}
+
+// GetMembers возвращает участников дерева
+func (h *MemberHandler) GetMembers(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	members, err := h.memberService.GetMembers(r.Context(), tree.ID)
+	if err != nil {
+		return apierror.InternalError("Failed to get members", err)
+	}
+
+	memberResponses := make([]dto.MemberResponse, 0, len(members))
+	for _, member := range members {
+		memberResponses = append(memberResponses, memberResponse(&member))
+	}
+
+	response := dto.MemberListResponse{
+		OwnerID: tree.OwnerID,
+		Members: memberResponses,
+		Total:   len(memberResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// AddMember приглашает существующего пользователя по email
+func (h *MemberHandler) AddMember(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	var req dto.AddMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	member, err := h.memberService.AddMember(r.Context(), tree, req.Email, req.Role)
+	if err != nil {
+		return memberError(err, "Failed to add member")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(memberResponse(member))
+}
+
+// UpdateMember меняет роль участника
+func (h *MemberHandler) UpdateMember(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	userIDStr := chi.URLParam(r, "user_id")
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid user ID format", err)
+	}
+
+	var req dto.UpdateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	member, err := h.memberService.UpdateMemberRole(r.Context(), tree, userID, req.Role)
+	if err != nil {
+		return memberError(err, "Failed to update member")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(memberResponse(member))
+}
+
+// RemoveMember отзывает доступ участника к дереву
+func (h *MemberHandler) RemoveMember(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	userIDStr := chi.URLParam(r, "user_id")
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid user ID format", err)
+	}
+
+	if err := h.memberService.RemoveMember(r.Context(), tree, userID); err != nil {
+		return memberError(err, "Failed to remove member")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// memberError переводит ошибки сервиса участников в ответ API
+func memberError(err error, failMessage string) error {
+	switch {
+	case errors.Is(err, repo.ErrUserNotFound):
+		return apierror.NotFound("User not found", err)
+	case errors.Is(err, repo.ErrMemberNotFound):
+		return apierror.NotFound("Member not found", err)
+	case errors.Is(err, repo.ErrMemberAlreadyExists):
+		return apierror.Conflict("User is already a member of the tree", err)
+	case errors.Is(err, service.ErrForbidden):
+		return apierror.Forbidden("Only the owner can manage admins", err)
+	default:
+		return apierror.BadRequest(failMessage, err)
+	}
+}
+
+func memberResponse(m *models.TreeMember) dto.MemberResponse {
+	return dto.MemberResponse{
+		UserID:    m.UserID,
+		Email:     m.Email,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
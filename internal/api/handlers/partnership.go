@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PartnershipHandler struct {
+	partnershipService *service.PartnershipService
+	privacyService     *service.PrivacyService
+}
+
+func NewPartnershipHandler(partnershipService *service.PartnershipService, privacyService *service.PrivacyService) *PartnershipHandler {
+	return &PartnershipHandler{
+		partnershipService: partnershipService,
+		privacyService:     privacyService,
+	}
+}
+
+// GetPartners возвращает всех партнёров персоны
+func (h *PartnershipHandler) GetPartners(w http.ResponseWriter, r *http.Request) error {
+	personIDStr := chi.URLParam(r, "person_id")
+	personID, err := strconv.Atoi(personIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid person ID format", err)
+	}
+
+	partners, err := h.partnershipService.GetPartners(r.Context(), personID)
+	if err != nil {
+		if errors.Is(err, repo.ErrPersonNotFound) {
+			return apierror.NotFound("Person not found", err)
+		}
+		return apierror.InternalError("Failed to get partners", err)
+	}
+
+	if helpers.ShouldRedactPrivate(r) {
+		person, err := helpers.GetPersonFromContext(r)
+		if err != nil {
+			return err
+		}
+		h.privacyService.RedactPartners(person, partners)
+	}
+
+	partnerResponses := make([]dto.PartnerResponse, 0, len(partners))
+	for _, partner := range partners {
+		partnerResponses = append(partnerResponses, dto.PartnerResponse{
+			Person: dto.PersonBriefResponse{
+				ID:            partner.ID,
+				FirstName:     partner.FirstName,
+				LastName:      partner.LastName,
+				BirthDate:     exactDate(partner.BirthDate),
+				BirthDateText: dateText(partner.BirthDate),
+				Sex:           partner.Sex,
+				IsMale:        partner.IsMale(),
+			},
+			Partnership: partnershipResponse(&partner.Partnership),
+		})
+	}
+
+	response := dto.PartnerListResponse{
+		Partners: partnerResponses,
+		Total:    len(partnerResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// AddPartner создаёт союз персоны с существующим партнёром
+func (h *PartnershipHandler) AddPartner(w http.ResponseWriter, r *http.Request) error {
+	personIDStr := chi.URLParam(r, "person_id")
+	personID, err := strconv.Atoi(personIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid person ID format", err)
+	}
+
+	var req dto.AddPartnerRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	// По умолчанию действующий брак
+	if req.Type == "" {
+		req.Type = "marriage"
+	}
+	if req.Status == "" {
+		req.Status = "active"
+	}
+
+	partnership := &models.Partnership{
+		Type:      req.Type,
+		Status:    req.Status,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	}
+
+	if _, err := h.partnershipService.AddPartner(r.Context(), personID, req.PartnerID, partnership); err != nil {
+		if errors.Is(err, repo.ErrPersonNotFound) {
+			return apierror.NotFound("Person not found", err)
+		}
+		return apierror.BadRequest("Failed to add partner", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(partnershipResponse(partnership))
+}
+
+// UpdatePartner обновляет тип, статус и даты союза
+func (h *PartnershipHandler) UpdatePartner(w http.ResponseWriter, r *http.Request) error {
+	personIDStr := chi.URLParam(r, "person_id")
+	personID, err := strconv.Atoi(personIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid person ID format", err)
+	}
+
+	partnershipIDStr := chi.URLParam(r, "partnership_id")
+	partnershipID, err := strconv.Atoi(partnershipIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid partnership ID format", err)
+	}
+
+	var req dto.UpdatePartnerRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	partnership := &models.Partnership{
+		ID:        partnershipID,
+		Type:      req.Type,
+		Status:    req.Status,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	}
+
+	if err := h.partnershipService.UpdatePartnership(r.Context(), personID, partnership); err != nil {
+		if errors.Is(err, repo.ErrPartnershipNotFound) {
+			return apierror.NotFound("Partnership not found", err)
+		}
+		return apierror.BadRequest("Failed to update partnership", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(partnershipResponse(partnership))
+}
+
+// RemovePartner удаляет союз
+func (h *PartnershipHandler) RemovePartner(w http.ResponseWriter, r *http.Request) error {
+	personIDStr := chi.URLParam(r, "person_id")
+	personID, err := strconv.Atoi(personIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid person ID format", err)
+	}
+
+	partnershipIDStr := chi.URLParam(r, "partnership_id")
+	partnershipID, err := strconv.Atoi(partnershipIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid partnership ID format", err)
+	}
+
+	if err := h.partnershipService.DeletePartnership(r.Context(), personID, partnershipID); err != nil {
+		if errors.Is(err, repo.ErrPartnershipNotFound) {
+			return apierror.NotFound("Partnership not found", err)
+		}
+		return apierror.InternalError("Failed to remove partner", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func partnershipResponse(p *models.Partnership) dto.PartnershipResponse {
+	return dto.PartnershipResponse{
+		ID:        p.ID,
+		Person1ID: p.Person1ID,
+		Person2ID: p.Person2ID,
+		Type:      p.Type,
+		Status:    p.Status,
+		StartDate: p.StartDate,
+		EndDate:   p.EndDate,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
@@ -3,60 +3,146 @@ package handlers
 import (
 	"GenealogyTree/internal/api/apierror"
 	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
 	"GenealogyTree/internal/models"
 	"GenealogyTree/internal/repo"
 	"GenealogyTree/internal/service"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
-
-	"github.com/go-chi/chi/v5"
+	"strings"
+	"time"
 )
 
 type PersonHandler struct {
-	personService *service.PersonService
+	personService  *service.PersonService
+	privacyService *service.PrivacyService
 }
 
-func NewPersonHandler(personService *service.PersonService) *PersonHandler {
+func NewPersonHandler(personService *service.PersonService, privacyService *service.PrivacyService) *PersonHandler {
 	return &PersonHandler{
-		personService: personService,
+		personService:  personService,
+		privacyService: privacyService,
 	}
 }
 
-// GetPersons получает все персоны в дереве
+// GetPersons получает страницу персон дерева: limit, cursor, sort, order,
+// фильтры sex, living, born_from, born_to и meta.<поле>
 func (h *PersonHandler) GetPersons(w http.ResponseWriter, r *http.Request) error {
-	treeIDStr := chi.URLParam(r, "tree_id")
-	treeID, err := strconv.Atoi(treeIDStr)
+	tree, err := helpers.GetTreeFromContext(r)
 	if err != nil {
-		return apierror.BadRequest("Invalid tree ID format", err)
+		return err
 	}
 
-	persons, err := h.personService.GetPersonsByTreeID(r.Context(), treeID)
+	query, err := personListQuery(r)
 	if err != nil {
-		return apierror.InternalError("Failed to get persons", err)
+		return err
+	}
+
+	redact := helpers.ShouldRedactPrivate(r)
+	page, err := h.personService.ListPersons(r.Context(), tree.ID, query, redact)
+	if err != nil {
+		return apierror.BadRequest("Failed to get persons", err)
+	}
+
+	persons := page.Persons
+	if redact {
+		h.privacyService.RedactPersons(persons)
 	}
 
 	// Формируем список PersonResponse
 	personResponses := make([]dto.PersonResponse, 0, len(persons))
 	for _, person := range persons {
 		personResponses = append(personResponses, dto.PersonResponse{
-			ID:        person.ID,
-			FirstName: person.FirstName,
-			LastName:  person.LastName,
-			BirthDate: person.BirthDate,
-			DeathDate: person.DeathDate,
-			IsMale:    person.IsMale,
-			Biography: person.Biography,
-			TreeID:    person.TreeID,
-			CreatedAt: person.CreatedAt,
-			UpdatedAt: person.UpdatedAt,
+			ID:            person.ID,
+			FirstName:     person.FirstName,
+			LastName:      person.LastName,
+			BirthDate:     exactDate(person.BirthDate),
+			BirthDateText: dateText(person.BirthDate),
+			DeathDate:     exactDate(person.DeathDate),
+			DeathDateText: dateText(person.DeathDate),
+			BirthPlaceID:  person.BirthPlaceID,
+			DeathPlaceID:  person.DeathPlaceID,
+			PortraitURL:   portraitURL(r, &person),
+			Sex:           person.Sex,
+			IsMale:        person.IsMale(),
+			Biography:     person.Biography,
+			Privacy:       person.Privacy,
+			Metadata:      person.Metadata,
+			TreeID:        person.TreeID,
+			CreatedAt:     person.CreatedAt,
+			UpdatedAt:     person.UpdatedAt,
 		})
 	}
 
 	response := dto.PersonListResponse{
-		Persons: personResponses,
-		Total:   len(personResponses),
+		Persons:    personResponses,
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// SearchPersons ищет персоны дерева по имени: ?q=, born_from, born_to, limit
+func (h *PersonHandler) SearchPersons(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	search := models.PersonSearch{Query: r.URL.Query().Get("q")}
+	for param, dst := range map[string]*int{
+		"born_from": &search.BornFrom,
+		"born_to":   &search.BornTo,
+		"limit":     &search.Limit,
+	} {
+		if v := r.URL.Query().Get(param); v != "" {
+			if *dst, err = strconv.Atoi(v); err != nil {
+				return apierror.BadRequest("Invalid "+param+" format", err)
+			}
+		}
+	}
+
+	persons, err := h.personService.GetPersonsByTreeID(r.Context(), tree.ID)
+	if err != nil {
+		return apierror.InternalError("Failed to get persons", err)
+	}
+
+	// Ищем по уже скрытым данным: имя и дата рождения живых не должны находиться
+	if helpers.ShouldRedactPrivate(r) {
+		h.privacyService.RedactPersons(persons)
+	}
+
+	matches, total, err := service.RankPersons(persons, search)
+	if err != nil {
+		return apierror.BadRequest("Failed to search persons", err)
+	}
+
+	results := make([]dto.PersonMatchResponse, 0, len(matches))
+	for _, m := range matches {
+		results = append(results, dto.PersonMatchResponse{
+			PersonBriefResponse: dto.PersonBriefResponse{
+				ID:            m.ID,
+				FirstName:     m.FirstName,
+				LastName:      m.LastName,
+				BirthDate:     exactDate(m.BirthDate),
+				BirthDateText: dateText(m.BirthDate),
+				Sex:           m.Sex,
+				IsMale:        m.IsMale(),
+			},
+			Score: m.Score,
+		})
+	}
+
+	response := dto.PersonSearchResponse{
+		Results: results,
+		Total:   total,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -64,12 +150,89 @@ func (h *PersonHandler) GetPersons(w http.ResponseWriter, r *http.Request) error
 	return json.NewEncoder(w).Encode(response)
 }
 
+// personListQuery разбирает параметры списка персон
+func personListQuery(r *http.Request) (models.PersonListQuery, error) {
+	params := r.URL.Query()
+	query := models.PersonListQuery{
+		Cursor:   params.Get("cursor"),
+		Sort:     params.Get("sort"),
+		Metadata: metadataFilter(r),
+	}
+
+	for param, dst := range map[string]*int{
+		"limit":     &query.Limit,
+		"born_from": &query.BornFrom,
+		"born_to":   &query.BornTo,
+	} {
+		if v := params.Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return query, apierror.BadRequest("Invalid "+param+" format", err)
+			}
+			*dst = n
+		}
+	}
+
+	switch params.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return query, apierror.BadRequest("Invalid order", errors.New("order must be asc or desc"))
+	}
+
+	if sex := params.Get("sex"); sex != "" {
+		if !slices.Contains(models.Sexes, sex) {
+			return query, apierror.BadRequest("Invalid sex", fmt.Errorf("sex must be one of %v", models.Sexes))
+		}
+		query.Sex = sex
+	}
+
+	if v := params.Get("living"); v != "" {
+		living, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, apierror.BadRequest("Invalid living format", err)
+		}
+		query.Living = &living
+	}
+
+	return query, nil
+}
+
+// metadataFilter собирает фильтр по метаданным из параметров ?meta.<поле>=<значение>
+func metadataFilter(r *http.Request) map[string]string {
+	filter := make(map[string]string)
+	for key, values := range r.URL.Query() {
+		if name, ok := strings.CutPrefix(key, "meta."); ok {
+			filter[name] = values[0]
+		}
+	}
+	return filter
+}
+
+// requestSex возвращает пол из запроса. Устаревшее is_male учитывается, только если sex
+// не задан; без обоих полей — fallback.
+func requestSex(sex string, isMale *bool, fallback string) (string, error) {
+	switch {
+	case sex != "":
+		if !slices.Contains(models.Sexes, sex) {
+			return "", fmt.Errorf("sex must be one of %v", models.Sexes)
+		}
+		return sex, nil
+	case isMale == nil:
+		return fallback, nil
+	case *isMale:
+		return models.SexMale, nil
+	default:
+		return models.SexFemale, nil
+	}
+}
+
 // CreatePerson создаёт персону в дереве
 func (h *PersonHandler) CreatePerson(w http.ResponseWriter, r *http.Request) error {
-	treeIDStr := chi.URLParam(r, "tree_id")
-	treeID, err := strconv.Atoi(treeIDStr)
+	tree, err := helpers.GetTreeFromContext(r)
 	if err != nil {
-		return apierror.BadRequest("Invalid tree ID format", err)
+		return err
 	}
 
 	var req dto.CreatePersonRequest
@@ -78,14 +241,31 @@ func (h *PersonHandler) CreatePerson(w http.ResponseWriter, r *http.Request) err
 		return apierror.BadRequest("Invalid JSON", err)
 	}
 
+	birthDate, err := requestDate(req.BirthDate, req.BirthDateText)
+	if err != nil {
+		return apierror.BadRequest("Invalid birth date", err)
+	}
+	deathDate, err := requestDate(req.DeathDate, req.DeathDateText)
+	if err != nil {
+		return apierror.BadRequest("Invalid death date", err)
+	}
+	sex, err := requestSex(req.Sex, req.IsMale, models.SexUnknown)
+	if err != nil {
+		return apierror.BadRequest("Invalid sex", err)
+	}
+
 	person := &models.Person{
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		BirthDate: req.BirthDate,
-		DeathDate: req.DeathDate,
-		IsMale:    req.IsMale,
-		Biography: req.Biography,
-		TreeID:    treeID, // Берём из URL
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		BirthDate:    birthDate,
+		DeathDate:    deathDate,
+		BirthPlaceID: req.BirthPlaceID,
+		DeathPlaceID: req.DeathPlaceID,
+		Sex:          sex,
+		Biography:    req.Biography,
+		Privacy:      req.Privacy,
+		Metadata:     req.Metadata,
+		TreeID:       tree.ID, // Берём из URL
 	}
 
 	id, err := h.personService.CreatePerson(r.Context(), person)
@@ -94,16 +274,24 @@ func (h *PersonHandler) CreatePerson(w http.ResponseWriter, r *http.Request) err
 	}
 
 	response := dto.PersonResponse{
-		ID:        id,
-		FirstName: person.FirstName,
-		LastName:  person.LastName,
-		BirthDate: person.BirthDate,
-		DeathDate: person.DeathDate,
-		IsMale:    person.IsMale,
-		Biography: person.Biography,
-		TreeID:    person.TreeID,
-		CreatedAt: person.CreatedAt,
-		UpdatedAt: person.UpdatedAt,
+		ID:            id,
+		FirstName:     person.FirstName,
+		LastName:      person.LastName,
+		BirthDate:     exactDate(person.BirthDate),
+		BirthDateText: dateText(person.BirthDate),
+		DeathDate:     exactDate(person.DeathDate),
+		DeathDateText: dateText(person.DeathDate),
+		BirthPlaceID:  person.BirthPlaceID,
+		DeathPlaceID:  person.DeathPlaceID,
+		PortraitURL:   portraitURL(r, person),
+		Sex:           person.Sex,
+		IsMale:        person.IsMale(),
+		Biography:     person.Biography,
+		Privacy:       person.Privacy,
+		Metadata:      person.Metadata,
+		TreeID:        person.TreeID,
+		CreatedAt:     person.CreatedAt,
+		UpdatedAt:     person.UpdatedAt,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -113,37 +301,35 @@ func (h *PersonHandler) CreatePerson(w http.ResponseWriter, r *http.Request) err
 
 // GetPerson получает персону по ID
 func (h *PersonHandler) GetPerson(w http.ResponseWriter, r *http.Request) error {
-	treeIDStr := chi.URLParam(r, "tree_id")
-	_, err := strconv.Atoi(treeIDStr)
+	// Персона уже проверена middleware и принадлежит дереву из URL
+	person, err := helpers.GetPersonFromContext(r)
 	if err != nil {
-		return apierror.BadRequest("Invalid tree ID format", err)
+		return err
 	}
 
-	personIDStr := chi.URLParam(r, "person_id")
-	personID, err := strconv.Atoi(personIDStr)
-	if err != nil {
-		return apierror.BadRequest("Invalid person ID format", err)
-	}
-
-	person, err := h.personService.GetPersonByID(r.Context(), personID)
-	if err != nil {
-		if errors.Is(err, repo.ErrPersonNotFound) {
-			return apierror.NotFound("Person not found", err)
-		}
-		return apierror.InternalError("Failed to get person", err)
+	if helpers.ShouldRedactPrivate(r) {
+		h.privacyService.RedactPerson(person, time.Now())
 	}
 
 	response := dto.PersonResponse{
-		ID:        person.ID,
-		FirstName: person.FirstName,
-		LastName:  person.LastName,
-		BirthDate: person.BirthDate,
-		DeathDate: person.DeathDate,
-		IsMale:    person.IsMale,
-		Biography: person.Biography,
-		TreeID:    person.TreeID,
-		CreatedAt: person.CreatedAt,
-		UpdatedAt: person.UpdatedAt,
+		ID:            person.ID,
+		FirstName:     person.FirstName,
+		LastName:      person.LastName,
+		BirthDate:     exactDate(person.BirthDate),
+		BirthDateText: dateText(person.BirthDate),
+		DeathDate:     exactDate(person.DeathDate),
+		DeathDateText: dateText(person.DeathDate),
+		BirthPlaceID:  person.BirthPlaceID,
+		DeathPlaceID:  person.DeathPlaceID,
+		PortraitURL:   portraitURL(r, person),
+		Sex:           person.Sex,
+		IsMale:        person.IsMale(),
+		Biography:     person.Biography,
+		Privacy:       person.Privacy,
+		Metadata:      person.Metadata,
+		TreeID:        person.TreeID,
+		CreatedAt:     person.CreatedAt,
+		UpdatedAt:     person.UpdatedAt,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -153,16 +339,10 @@ func (h *PersonHandler) GetPerson(w http.ResponseWriter, r *http.Request) error
 
 // UpdatePerson обновляет персону
 func (h *PersonHandler) UpdatePerson(w http.ResponseWriter, r *http.Request) error {
-	treeIDStr := chi.URLParam(r, "tree_id")
-	_, err := strconv.Atoi(treeIDStr)
+	// Персона уже проверена middleware и принадлежит дереву из URL
+	person, err := helpers.GetPersonFromContext(r)
 	if err != nil {
-		return apierror.BadRequest("Invalid tree ID format", err)
-	}
-
-	personIDStr := chi.URLParam(r, "person_id")
-	personID, err := strconv.Atoi(personIDStr)
-	if err != nil {
-		return apierror.BadRequest("Invalid person ID format", err)
+		return err
 	}
 
 	var req dto.UpdatePersonRequest
@@ -171,40 +351,70 @@ func (h *PersonHandler) UpdatePerson(w http.ResponseWriter, r *http.Request) err
 		return apierror.BadRequest("Invalid JSON", err)
 	}
 
-	person := &models.Person{
-		ID:        personID,
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		BirthDate: req.BirthDate,
-		DeathDate: req.DeathDate,
-		IsMale:    req.IsMale,
-		Biography: req.Biography,
-		// TreeID не трогаем - он не меняется в UPDATE
+	birthDate, err := requestDate(req.BirthDate, req.BirthDateText)
+	if err != nil {
+		return apierror.BadRequest("Invalid birth date", err)
+	}
+	deathDate, err := requestDate(req.DeathDate, req.DeathDateText)
+	if err != nil {
+		return apierror.BadRequest("Invalid death date", err)
+	}
+	// Без sex и is_male пол не меняется
+	sex, err := requestSex(req.Sex, req.IsMale, person.Sex)
+	if err != nil {
+		return apierror.BadRequest("Invalid sex", err)
+	}
+
+	updated := &models.Person{
+		ID:           person.ID,
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		BirthDate:    birthDate,
+		DeathDate:    deathDate,
+		BirthPlaceID: req.BirthPlaceID,
+		DeathPlaceID: req.DeathPlaceID,
+		Sex:          sex,
+		Biography:    req.Biography,
+		Privacy:      req.Privacy,   // Пусто — не меняется
+		Metadata:     req.Metadata,  // nil — не меняется
+		TreeID:       person.TreeID, // Нужен для валидации, в UPDATE не меняется
 	}
 
-	if err := h.personService.UpdatePerson(r.Context(), person); err != nil {
+	if err := h.personService.UpdatePerson(r.Context(), updated); err != nil {
 		if errors.Is(err, repo.ErrPersonNotFound) {
 			return apierror.NotFound("Person not found", err)
 		}
+		// Новый пол совпал с полом второго родителя у общего ребенка, а дерево это запрещает
+		if errors.Is(err, repo.ErrSameSexParents) {
+			return apierror.Conflict("Same-sex parents are not allowed in this tree", err)
+		}
 		return apierror.BadRequest("Failed to update person", err)
 	}
 
-	updatedPerson, err := h.personService.GetPersonByID(r.Context(), personID)
+	updatedPerson, err := h.personService.GetPersonByID(r.Context(), person.ID)
 	if err != nil {
 		return apierror.InternalError("Failed to fetch updated person", err)
 	}
 
 	response := dto.PersonResponse{
-		ID:        updatedPerson.ID,
-		FirstName: updatedPerson.FirstName,
-		LastName:  updatedPerson.LastName,
-		BirthDate: updatedPerson.BirthDate,
-		DeathDate: updatedPerson.DeathDate,
-		IsMale:    updatedPerson.IsMale,
-		Biography: updatedPerson.Biography,
-		TreeID:    updatedPerson.TreeID,
-		CreatedAt: updatedPerson.CreatedAt,
-		UpdatedAt: updatedPerson.UpdatedAt,
+		ID:            updatedPerson.ID,
+		FirstName:     updatedPerson.FirstName,
+		LastName:      updatedPerson.LastName,
+		BirthDate:     exactDate(updatedPerson.BirthDate),
+		BirthDateText: dateText(updatedPerson.BirthDate),
+		DeathDate:     exactDate(updatedPerson.DeathDate),
+		DeathDateText: dateText(updatedPerson.DeathDate),
+		BirthPlaceID:  updatedPerson.BirthPlaceID,
+		DeathPlaceID:  updatedPerson.DeathPlaceID,
+		PortraitURL:   portraitURL(r, updatedPerson),
+		Sex:           updatedPerson.Sex,
+		IsMale:        updatedPerson.IsMale(),
+		Biography:     updatedPerson.Biography,
+		Privacy:       updatedPerson.Privacy,
+		Metadata:      updatedPerson.Metadata,
+		TreeID:        updatedPerson.TreeID,
+		CreatedAt:     updatedPerson.CreatedAt,
+		UpdatedAt:     updatedPerson.UpdatedAt,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -214,19 +424,13 @@ func (h *PersonHandler) UpdatePerson(w http.ResponseWriter, r *http.Request) err
 
 // DeletePerson удаляет персону
 func (h *PersonHandler) DeletePerson(w http.ResponseWriter, r *http.Request) error {
-	treeIDStr := chi.URLParam(r, "tree_id")
-	_, err := strconv.Atoi(treeIDStr)
-	if err != nil {
-		return apierror.BadRequest("Invalid tree ID format", err)
-	}
-
-	personIDStr := chi.URLParam(r, "person_id")
-	personID, err := strconv.Atoi(personIDStr)
+	// Персона уже проверена middleware и принадлежит дереву из URL
+	person, err := helpers.GetPersonFromContext(r)
 	if err != nil {
-		return apierror.BadRequest("Invalid person ID format", err)
+		return err
 	}
 
-	if err := h.personService.DeletePerson(r.Context(), personID); err != nil {
+	if err := h.personService.DeletePerson(r.Context(), person.ID); err != nil {
 		if errors.Is(err, repo.ErrPersonNotFound) {
 			return apierror.NotFound("Person not found", err)
 		}
@@ -236,3 +440,77 @@ func (h *PersonHandler) DeletePerson(w http.ResponseWriter, r *http.Request) err
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
+
+// MergePersons сливает персону source_id в персону из URL и возвращает результат.
+// Если слияние нарушило бы правила дерева, отвечает 409 со списком нарушений.
+func (h *PersonHandler) MergePersons(w http.ResponseWriter, r *http.Request) error {
+	person, err := helpers.GetPersonFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	var req dto.MergePersonsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	conflicts, err := h.personService.MergePersons(r.Context(), person.ID, req.SourceID, req.Fields)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMergeConflict):
+			return writeMergeConflicts(w, conflicts)
+		case errors.Is(err, repo.ErrPersonNotFound):
+			return apierror.NotFound("Person not found", err)
+		default:
+			return linkError("Failed to merge persons", err)
+		}
+	}
+
+	merged, err := h.personService.GetPersonByID(r.Context(), person.ID)
+	if err != nil {
+		return apierror.InternalError("Failed to fetch merged person", err)
+	}
+
+	response := dto.PersonResponse{
+		ID:            merged.ID,
+		FirstName:     merged.FirstName,
+		LastName:      merged.LastName,
+		BirthDate:     exactDate(merged.BirthDate),
+		BirthDateText: dateText(merged.BirthDate),
+		DeathDate:     exactDate(merged.DeathDate),
+		DeathDateText: dateText(merged.DeathDate),
+		BirthPlaceID:  merged.BirthPlaceID,
+		DeathPlaceID:  merged.DeathPlaceID,
+		PortraitURL:   portraitURL(r, merged),
+		Sex:           merged.Sex,
+		IsMale:        merged.IsMale(),
+		Biography:     merged.Biography,
+		Privacy:       merged.Privacy,
+		Metadata:      merged.Metadata,
+		TreeID:        merged.TreeID,
+		CreatedAt:     merged.CreatedAt,
+		UpdatedAt:     merged.UpdatedAt,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// writeMergeConflicts отвечает 409 со списком нарушенных правил
+func writeMergeConflicts(w http.ResponseWriter, conflicts []models.MergeConflict) error {
+	response := dto.MergeErrorResponse{
+		Error:     "Merge would break tree rules",
+		Conflicts: make([]dto.MergeConflictResponse, 0, len(conflicts)),
+	}
+	for _, c := range conflicts {
+		response.Conflicts = append(response.Conflicts, dto.MergeConflictResponse{
+			PersonID: c.PersonID,
+			Reason:   c.Reason,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	return json.NewEncoder(w).Encode(response)
+}
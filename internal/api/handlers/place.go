@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PlaceHandler struct {
+	placeService   *service.PlaceService
+	privacyService *service.PrivacyService
+}
+
+func NewPlaceHandler(placeService *service.PlaceService, privacyService *service.PrivacyService) *PlaceHandler {
+	return &PlaceHandler{
+		placeService:   placeService,
+		privacyService: privacyService,
+	}
+}
+
+// GetPlaces возвращает все места дерева
+func (h *PlaceHandler) GetPlaces(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	places, err := h.placeService.GetPlaces(r.Context(), tree.ID)
+	if err != nil {
+		return apierror.InternalError("Failed to get places", err)
+	}
+
+	placeResponses := make([]dto.PlaceResponse, 0, len(places))
+	for _, place := range places {
+		placeResponses = append(placeResponses, placeResponse(&place))
+	}
+
+	response := dto.PlaceListResponse{
+		Places: placeResponses,
+		Total:  len(placeResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// GetPlace возвращает место дерева
+func (h *PlaceHandler) GetPlace(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	placeIDStr := chi.URLParam(r, "place_id")
+	placeID, err := strconv.Atoi(placeIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid place ID format", err)
+	}
+
+	place, err := h.placeService.GetPlace(r.Context(), tree.ID, placeID)
+	if err != nil {
+		return placeError(err, "Failed to get place")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(placeResponse(place))
+}
+
+// CreatePlace создаёт место в дереве
+func (h *PlaceHandler) CreatePlace(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	var req dto.PlaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	place := placeFromRequest(&req)
+	place.TreeID = tree.ID
+
+	if _, err := h.placeService.CreatePlace(r.Context(), place); err != nil {
+		return placeError(err, "Failed to create place")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(placeResponse(place))
+}
+
+// UpdatePlace обновляет место
+func (h *PlaceHandler) UpdatePlace(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	placeIDStr := chi.URLParam(r, "place_id")
+	placeID, err := strconv.Atoi(placeIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid place ID format", err)
+	}
+
+	var req dto.PlaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	place := placeFromRequest(&req)
+	place.ID = placeID
+	place.TreeID = tree.ID
+
+	if err := h.placeService.UpdatePlace(r.Context(), place); err != nil {
+		return placeError(err, "Failed to update place")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(placeResponse(place))
+}
+
+// DeletePlace удаляет место; ссылки на него у персон, событий и вложенных мест обнуляются
+func (h *PlaceHandler) DeletePlace(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	placeIDStr := chi.URLParam(r, "place_id")
+	placeID, err := strconv.Atoi(placeIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid place ID format", err)
+	}
+
+	if err := h.placeService.DeletePlace(r.Context(), tree.ID, placeID); err != nil {
+		if errors.Is(err, repo.ErrPlaceNotFound) {
+			return apierror.NotFound("Place not found", err)
+		}
+		return apierror.InternalError("Failed to delete place", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// GetPlacesGeoJSON возвращает места рождений и смертей дерева как GeoJSON FeatureCollection
+func (h *PlaceHandler) GetPlacesGeoJSON(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	vitals, err := h.placeService.GetVitalPlaces(r.Context(), tree.ID)
+	if err != nil {
+		return apierror.InternalError("Failed to get places", err)
+	}
+
+	redact := helpers.ShouldRedactPrivate(r)
+	now := time.Now()
+
+	features := make([]dto.GeoJSONFeature, 0, len(vitals))
+	for _, vital := range vitals {
+		// Скрытые персоны на карту не попадают
+		if redact && h.privacyService.IsHidden(&vital.Person, now) {
+			continue
+		}
+
+		features = append(features, dto.GeoJSONFeature{
+			Type: "Feature",
+			Geometry: dto.GeoJSONPoint{
+				Type:        "Point",
+				Coordinates: [2]float64{vital.Longitude, vital.Latitude},
+			},
+			Properties: dto.VitalPlaceProperties{
+				PersonID:  vital.Person.ID,
+				FirstName: vital.Person.FirstName,
+				LastName:  vital.Person.LastName,
+				Event:     vital.EventType,
+				DateText:  dateText(vital.Date),
+				PlaceID:   vital.Place.ID,
+				Place:     vital.Place.Name,
+			},
+		})
+	}
+
+	response := dto.GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: features,
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+func placeError(err error, failMessage string) error {
+	switch {
+	case errors.Is(err, repo.ErrPlaceNotFound):
+		return apierror.NotFound("Place not found", err)
+	case errors.Is(err, repo.ErrPlaceAlreadyExists):
+		return apierror.Conflict("Place with this name already exists", err)
+	default:
+		return apierror.BadRequest(failMessage, err)
+	}
+}
+
+func placeFromRequest(req *dto.PlaceRequest) *models.Place {
+	// По умолчанию тип не уточняется
+	if req.Type == "" {
+		req.Type = models.PlaceOther
+	}
+
+	place := &models.Place{
+		ParentID:  req.ParentID,
+		Name:      req.Name,
+		Type:      req.Type,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}
+	for _, name := range req.Names {
+		place.Names = append(place.Names, models.PlaceName{
+			Name:     name.Name,
+			FromYear: name.FromYear,
+			ToYear:   name.ToYear,
+		})
+	}
+	return place
+}
+
+func placeResponse(p *models.Place) dto.PlaceResponse {
+	names := make([]dto.PlaceNameDTO, 0, len(p.Names))
+	for _, name := range p.Names {
+		names = append(names, dto.PlaceNameDTO{
+			Name:     name.Name,
+			FromYear: name.FromYear,
+			ToYear:   name.ToYear,
+		})
+	}
+
+	return dto.PlaceResponse{
+		ID:        p.ID,
+		TreeID:    p.TreeID,
+		ParentID:  p.ParentID,
+		Name:      p.Name,
+		Type:      p.Type,
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+		Names:     names,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
@@ -3,9 +3,11 @@ package handlers
 import (
 	"GenealogyTree/internal/api/apierror"
 	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
 	"GenealogyTree/internal/models"
 	"GenealogyTree/internal/repo"
 	"GenealogyTree/internal/service"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -16,11 +18,13 @@ import (
 
 type RelationshipHandler struct {
 	relationshipService *service.RelationshipService
+	privacyService      *service.PrivacyService
 }
 
-func NewRelationshipHandler(relationshipService *service.RelationshipService) *RelationshipHandler {
+func NewRelationshipHandler(relationshipService *service.RelationshipService, privacyService *service.PrivacyService) *RelationshipHandler {
 	return &RelationshipHandler{
 		relationshipService: relationshipService,
+		privacyService:      privacyService,
 	}
 }
 
@@ -40,12 +44,12 @@ func (h *RelationshipHandler) AddChild(w http.ResponseWriter, r *http.Request) e
 
 	// По умолчанию biological
 	if req.RelationshipType == "" {
-		req.RelationshipType = "biological"
+		req.RelationshipType = models.RelationshipBiological
 	}
 
 	relID, err := h.relationshipService.AddChild(r.Context(), personID, req.ChildID, req.RelationshipType)
 	if err != nil {
-		return apierror.BadRequest("Failed to add child", err)
+		return linkError("Failed to add child", err)
 	}
 
 	response := dto.RelationshipResponse{
@@ -76,12 +80,12 @@ func (h *RelationshipHandler) AddParent(w http.ResponseWriter, r *http.Request)
 
 	// По умолчанию biological
 	if req.RelationshipType == "" {
-		req.RelationshipType = "biological"
+		req.RelationshipType = models.RelationshipBiological
 	}
 
 	relID, err := h.relationshipService.AddParent(r.Context(), personID, req.ParentID, req.RelationshipType)
 	if err != nil {
-		return apierror.BadRequest("Failed to add parent", err)
+		return linkError("Failed to add parent", err)
 	}
 
 	response := dto.RelationshipResponse{
@@ -112,21 +116,34 @@ func (h *RelationshipHandler) CreateChildAndLink(w http.ResponseWriter, r *http.
 
 	// По умолчанию biological
 	if req.RelationshipType == "" {
-		req.RelationshipType = "biological"
+		req.RelationshipType = models.RelationshipBiological
+	}
+
+	birthDate, err := requestDate(req.BirthDate, req.BirthDateText)
+	if err != nil {
+		return apierror.BadRequest("Invalid birth date", err)
+	}
+	deathDate, err := requestDate(req.DeathDate, req.DeathDateText)
+	if err != nil {
+		return apierror.BadRequest("Invalid death date", err)
+	}
+	sex, err := requestSex(req.Sex, req.IsMale, models.SexUnknown)
+	if err != nil {
+		return apierror.BadRequest("Invalid sex", err)
 	}
 
 	child := &models.Person{
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
-		BirthDate: req.BirthDate,
-		DeathDate: req.DeathDate,
-		IsMale:    req.IsMale,
+		BirthDate: birthDate,
+		DeathDate: deathDate,
+		Sex:       sex,
 		Biography: req.Biography,
 	}
 
 	relID, err := h.relationshipService.CreateChildAndLink(r.Context(), personID, child, req.RelationshipType)
 	if err != nil {
-		return apierror.BadRequest("Failed to create child and link", err)
+		return linkError("Failed to create child and link", err)
 	}
 
 	response := dto.RelationshipResponse{
@@ -157,21 +174,34 @@ func (h *RelationshipHandler) CreateParentAndLink(w http.ResponseWriter, r *http
 
 	// По умолчанию biological
 	if req.RelationshipType == "" {
-		req.RelationshipType = "biological"
+		req.RelationshipType = models.RelationshipBiological
+	}
+
+	birthDate, err := requestDate(req.BirthDate, req.BirthDateText)
+	if err != nil {
+		return apierror.BadRequest("Invalid birth date", err)
+	}
+	deathDate, err := requestDate(req.DeathDate, req.DeathDateText)
+	if err != nil {
+		return apierror.BadRequest("Invalid death date", err)
+	}
+	sex, err := requestSex(req.Sex, req.IsMale, models.SexUnknown)
+	if err != nil {
+		return apierror.BadRequest("Invalid sex", err)
 	}
 
 	parent := &models.Person{
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
-		BirthDate: req.BirthDate,
-		DeathDate: req.DeathDate,
-		IsMale:    req.IsMale,
+		BirthDate: birthDate,
+		DeathDate: deathDate,
+		Sex:       sex,
 		Biography: req.Biography,
 	}
 
 	relID, err := h.relationshipService.CreateParentAndLink(r.Context(), personID, parent, req.RelationshipType)
 	if err != nil {
-		return apierror.BadRequest("Failed to create parent and link", err)
+		return linkError("Failed to create parent and link", err)
 	}
 
 	response := dto.RelationshipResponse{
@@ -186,6 +216,29 @@ func (h *RelationshipHandler) CreateParentAndLink(w http.ResponseWriter, r *http
 	return json.NewEncoder(w).Encode(response)
 }
 
+// linkError переводит нарушение правил связи в 409 (конфликт с уже существующими связями)
+// или 422 (связь недопустима сама по себе); остальные ошибки — 400 с failMessage
+func linkError(failMessage string, err error) error {
+	switch {
+	case errors.Is(err, repo.ErrRelationshipCycle):
+		return apierror.Conflict("Relationship would create a cycle", err)
+	case errors.Is(err, repo.ErrRelationshipExists):
+		return apierror.Conflict("Relationship already exists", err)
+	case errors.Is(err, repo.ErrTooManyParents):
+		return apierror.Conflict("Child already has 2 parents", err)
+	case errors.Is(err, repo.ErrSameSexParents):
+		return apierror.Conflict("Same-sex parents are not allowed in this tree", err)
+	case errors.Is(err, repo.ErrSelfParent):
+		return apierror.UnprocessableEntity("Person cannot be their own parent", err)
+	case errors.Is(err, repo.ErrCrossTreeRelationship):
+		return apierror.UnprocessableEntity("Parent and child must be in the same tree", err)
+	case errors.Is(err, repo.ErrUnknownRelationshipType):
+		return apierror.UnprocessableEntity("Unknown relationship type", err)
+	default:
+		return apierror.BadRequest(failMessage, err)
+	}
+}
+
 // RemoveChild удаляет связь родитель-ребенок
 func (h *RelationshipHandler) RemoveChild(w http.ResponseWriter, r *http.Request) error {
 	personIDStr := chi.URLParam(r, "person_id")
@@ -245,7 +298,7 @@ func (h *RelationshipHandler) GetAvailableChildren(w http.ResponseWriter, r *htt
 		return apierror.BadRequest("Invalid person ID format", err)
 	}
 
-	children, err := h.relationshipService.GetAvailableChildren(r.Context(), personID)
+	children, err := h.relationshipService.GetAvailableChildren(r.Context(), personID, helpers.ShouldRedactPrivate(r))
 	if err != nil {
 		if errors.Is(err, repo.ErrPersonNotFound) {
 			return apierror.NotFound("Person not found", err)
@@ -257,11 +310,13 @@ func (h *RelationshipHandler) GetAvailableChildren(w http.ResponseWriter, r *htt
 	childrenResponses := make([]dto.PersonBriefResponse, 0, len(children))
 	for _, child := range children {
 		childrenResponses = append(childrenResponses, dto.PersonBriefResponse{
-			ID:        child.ID,
-			FirstName: child.FirstName,
-			LastName:  child.LastName,
-			BirthDate: child.BirthDate,
-			IsMale:    child.IsMale,
+			ID:            child.ID,
+			FirstName:     child.FirstName,
+			LastName:      child.LastName,
+			BirthDate:     exactDate(child.BirthDate),
+			BirthDateText: dateText(child.BirthDate),
+			Sex:           child.Sex,
+			IsMale:        child.IsMale(),
 		})
 	}
 
@@ -283,7 +338,7 @@ func (h *RelationshipHandler) GetAvailableParents(w http.ResponseWriter, r *http
 		return apierror.BadRequest("Invalid person ID format", err)
 	}
 
-	parents, err := h.relationshipService.GetAvailableParents(r.Context(), personID)
+	parents, err := h.relationshipService.GetAvailableParents(r.Context(), personID, helpers.ShouldRedactPrivate(r))
 	if err != nil {
 		if errors.Is(err, repo.ErrPersonNotFound) {
 			return apierror.NotFound("Person not found", err)
@@ -295,11 +350,13 @@ func (h *RelationshipHandler) GetAvailableParents(w http.ResponseWriter, r *http
 	parentsResponses := make([]dto.PersonBriefResponse, 0, len(parents))
 	for _, parent := range parents {
 		parentsResponses = append(parentsResponses, dto.PersonBriefResponse{
-			ID:        parent.ID,
-			FirstName: parent.FirstName,
-			LastName:  parent.LastName,
-			BirthDate: parent.BirthDate,
-			IsMale:    parent.IsMale,
+			ID:            parent.ID,
+			FirstName:     parent.FirstName,
+			LastName:      parent.LastName,
+			BirthDate:     exactDate(parent.BirthDate),
+			BirthDateText: dateText(parent.BirthDate),
+			Sex:           parent.Sex,
+			IsMale:        parent.IsMale(),
 		})
 	}
 
@@ -312,3 +369,83 @@ func (h *RelationshipHandler) GetAvailableParents(w http.ResponseWriter, r *http
 	w.WriteHeader(http.StatusOK)
 	return json.NewEncoder(w).Encode(response)
 }
+
+// defaultRelativesDepth — глубина обхода, если ?depth не указан
+const defaultRelativesDepth = 10
+
+// GetAncestors возвращает предков персоны с номерами поколений
+func (h *RelationshipHandler) GetAncestors(w http.ResponseWriter, r *http.Request) error {
+	return h.getRelatives(w, r, h.relationshipService.GetAncestors, "Failed to get ancestors")
+}
+
+// GetDescendants возвращает потомков персоны с номерами поколений
+func (h *RelationshipHandler) GetDescendants(w http.ResponseWriter, r *http.Request) error {
+	return h.getRelatives(w, r, h.relationshipService.GetDescendants, "Failed to get descendants")
+}
+
+type relativesFunc func(ctx context.Context, personID, depth int) ([]models.Relative, []models.Relationship, error)
+
+func (h *RelationshipHandler) getRelatives(w http.ResponseWriter, r *http.Request, get relativesFunc, failMessage string) error {
+	personIDStr := chi.URLParam(r, "person_id")
+	personID, err := strconv.Atoi(personIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid person ID format", err)
+	}
+
+	depth := defaultRelativesDepth
+	if depthStr := r.URL.Query().Get("depth"); depthStr != "" {
+		depth, err = strconv.Atoi(depthStr)
+		if err != nil {
+			return apierror.BadRequest("Invalid depth format", err)
+		}
+	}
+
+	relatives, relationships, err := get(r.Context(), personID, depth)
+	if err != nil {
+		if errors.Is(err, repo.ErrPersonNotFound) {
+			return apierror.NotFound("Person not found", err)
+		}
+		return apierror.BadRequest(failMessage, err)
+	}
+
+	if helpers.ShouldRedactPrivate(r) {
+		h.privacyService.RedactRelatives(relatives)
+	}
+
+	relativeResponses := make([]dto.RelativeResponse, 0, len(relatives))
+	for _, relative := range relatives {
+		relativeResponses = append(relativeResponses, dto.RelativeResponse{
+			ID:            relative.ID,
+			FirstName:     relative.FirstName,
+			LastName:      relative.LastName,
+			BirthDate:     exactDate(relative.BirthDate),
+			BirthDateText: dateText(relative.BirthDate),
+			DeathDate:     exactDate(relative.DeathDate),
+			DeathDateText: dateText(relative.DeathDate),
+			Sex:           relative.Sex,
+			IsMale:        relative.IsMale(),
+			Generation:    relative.Generation,
+		})
+	}
+
+	edges := make([]dto.GraphEdgeResponse, 0, len(relationships))
+	for _, rel := range relationships {
+		edges = append(edges, dto.GraphEdgeResponse{
+			ID:               rel.ID,
+			ParentID:         rel.ParentID,
+			ChildID:          rel.ChildID,
+			RelationshipType: rel.RelationshipType,
+		})
+	}
+
+	response := dto.RelativesResponse{
+		PersonID:  personID,
+		Relatives: relativeResponses,
+		Edges:     edges,
+		Total:     len(relativeResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RelationshipTypeHandler struct {
+	relationshipTypeService *service.RelationshipTypeService
+}
+
+func NewRelationshipTypeHandler(relationshipTypeService *service.RelationshipTypeService) *RelationshipTypeHandler {
+	return &RelationshipTypeHandler{
+		relationshipTypeService: relationshipTypeService,
+	}
+}
+
+// GetRelationshipTypes возвращает встроенные типы связей
+func (h *RelationshipTypeHandler) GetRelationshipTypes(w http.ResponseWriter, r *http.Request) error {
+	return h.writeRelationshipTypes(w, r, 0)
+}
+
+// GetTreeRelationshipTypes возвращает встроенные типы связей вместе с типами дерева
+func (h *RelationshipTypeHandler) GetTreeRelationshipTypes(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	return h.writeRelationshipTypes(w, r, tree.ID)
+}
+
+func (h *RelationshipTypeHandler) writeRelationshipTypes(w http.ResponseWriter, r *http.Request, treeID int) error {
+	types, err := h.relationshipTypeService.GetRelationshipTypes(r.Context(), treeID)
+	if err != nil {
+		return apierror.InternalError("Failed to get relationship types", err)
+	}
+
+	typeResponses := make([]dto.RelationshipTypeResponse, 0, len(types))
+	for _, t := range types {
+		typeResponses = append(typeResponses, relationshipTypeResponse(&t))
+	}
+
+	response := dto.RelationshipTypeListResponse{
+		Types: typeResponses,
+		Total: len(typeResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// CreateRelationshipType добавляет тип связи в реестр дерева
+func (h *RelationshipTypeHandler) CreateRelationshipType(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	var req dto.RelationshipTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	t := &models.RelationshipType{
+		TreeID:              &tree.ID,
+		Code:                req.Code,
+		Name:                req.Name,
+		CountsTowardParents: req.CountsTowardParents,
+		Blood:               req.Blood,
+	}
+
+	if _, err := h.relationshipTypeService.CreateRelationshipType(r.Context(), t); err != nil {
+		if errors.Is(err, repo.ErrRelationshipTypeExists) {
+			return apierror.Conflict("Relationship type with this code already exists", err)
+		}
+		return apierror.BadRequest("Failed to create relationship type", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(relationshipTypeResponse(t))
+}
+
+// DeleteRelationshipType удаляет тип связи дерева
+func (h *RelationshipTypeHandler) DeleteRelationshipType(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	typeID, err := strconv.Atoi(chi.URLParam(r, "type_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid relationship type ID format", err)
+	}
+
+	if err := h.relationshipTypeService.DeleteRelationshipType(r.Context(), tree.ID, typeID); err != nil {
+		switch {
+		case errors.Is(err, repo.ErrRelationshipTypeNotFound):
+			return apierror.NotFound("Relationship type not found", err)
+		case errors.Is(err, repo.ErrRelationshipTypeInUse):
+			return apierror.Conflict("Relationship type is used by relationships", err)
+		default:
+			return apierror.InternalError("Failed to delete relationship type", err)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func relationshipTypeResponse(t *models.RelationshipType) dto.RelationshipTypeResponse {
+	return dto.RelationshipTypeResponse{
+		ID:                  t.ID,
+		TreeID:              t.TreeID,
+		Code:                t.Code,
+		Name:                t.Name,
+		CountsTowardParents: t.CountsTowardParents,
+		Blood:               t.Blood,
+		CreatedAt:           t.CreatedAt,
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ShareHandler struct {
+	shareService *service.ShareService
+}
+
+func NewShareHandler(shareService *service.ShareService) *ShareHandler {
+	return &ShareHandler{
+		shareService: shareService,
+	}
+}
+
+// GetShareLinks возвращает все публичные ссылки дерева
+func (h *ShareHandler) GetShareLinks(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	links, err := h.shareService.GetShareLinks(r.Context(), tree.ID)
+	if err != nil {
+		return apierror.InternalError("Failed to get share links", err)
+	}
+
+	now := time.Now()
+	linkResponses := make([]dto.ShareLinkResponse, 0, len(links))
+	for _, link := range links {
+		linkResponses = append(linkResponses, shareLinkResponse(&link, "", now))
+	}
+
+	response := dto.ShareLinkListResponse{
+		Links: linkResponses,
+		Total: len(linkResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// CreateShareLink создаёт публичную ссылку; токен возвращается только здесь
+func (h *ShareHandler) CreateShareLink(w http.ResponseWriter, r *http.Request) error {
+	userID, err := helpers.GetUserIDFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	var req dto.CreateShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	token, link, err := h.shareService.CreateShareLink(r.Context(), tree.ID, userID, req.ExpiresAt)
+	if err != nil {
+		return apierror.BadRequest("Failed to create share link", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(shareLinkResponse(link, token, time.Now()))
+}
+
+// RevokeShareLink отзывает публичную ссылку
+func (h *ShareHandler) RevokeShareLink(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	linkIDStr := chi.URLParam(r, "link_id")
+	linkID, err := strconv.Atoi(linkIDStr)
+	if err != nil {
+		return apierror.BadRequest("Invalid link ID format", err)
+	}
+
+	if err := h.shareService.RevokeShareLink(r.Context(), tree.ID, linkID); err != nil {
+		if errors.Is(err, repo.ErrShareLinkNotFound) {
+			return apierror.NotFound("Share link not found", err)
+		}
+		return apierror.InternalError("Failed to revoke share link", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func shareLinkResponse(l *models.ShareLink, token string, now time.Time) dto.ShareLinkResponse {
+	return dto.ShareLinkResponse{
+		ID:        l.ID,
+		TreeID:    l.TreeID,
+		Token:     token,
+		CreatedBy: l.CreatedBy,
+		Active:    l.IsActive(now),
+		ExpiresAt: l.ExpiresAt,
+		RevokedAt: l.RevokedAt,
+		CreatedAt: l.CreatedAt,
+	}
+}
@@ -0,0 +1,417 @@
+package handlers
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/dto"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SourceHandler struct {
+	sourceService *service.SourceService
+}
+
+func NewSourceHandler(sourceService *service.SourceService) *SourceHandler {
+	return &SourceHandler{
+		sourceService: sourceService,
+	}
+}
+
+// GetSources возвращает источники дерева
+func (h *SourceHandler) GetSources(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	sources, err := h.sourceService.GetSources(r.Context(), tree.ID)
+	if err != nil {
+		return apierror.InternalError("Failed to get sources", err)
+	}
+
+	sourceResponses := make([]dto.SourceResponse, 0, len(sources))
+	for _, src := range sources {
+		sourceResponses = append(sourceResponses, sourceResponse(&src))
+	}
+
+	response := dto.SourceListResponse{
+		Sources: sourceResponses,
+		Total:   len(sourceResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// GetSource возвращает источник дерева
+func (h *SourceHandler) GetSource(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	sourceID, err := strconv.Atoi(chi.URLParam(r, "source_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid source ID format", err)
+	}
+
+	src, err := h.sourceService.GetSource(r.Context(), tree.ID, sourceID)
+	if err != nil {
+		return sourceError(err, "Failed to get source")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(sourceResponse(src))
+}
+
+// CreateSource создаёт источник в дереве
+func (h *SourceHandler) CreateSource(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	var req dto.SourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	src := &models.Source{
+		TreeID:     tree.ID,
+		Title:      req.Title,
+		Author:     req.Author,
+		Repository: req.Repository,
+		URL:        req.URL,
+		ArchiveRef: req.ArchiveRef,
+	}
+
+	if _, err := h.sourceService.CreateSource(r.Context(), src); err != nil {
+		return sourceError(err, "Failed to create source")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(sourceResponse(src))
+}
+
+// UpdateSource обновляет источник
+func (h *SourceHandler) UpdateSource(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	sourceID, err := strconv.Atoi(chi.URLParam(r, "source_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid source ID format", err)
+	}
+
+	var req dto.SourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	src := &models.Source{
+		ID:         sourceID,
+		TreeID:     tree.ID,
+		Title:      req.Title,
+		Author:     req.Author,
+		Repository: req.Repository,
+		URL:        req.URL,
+		ArchiveRef: req.ArchiveRef,
+	}
+
+	if err := h.sourceService.UpdateSource(r.Context(), src); err != nil {
+		return sourceError(err, "Failed to update source")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(sourceResponse(src))
+}
+
+// DeleteSource удаляет источник вместе с его цитатами
+func (h *SourceHandler) DeleteSource(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	sourceID, err := strconv.Atoi(chi.URLParam(r, "source_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid source ID format", err)
+	}
+
+	if err := h.sourceService.DeleteSource(r.Context(), tree.ID, sourceID); err != nil {
+		if errors.Is(err, repo.ErrSourceNotFound) {
+			return apierror.NotFound("Source not found", err)
+		}
+		return apierror.InternalError("Failed to delete source", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// GetSourceFacts возвращает все факты, которые подтверждает источник
+func (h *SourceHandler) GetSourceFacts(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	sourceID, err := strconv.Atoi(chi.URLParam(r, "source_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid source ID format", err)
+	}
+
+	cited, err := h.sourceService.GetSourceFacts(r.Context(), tree.ID, sourceID, helpers.ShouldRedactPrivate(r))
+	if err != nil {
+		return sourceError(err, "Failed to get source facts")
+	}
+
+	facts := make([]models.Fact, 0, len(cited))
+	citations := make([]*models.Citation, 0, len(cited))
+	for i := range cited {
+		facts = append(facts, cited[i].Fact)
+		citations = append(citations, &cited[i].Citation)
+	}
+
+	return h.writeFacts(w, facts, citations)
+}
+
+// GetUncitedFacts возвращает факты дерева, не подтверждённые ни одним источником
+func (h *SourceHandler) GetUncitedFacts(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	facts, err := h.sourceService.GetUncitedFacts(r.Context(), tree.ID, helpers.ShouldRedactPrivate(r))
+	if err != nil {
+		return apierror.InternalError("Failed to get uncited facts", err)
+	}
+
+	return h.writeFacts(w, facts, nil)
+}
+
+// writeFacts отдаёт факты; citations — цитаты фактов по индексу или nil.
+// Факты скрытых персон и их связей сервис для зрителя уже отбросил.
+func (h *SourceHandler) writeFacts(w http.ResponseWriter, facts []models.Fact, citations []*models.Citation) error {
+	factResponses := make([]dto.FactResponse, 0, len(facts))
+	for i, fact := range facts {
+
+		response := dto.FactResponse{Field: fact.Field}
+		if fact.Person != nil {
+			response.Person = &dto.PersonBriefResponse{
+				ID:            fact.Person.ID,
+				FirstName:     fact.Person.FirstName,
+				LastName:      fact.Person.LastName,
+				BirthDate:     exactDate(fact.Person.BirthDate),
+				BirthDateText: dateText(fact.Person.BirthDate),
+				Sex:           fact.Person.Sex,
+				IsMale:        fact.Person.IsMale(),
+			}
+		}
+		if fact.Relationship != nil {
+			response.Relationship = &dto.GraphEdgeResponse{
+				ID:               fact.Relationship.ID,
+				ParentID:         fact.Relationship.ParentID,
+				ChildID:          fact.Relationship.ChildID,
+				RelationshipType: fact.Relationship.RelationshipType,
+			}
+		}
+		if citations != nil {
+			citation := citationResponse(citations[i])
+			response.Citation = &citation
+		}
+		factResponses = append(factResponses, response)
+	}
+
+	response := dto.FactListResponse{
+		Facts: factResponses,
+		Total: len(factResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// GetCitations возвращает цитаты дерева; ?person_id= или ?relationship_id= фильтруют их
+func (h *SourceHandler) GetCitations(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	var personID, relationshipID int
+	if v := r.URL.Query().Get("person_id"); v != "" {
+		if personID, err = strconv.Atoi(v); err != nil {
+			return apierror.BadRequest("Invalid person ID format", err)
+		}
+	}
+	if v := r.URL.Query().Get("relationship_id"); v != "" {
+		if relationshipID, err = strconv.Atoi(v); err != nil {
+			return apierror.BadRequest("Invalid relationship ID format", err)
+		}
+	}
+
+	// Зрителю не отдаются цитаты скрытых персон и их связей, в том числе по ?person_id=
+	citations, err := h.sourceService.GetCitations(r.Context(), tree.ID, personID, relationshipID, helpers.ShouldRedactPrivate(r))
+	if err != nil {
+		return apierror.InternalError("Failed to get citations", err)
+	}
+
+	citationResponses := make([]dto.CitationResponse, 0, len(citations))
+	for _, c := range citations {
+		citationResponses = append(citationResponses, citationResponse(&c))
+	}
+
+	response := dto.CitationListResponse{
+		Citations: citationResponses,
+		Total:     len(citationResponses),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// CreateCitation прикрепляет источник к персоне или связи
+func (h *SourceHandler) CreateCitation(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	var req dto.CreateCitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	citation := &models.Citation{
+		SourceID:       req.SourceID,
+		PersonID:       req.PersonID,
+		RelationshipID: req.RelationshipID,
+		Field:          req.Field,
+		Page:           req.Page,
+		Quality:        req.Quality,
+	}
+
+	if _, err := h.sourceService.CreateCitation(r.Context(), tree.ID, citation); err != nil {
+		return sourceError(err, "Failed to create citation")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(citationResponse(citation))
+}
+
+// UpdateCitation обновляет страницу и качество цитаты
+func (h *SourceHandler) UpdateCitation(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	citationID, err := strconv.Atoi(chi.URLParam(r, "citation_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid citation ID format", err)
+	}
+
+	var req dto.UpdateCitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierror.BadRequest("Invalid JSON", err)
+	}
+
+	citation := &models.Citation{
+		ID:      citationID,
+		Page:    req.Page,
+		Quality: req.Quality,
+	}
+
+	if err := h.sourceService.UpdateCitation(r.Context(), tree.ID, citation); err != nil {
+		return sourceError(err, "Failed to update citation")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(citationResponse(citation))
+}
+
+// DeleteCitation удаляет цитату
+func (h *SourceHandler) DeleteCitation(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	citationID, err := strconv.Atoi(chi.URLParam(r, "citation_id"))
+	if err != nil {
+		return apierror.BadRequest("Invalid citation ID format", err)
+	}
+
+	if err := h.sourceService.DeleteCitation(r.Context(), tree.ID, citationID); err != nil {
+		if errors.Is(err, repo.ErrCitationNotFound) {
+			return apierror.NotFound("Citation not found", err)
+		}
+		return apierror.InternalError("Failed to delete citation", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func sourceError(err error, failMessage string) error {
+	switch {
+	case errors.Is(err, repo.ErrSourceNotFound):
+		return apierror.NotFound("Source not found", err)
+	case errors.Is(err, repo.ErrCitationNotFound):
+		return apierror.NotFound("Citation not found", err)
+	case errors.Is(err, repo.ErrPersonNotFound):
+		return apierror.NotFound("Person not found", err)
+	case errors.Is(err, repo.ErrRelationshipNotFound):
+		return apierror.NotFound("Relationship not found", err)
+	default:
+		return apierror.BadRequest(failMessage, err)
+	}
+}
+
+func sourceResponse(src *models.Source) dto.SourceResponse {
+	return dto.SourceResponse{
+		ID:         src.ID,
+		TreeID:     src.TreeID,
+		Title:      src.Title,
+		Author:     src.Author,
+		Repository: src.Repository,
+		URL:        src.URL,
+		ArchiveRef: src.ArchiveRef,
+		CreatedAt:  src.CreatedAt,
+		UpdatedAt:  src.UpdatedAt,
+	}
+}
+
+func citationResponse(c *models.Citation) dto.CitationResponse {
+	return dto.CitationResponse{
+		ID:             c.ID,
+		SourceID:       c.SourceID,
+		PersonID:       c.PersonID,
+		RelationshipID: c.RelationshipID,
+		Field:          c.Field,
+		Page:           c.Page,
+		Quality:        c.Quality,
+		CreatedAt:      c.CreatedAt,
+		UpdatedAt:      c.UpdatedAt,
+	}
+}
@@ -11,46 +11,59 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-
-	"github.com/go-chi/chi/v5"
 )
 
 type TreeHandler struct {
-	treeService *service.TreeService
+	treeService    *service.TreeService
+	privacyService *service.PrivacyService
 }
 
-func NewTreeHandler(treeService *service.TreeService) *TreeHandler {
+func NewTreeHandler(treeService *service.TreeService, privacyService *service.PrivacyService) *TreeHandler {
 	return &TreeHandler{
-		treeService: treeService,
+		treeService:    treeService,
+		privacyService: privacyService,
 	}
 }
 
-// GetTrees получает все деревья текущего пользователя
+// GetTrees получает страницу деревьев текущего пользователя: ?limit=, cursor=
 func (h *TreeHandler) GetTrees(w http.ResponseWriter, r *http.Request) error {
 	userID, err := helpers.GetUserIDFromContext(r)
 	if err != nil {
 		return err
 	}
 
-	trees, err := h.treeService.GetTreesByOwnerID(r.Context(), userID)
+	query := models.TreeListQuery{Cursor: r.URL.Query().Get("cursor")}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil {
+			return apierror.BadRequest("Invalid limit format", err)
+		}
+	}
+
+	page, err := h.treeService.GetTreesByUserID(r.Context(), userID, query)
 	if err != nil {
+		if errors.Is(err, repo.ErrInvalidCursor) {
+			return apierror.BadRequest("Invalid cursor", err)
+		}
 		return apierror.InternalError("Failed to get trees", err)
 	}
 
-	treeResponses := make([]dto.TreeResponse, 0, len(trees))
-	for _, tree := range trees {
+	treeResponses := make([]dto.TreeResponse, 0, len(page.Trees))
+	for _, tree := range page.Trees {
 		treeResponses = append(treeResponses, dto.TreeResponse{
-			ID:        tree.ID,
-			OwnerID:   tree.OwnerID,
-			Name:      tree.Name,
-			CreatedAt: tree.CreatedAt,
-			UpdatedAt: tree.UpdatedAt,
+			ID:                  tree.ID,
+			OwnerID:             tree.OwnerID,
+			Name:                tree.Name,
+			Role:                tree.Role,
+			AllowSameSexParents: tree.AllowSameSexParents,
+			CreatedAt:           tree.CreatedAt,
+			UpdatedAt:           tree.UpdatedAt,
 		})
 	}
 
 	response := dto.TreeListResponse{
-		Trees: treeResponses,
-		Total: len(treeResponses),
+		Trees:      treeResponses,
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -71,8 +84,9 @@ func (h *TreeHandler) CreateTree(w http.ResponseWriter, r *http.Request) error {
 	}
 
 	tree := &models.Tree{
-		OwnerID: userID,
-		Name:    req.Name,
+		OwnerID:             userID,
+		Name:                req.Name,
+		AllowSameSexParents: req.AllowSameSexParents,
 	}
 
 	id, err := h.treeService.CreateTree(r.Context(), tree)
@@ -81,11 +95,13 @@ func (h *TreeHandler) CreateTree(w http.ResponseWriter, r *http.Request) error {
 	}
 
 	response := dto.TreeResponse{
-		ID:        id,
-		OwnerID:   tree.OwnerID,
-		Name:      tree.Name,
-		CreatedAt: tree.CreatedAt,
-		UpdatedAt: tree.UpdatedAt,
+		ID:                  id,
+		OwnerID:             tree.OwnerID,
+		Name:                tree.Name,
+		Role:                models.RoleOwner,
+		AllowSameSexParents: tree.AllowSameSexParents,
+		CreatedAt:           tree.CreatedAt,
+		UpdatedAt:           tree.UpdatedAt,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -93,38 +109,21 @@ func (h *TreeHandler) CreateTree(w http.ResponseWriter, r *http.Request) error {
 	return json.NewEncoder(w).Encode(response)
 }
 
-// GetTree получает дерево по ID (с проверкой владельца)
+// GetTree получает дерево по ID
 func (h *TreeHandler) GetTree(w http.ResponseWriter, r *http.Request) error {
-	userID, err := helpers.GetUserIDFromContext(r)
+	tree, err := helpers.GetTreeFromContext(r)
 	if err != nil {
 		return err
 	}
 
-	treeIDStr := chi.URLParam(r, "tree_id")
-	treeID, err := strconv.Atoi(treeIDStr)
-	if err != nil {
-		return apierror.BadRequest("Invalid tree ID format", err)
-	}
-
-	tree, err := h.treeService.GetTreeByID(r.Context(), treeID)
-	if err != nil {
-		if errors.Is(err, repo.ErrTreeNotFound) {
-			return apierror.NotFound("Tree not found", err)
-		}
-		return apierror.InternalError("Failed to get tree", err)
-	}
-
-	// Проверяем что пользователь владелец этого дерева
-	if tree.OwnerID != userID {
-		return apierror.NotFound("Tree not found", nil)
-	}
-
 	response := dto.TreeResponse{
-		ID:        tree.ID,
-		OwnerID:   tree.OwnerID,
-		Name:      tree.Name,
-		CreatedAt: tree.CreatedAt,
-		UpdatedAt: tree.UpdatedAt,
+		ID:                  tree.ID,
+		OwnerID:             tree.OwnerID,
+		Name:                tree.Name,
+		Role:                tree.Role,
+		AllowSameSexParents: tree.AllowSameSexParents,
+		CreatedAt:           tree.CreatedAt,
+		UpdatedAt:           tree.UpdatedAt,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -132,55 +131,46 @@ func (h *TreeHandler) GetTree(w http.ResponseWriter, r *http.Request) error {
 	return json.NewEncoder(w).Encode(response)
 }
 
-// UpdateTree обновляет дерево (с проверкой владельца)
+// UpdateTree обновляет дерево
 func (h *TreeHandler) UpdateTree(w http.ResponseWriter, r *http.Request) error {
-	userID, err := helpers.GetUserIDFromContext(r)
+	existingTree, err := helpers.GetTreeFromContext(r)
 	if err != nil {
 		return err
 	}
 
-	treeIDStr := chi.URLParam(r, "tree_id")
-	treeID, err := strconv.Atoi(treeIDStr)
-	if err != nil {
-		return apierror.BadRequest("Invalid tree ID format", err)
-	}
-
-	// Проверяем что дерево существует и принадлежит пользователю
-	existingTree, err := h.treeService.GetTreeByID(r.Context(), treeID)
-	if err != nil {
-		if errors.Is(err, repo.ErrTreeNotFound) {
-			return apierror.NotFound("Tree not found", err)
-		}
-		return apierror.InternalError("Failed to get tree", err)
-	}
-
-	if existingTree.OwnerID != userID {
-		return apierror.NotFound("Tree not found", nil)
-	}
-
 	var req dto.UpdateTreeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return apierror.BadRequest("Invalid JSON", err)
 	}
 
 	tree := &models.Tree{
-		ID:   treeID,
-		Name: req.Name,
+		ID:                  existingTree.ID,
+		Name:                req.Name,
+		AllowSameSexParents: existingTree.AllowSameSexParents,
+	}
+	if req.AllowSameSexParents != nil {
+		tree.AllowSameSexParents = *req.AllowSameSexParents
 	}
 
 	if err := h.treeService.UpdateTree(r.Context(), tree); err != nil {
 		if errors.Is(err, repo.ErrTreeNotFound) {
 			return apierror.NotFound("Tree not found", err)
 		}
+		// Запрет нельзя включить, пока в дереве есть однополые пары родителей
+		if errors.Is(err, repo.ErrSameSexParents) {
+			return apierror.Conflict("Tree already has same-sex parents", err)
+		}
 		return apierror.BadRequest("Failed to update tree", err)
 	}
 
 	response := dto.TreeResponse{
-		ID:        tree.ID,
-		OwnerID:   existingTree.OwnerID,
-		Name:      tree.Name,
-		CreatedAt: existingTree.CreatedAt,
-		UpdatedAt: tree.UpdatedAt,
+		ID:                  tree.ID,
+		OwnerID:             existingTree.OwnerID,
+		Name:                tree.Name,
+		Role:                existingTree.Role,
+		AllowSameSexParents: tree.AllowSameSexParents,
+		CreatedAt:           existingTree.CreatedAt,
+		UpdatedAt:           tree.UpdatedAt,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -188,33 +178,14 @@ func (h *TreeHandler) UpdateTree(w http.ResponseWriter, r *http.Request) error {
 	return json.NewEncoder(w).Encode(response)
 }
 
-// DeleteTree удаляет дерево (с проверкой владельца)
+// DeleteTree удаляет дерево
 func (h *TreeHandler) DeleteTree(w http.ResponseWriter, r *http.Request) error {
-	userID, err := helpers.GetUserIDFromContext(r)
+	tree, err := helpers.GetTreeFromContext(r)
 	if err != nil {
 		return err
 	}
 
-	treeIDStr := chi.URLParam(r, "tree_id")
-	treeID, err := strconv.Atoi(treeIDStr)
-	if err != nil {
-		return apierror.BadRequest("Invalid tree ID format", err)
-	}
-
-	// Проверяем что дерево существует и принадлежит пользователю
-	tree, err := h.treeService.GetTreeByID(r.Context(), treeID)
-	if err != nil {
-		if errors.Is(err, repo.ErrTreeNotFound) {
-			return apierror.NotFound("Tree not found", err)
-		}
-		return apierror.InternalError("Failed to get tree", err)
-	}
-
-	if tree.OwnerID != userID {
-		return apierror.NotFound("Tree not found", nil)
-	}
-
-	if err := h.treeService.DeleteTree(r.Context(), treeID); err != nil {
+	if err := h.treeService.DeleteTree(r.Context(), tree.ID); err != nil {
 		if errors.Is(err, repo.ErrTreeNotFound) {
 			return apierror.NotFound("Tree not found", err)
 		}
@@ -228,66 +199,152 @@ func (h *TreeHandler) DeleteTree(w http.ResponseWriter, r *http.Request) error {
 	})
 }
 
-// GetTreeGraph возвращает граф дерева для визуализации (с проверкой владельца)
+// GetTreeGraph возвращает граф дерева для визуализации
 func (h *TreeHandler) GetTreeGraph(w http.ResponseWriter, r *http.Request) error {
-	userID, err := helpers.GetUserIDFromContext(r)
+	tree, err := helpers.GetTreeFromContext(r)
 	if err != nil {
 		return err
 	}
 
-	treeIDStr := chi.URLParam(r, "tree_id")
-	treeID, err := strconv.Atoi(treeIDStr)
-	if err != nil {
-		return apierror.BadRequest("Invalid tree ID format", err)
-	}
-
-	// Проверяем что дерево существует и принадлежит пользователю
-	tree, err := h.treeService.GetTreeByID(r.Context(), treeID)
+	graph, err := h.treeService.GetTreeGraph(r.Context(), tree.ID)
 	if err != nil {
-		if errors.Is(err, repo.ErrTreeNotFound) {
-			return apierror.NotFound("Tree not found", err)
-		}
-		return apierror.InternalError("Failed to get tree", err)
-	}
-
-	if tree.OwnerID != userID {
-		return apierror.NotFound("Tree not found", nil)
+		return apierror.InternalError("Failed to get tree graph", err)
 	}
 
-	persons, relationships, err := h.treeService.GetTreeGraph(r.Context(), treeID)
-	if err != nil {
-		return apierror.InternalError("Failed to get tree graph", err)
+	if helpers.ShouldRedactPrivate(r) {
+		h.privacyService.RedactGraph(graph)
 	}
 
 	// Формируем nodes
-	nodes := make([]dto.GraphNodeResponse, 0, len(persons))
-	for _, person := range persons {
+	nodes := make([]dto.GraphNodeResponse, 0, len(graph.Persons))
+	for _, person := range graph.Persons {
 		nodes = append(nodes, dto.GraphNodeResponse{
-			ID:        person.ID,
-			FirstName: person.FirstName,
-			LastName:  person.LastName,
-			BirthDate: person.BirthDate,
-			DeathDate: person.DeathDate,
-			IsMale:    person.IsMale,
+			ID:            person.ID,
+			FirstName:     person.FirstName,
+			LastName:      person.LastName,
+			BirthDate:     exactDate(person.BirthDate),
+			BirthDateText: dateText(person.BirthDate),
+			DeathDate:     exactDate(person.DeathDate),
+			DeathDateText: dateText(person.DeathDate),
+			PortraitURL:   portraitURL(r, &person),
+			ThumbnailURL:  portraitThumbnailURL(r, &person, models.ThumbnailSmall),
+			Sex:           person.Sex,
+			IsMale:        person.IsMale(),
 		})
 	}
 
 	// Формируем edges
-	edges := make([]dto.GraphEdgeResponse, 0, len(relationships))
-	for _, rel := range relationships {
+	edges := make([]dto.GraphEdgeResponse, 0, len(graph.Relationships))
+	for _, rel := range graph.Relationships {
 		edges = append(edges, dto.GraphEdgeResponse{
+			ID:               rel.ID,
 			ParentID:         rel.ParentID,
 			ChildID:          rel.ChildID,
 			RelationshipType: rel.RelationshipType,
 		})
 	}
 
+	// Формируем союзы
+	partnerships := make([]dto.GraphPartnershipResponse, 0, len(graph.Partnerships))
+	for _, p := range graph.Partnerships {
+		partnerships = append(partnerships, dto.GraphPartnershipResponse{
+			ID:        p.ID,
+			Person1ID: p.Person1ID,
+			Person2ID: p.Person2ID,
+			Type:      p.Type,
+			Status:    p.Status,
+			StartDate: p.StartDate,
+			EndDate:   p.EndDate,
+		})
+	}
+
 	response := dto.GraphResponse{
-		Nodes: nodes,
-		Edges: edges,
+		Nodes:        nodes,
+		Edges:        edges,
+		Partnerships: partnerships,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// GetIntegrity возвращает отчёт о целостности связей дерева
+func (h *TreeHandler) GetIntegrity(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.treeService.GetIntegrityReport(r.Context(), tree.ID)
+	if err != nil {
+		return apierror.InternalError("Failed to check tree integrity", err)
+	}
+
+	cycles := make([]dto.CycleResponse, 0, len(report.Cycles))
+	for _, cycle := range report.Cycles {
+		cycles = append(cycles, dto.CycleResponse{PersonIDs: cycle})
+	}
+
+	response := dto.IntegrityReportResponse{
+		TreeID: report.TreeID,
+		Valid:  len(cycles) == 0,
+		Cycles: cycles,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(response)
+}
+
+// GetDuplicates возвращает возможные дубли персон дерева: ?limit=
+func (h *TreeHandler) GetDuplicates(w http.ResponseWriter, r *http.Request) error {
+	tree, err := helpers.GetTreeFromContext(r)
+	if err != nil {
+		return err
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			return apierror.BadRequest("Invalid limit format", err)
+		}
+	}
+
+	pairs, total, err := h.treeService.FindDuplicates(r.Context(), tree.ID, limit)
+	if err != nil {
+		return apierror.InternalError("Failed to find duplicates", err)
+	}
+
+	duplicates := make([]dto.DuplicateResponse, 0, len(pairs))
+	for _, pair := range pairs {
+		duplicates = append(duplicates, dto.DuplicateResponse{
+			Person1: personBrief(&pair.Person1),
+			Person2: personBrief(&pair.Person2),
+			Score:   pair.Score,
+			Reasons: pair.Reasons,
+		})
+	}
+
+	response := dto.DuplicateListResponse{
+		Duplicates: duplicates,
+		Total:      total,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	return json.NewEncoder(w).Encode(response)
 }
+
+// personBrief формирует краткий ответ о персоне
+func personBrief(p *models.Person) dto.PersonBriefResponse {
+	return dto.PersonBriefResponse{
+		ID:            p.ID,
+		FirstName:     p.FirstName,
+		LastName:      p.LastName,
+		BirthDate:     exactDate(p.BirthDate),
+		BirthDateText: dateText(p.BirthDate),
+		Sex:           p.Sex,
+		IsMale:        p.IsMale(),
+	}
+}
@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/models"
 	"net/http"
 )
 
@@ -13,3 +14,28 @@ func GetUserIDFromContext(r *http.Request) (int, error) {
 	}
 	return userID, nil
 }
+
+// GetTreeFromContext извлекает дерево, доступ к которому проверил middleware
+func GetTreeFromContext(r *http.Request) (*models.Tree, error) {
+	tree, ok := r.Context().Value("tree").(*models.Tree)
+	if !ok {
+		return nil, apierror.InternalError("Tree access was not checked", nil)
+	}
+	return tree, nil
+}
+
+// GetPersonFromContext извлекает персону, доступ к которой проверил middleware
+func GetPersonFromContext(r *http.Request) (*models.Person, error) {
+	person, ok := r.Context().Value("person").(*models.Person)
+	if !ok {
+		return nil, apierror.InternalError("Person access was not checked", nil)
+	}
+	return person, nil
+}
+
+// ShouldRedactPrivate — данные живых и закрытых персон нужно скрыть
+// (зритель дерева или публичная ссылка)
+func ShouldRedactPrivate(r *http.Request) bool {
+	redact, _ := r.Context().Value("redact_private").(bool)
+	return redact
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/api/helpers"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TreeAccessMiddleware проверяет доступ к дереву {tree_id} и добавляет его в контекст.
+// Подключается через With(), чтобы параметры маршрута были уже разобраны.
+func TreeAccessMiddleware(accessService *service.AccessService, required service.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := helpers.GetUserIDFromContext(r)
+			if err != nil {
+				respondWithError(w, apierror.BadRequest("User not authenticated", err))
+				return
+			}
+
+			treeID, err := strconv.Atoi(chi.URLParam(r, "tree_id"))
+			if err != nil {
+				respondWithError(w, apierror.BadRequest("Invalid tree ID format", err))
+				return
+			}
+
+			tree, err := accessService.AuthorizeTree(r.Context(), userID, treeID, required)
+			if err != nil {
+				respondWithError(w, accessError(err))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), "tree", tree)
+			ctx = context.WithValue(ctx, "redact_private", !accessService.CanSeePrivate(tree))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// PersonAccessMiddleware проверяет доступ к дереву персоны {person_id}
+// и добавляет персону и дерево в контекст. Если в маршруте есть {tree_id},
+// персона должна принадлежать этому дереву.
+func PersonAccessMiddleware(accessService *service.AccessService, required service.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := helpers.GetUserIDFromContext(r)
+			if err != nil {
+				respondWithError(w, apierror.BadRequest("User not authenticated", err))
+				return
+			}
+
+			personID, err := strconv.Atoi(chi.URLParam(r, "person_id"))
+			if err != nil {
+				respondWithError(w, apierror.BadRequest("Invalid person ID format", err))
+				return
+			}
+
+			person, tree, err := accessService.AuthorizePerson(r.Context(), userID, personID, required)
+			if err != nil {
+				respondWithError(w, accessError(err))
+				return
+			}
+
+			if treeIDStr := chi.URLParam(r, "tree_id"); treeIDStr != "" {
+				treeID, err := strconv.Atoi(treeIDStr)
+				if err != nil {
+					respondWithError(w, apierror.BadRequest("Invalid tree ID format", err))
+					return
+				}
+				if treeID != tree.ID {
+					respondWithError(w, apierror.NotFound("Person not found", nil))
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), "tree", tree)
+			ctx = context.WithValue(ctx, "person", person)
+			ctx = context.WithValue(ctx, "redact_private", !accessService.CanSeePrivate(tree))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// accessError переводит ошибку проверки доступа в ответ API
+func accessError(err error) *apierror.APIError {
+	switch {
+	case errors.Is(err, repo.ErrTreeNotFound):
+		return apierror.NotFound("Tree not found", err)
+	case errors.Is(err, repo.ErrPersonNotFound):
+		return apierror.NotFound("Person not found", err)
+	case errors.Is(err, service.ErrForbidden):
+		return apierror.Forbidden("Access denied", err)
+	default:
+		return apierror.InternalError("Failed to check access", err)
+	}
+}
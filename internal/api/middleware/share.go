@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"GenealogyTree/internal/api/apierror"
+	"GenealogyTree/internal/repo"
+	"GenealogyTree/internal/service"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ShareTokenMiddleware открывает дерево по токену публичной ссылки {token}.
+// Дерево доступно только на чтение, данные живых и закрытых персон скрываются.
+func ShareTokenMiddleware(shareService *service.ShareService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tree, err := shareService.ResolveToken(r.Context(), chi.URLParam(r, "token"))
+			if err != nil {
+				if errors.Is(err, repo.ErrShareLinkNotFound) || errors.Is(err, repo.ErrTreeNotFound) {
+					respondWithError(w, apierror.NotFound("Share link not found", err))
+					return
+				}
+				respondWithError(w, apierror.InternalError("Failed to resolve share link", err))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), "tree", tree)
+			ctx = context.WithValue(ctx, "redact_private", true)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -18,22 +18,44 @@ import (
 type handlerFunc func(http.ResponseWriter, *http.Request) error
 
 type Router struct {
-	Mux                 *chi.Mux
-	services            *service.Container
-	personHandler       *handlers.PersonHandler
-	treeHandler         *handlers.TreeHandler
-	relationshipHandler *handlers.RelationshipHandler
-	authHandler         *handlers.AuthHandler
+	Mux                     *chi.Mux
+	services                *service.Container
+	personHandler           *handlers.PersonHandler
+	treeHandler             *handlers.TreeHandler
+	relationshipHandler     *handlers.RelationshipHandler
+	authHandler             *handlers.AuthHandler
+	gedcomHandler           *handlers.GedcomHandler
+	kinshipHandler          *handlers.KinshipHandler
+	partnershipHandler      *handlers.PartnershipHandler
+	memberHandler           *handlers.MemberHandler
+	shareHandler            *handlers.ShareHandler
+	eventHandler            *handlers.EventHandler
+	placeHandler            *handlers.PlaceHandler
+	sourceHandler           *handlers.SourceHandler
+	mediaHandler            *handlers.MediaHandler
+	customFieldHandler      *handlers.CustomFieldHandler
+	relationshipTypeHandler *handlers.RelationshipTypeHandler
 }
 
 func NewRouter(services *service.Container) *Router {
 	r := &Router{
-		Mux:                 chi.NewRouter(),
-		services:            services,
-		personHandler:       handlers.NewPersonHandler(services.Person),
-		treeHandler:         handlers.NewTreeHandler(services.Tree),
-		relationshipHandler: handlers.NewRelationshipHandler(services.Relationship),
-		authHandler:         handlers.NewAuthHandler(services.Auth),
+		Mux:                     chi.NewRouter(),
+		services:                services,
+		personHandler:           handlers.NewPersonHandler(services.Person, services.Privacy),
+		treeHandler:             handlers.NewTreeHandler(services.Tree, services.Privacy),
+		relationshipHandler:     handlers.NewRelationshipHandler(services.Relationship, services.Privacy),
+		authHandler:             handlers.NewAuthHandler(services.Auth),
+		gedcomHandler:           handlers.NewGedcomHandler(services.Gedcom),
+		kinshipHandler:          handlers.NewKinshipHandler(services.Kinship),
+		partnershipHandler:      handlers.NewPartnershipHandler(services.Partnership, services.Privacy),
+		memberHandler:           handlers.NewMemberHandler(services.Member),
+		shareHandler:            handlers.NewShareHandler(services.Share),
+		eventHandler:            handlers.NewEventHandler(services.Event, services.Partnership, services.Privacy),
+		placeHandler:            handlers.NewPlaceHandler(services.Place, services.Privacy),
+		sourceHandler:           handlers.NewSourceHandler(services.Source),
+		mediaHandler:            handlers.NewMediaHandler(services.Media, services.Privacy),
+		customFieldHandler:      handlers.NewCustomFieldHandler(services.CustomField),
+		relationshipTypeHandler: handlers.NewRelationshipTypeHandler(services.RelationshipType),
 	}
 
 	r.initMiddleware()
@@ -59,6 +81,18 @@ func (r *Router) initRoutes() {
 	r.Mux.Post("/api/auth/register", r.handler(r.authHandler.Register))
 	r.Mux.Post("/api/auth/login", r.handler(r.authHandler.Login))
 
+	// Публичные ссылки: дерево только на чтение, живые и закрытые персоны скрыты
+	r.Mux.Group(func(shared chi.Router) {
+		shared.Use(appMiddleware.ShareTokenMiddleware(r.services.Share))
+
+		shared.Get("/api/shared/{token}", r.handler(r.treeHandler.GetTree))
+		shared.Get("/api/shared/{token}/persons", r.handler(r.personHandler.GetPersons))
+		shared.Get("/api/shared/{token}/graph", r.handler(r.treeHandler.GetTreeGraph))
+		shared.Get("/api/shared/{token}/places.geojson", r.handler(r.placeHandler.GetPlacesGeoJSON))
+		shared.Get("/api/shared/{token}/media/{media_id}/content", r.handler(r.mediaHandler.GetSharedMediaContent))
+		shared.Get("/api/shared/{token}/media/{media_id}/thumbnails/{size}", r.handler(r.mediaHandler.GetSharedThumbnail))
+	})
+
 	// ========================================
 	// Защищённые endpoints (ТРЕБУЮТ токен)
 	// ========================================
@@ -70,34 +104,120 @@ func (r *Router) initRoutes() {
 		protected.Post("/api/auth/logout", r.handler(r.authHandler.Logout))
 		protected.Get("/api/profile", r.handler(r.authHandler.GetProfile))
 
+		// Проверка доступа к дереву: по {tree_id} или по дереву персоны {person_id}
+		readTree := appMiddleware.TreeAccessMiddleware(r.services.Access, service.PermissionRead)
+		writeTree := appMiddleware.TreeAccessMiddleware(r.services.Access, service.PermissionWrite)
+		adminTree := appMiddleware.TreeAccessMiddleware(r.services.Access, service.PermissionAdmin)
+		ownTree := appMiddleware.TreeAccessMiddleware(r.services.Access, service.PermissionOwner)
+		readPerson := appMiddleware.PersonAccessMiddleware(r.services.Access, service.PermissionRead)
+		writePerson := appMiddleware.PersonAccessMiddleware(r.services.Access, service.PermissionWrite)
+
 		// Trees
 		protected.Get("/api/trees", r.handler(r.treeHandler.GetTrees))
 		protected.Post("/api/trees", r.handler(r.treeHandler.CreateTree))
-		protected.Get("/api/trees/{tree_id}", r.handler(r.treeHandler.GetTree))
-		protected.Put("/api/trees/{tree_id}", r.handler(r.treeHandler.UpdateTree))
-		protected.Delete("/api/trees/{tree_id}", r.handler(r.treeHandler.DeleteTree))
+		protected.With(readTree).Get("/api/trees/{tree_id}", r.handler(r.treeHandler.GetTree))
+		protected.With(adminTree).Put("/api/trees/{tree_id}", r.handler(r.treeHandler.UpdateTree))
+		protected.With(ownTree).Delete("/api/trees/{tree_id}", r.handler(r.treeHandler.DeleteTree))
+
+		// Members
+		protected.With(readTree).Get("/api/trees/{tree_id}/members", r.handler(r.memberHandler.GetMembers))
+		protected.With(adminTree).Post("/api/trees/{tree_id}/members", r.handler(r.memberHandler.AddMember))
+		protected.With(adminTree).Put("/api/trees/{tree_id}/members/{user_id}", r.handler(r.memberHandler.UpdateMember))
+		protected.With(adminTree).Delete("/api/trees/{tree_id}/members/{user_id}", r.handler(r.memberHandler.RemoveMember))
+
+		// Share links
+		protected.With(adminTree).Get("/api/trees/{tree_id}/share-links", r.handler(r.shareHandler.GetShareLinks))
+		protected.With(adminTree).Post("/api/trees/{tree_id}/share-links", r.handler(r.shareHandler.CreateShareLink))
+		protected.With(adminTree).Delete("/api/trees/{tree_id}/share-links/{link_id}", r.handler(r.shareHandler.RevokeShareLink))
+
+		// Places
+		protected.With(readTree).Get("/api/trees/{tree_id}/places", r.handler(r.placeHandler.GetPlaces))
+		protected.With(writeTree).Post("/api/trees/{tree_id}/places", r.handler(r.placeHandler.CreatePlace))
+		protected.With(readTree).Get("/api/trees/{tree_id}/places/{place_id}", r.handler(r.placeHandler.GetPlace))
+		protected.With(writeTree).Put("/api/trees/{tree_id}/places/{place_id}", r.handler(r.placeHandler.UpdatePlace))
+		protected.With(writeTree).Delete("/api/trees/{tree_id}/places/{place_id}", r.handler(r.placeHandler.DeletePlace))
+		protected.With(readTree).Get("/api/trees/{tree_id}/places.geojson", r.handler(r.placeHandler.GetPlacesGeoJSON))
+
+		// Sources & citations
+		protected.With(readTree).Get("/api/trees/{tree_id}/sources", r.handler(r.sourceHandler.GetSources))
+		protected.With(writeTree).Post("/api/trees/{tree_id}/sources", r.handler(r.sourceHandler.CreateSource))
+		protected.With(readTree).Get("/api/trees/{tree_id}/sources/{source_id}", r.handler(r.sourceHandler.GetSource))
+		protected.With(writeTree).Put("/api/trees/{tree_id}/sources/{source_id}", r.handler(r.sourceHandler.UpdateSource))
+		protected.With(writeTree).Delete("/api/trees/{tree_id}/sources/{source_id}", r.handler(r.sourceHandler.DeleteSource))
+		protected.With(readTree).Get("/api/trees/{tree_id}/sources/{source_id}/facts", r.handler(r.sourceHandler.GetSourceFacts))
+		protected.With(readTree).Get("/api/trees/{tree_id}/citations", r.handler(r.sourceHandler.GetCitations))
+		protected.With(writeTree).Post("/api/trees/{tree_id}/citations", r.handler(r.sourceHandler.CreateCitation))
+		protected.With(writeTree).Put("/api/trees/{tree_id}/citations/{citation_id}", r.handler(r.sourceHandler.UpdateCitation))
+		protected.With(writeTree).Delete("/api/trees/{tree_id}/citations/{citation_id}", r.handler(r.sourceHandler.DeleteCitation))
+		protected.With(readTree).Get("/api/trees/{tree_id}/uncited-facts", r.handler(r.sourceHandler.GetUncitedFacts))
+
+		// Custom fields
+		protected.With(readTree).Get("/api/trees/{tree_id}/custom-fields", r.handler(r.customFieldHandler.GetCustomFields))
+		protected.With(adminTree).Post("/api/trees/{tree_id}/custom-fields", r.handler(r.customFieldHandler.CreateCustomField))
+		protected.With(adminTree).Put("/api/trees/{tree_id}/custom-fields/{field_id}", r.handler(r.customFieldHandler.UpdateCustomField))
+		protected.With(adminTree).Delete("/api/trees/{tree_id}/custom-fields/{field_id}", r.handler(r.customFieldHandler.DeleteCustomField))
+
+		// Relationship types
+		protected.Get("/api/relationship-types", r.handler(r.relationshipTypeHandler.GetRelationshipTypes))
+		protected.With(readTree).Get("/api/trees/{tree_id}/relationship-types", r.handler(r.relationshipTypeHandler.GetTreeRelationshipTypes))
+		protected.With(adminTree).Post("/api/trees/{tree_id}/relationship-types", r.handler(r.relationshipTypeHandler.CreateRelationshipType))
+		protected.With(adminTree).Delete("/api/trees/{tree_id}/relationship-types/{type_id}", r.handler(r.relationshipTypeHandler.DeleteRelationshipType))
 
 		// Persons
-		protected.Get("/api/trees/{tree_id}/persons", r.handler(r.personHandler.GetPersons))
-		protected.Post("/api/trees/{tree_id}/persons", r.handler(r.personHandler.CreatePerson))
-		protected.Get("/api/trees/{tree_id}/persons/{person_id}", r.handler(r.personHandler.GetPerson))
-		protected.Put("/api/trees/{tree_id}/persons/{person_id}", r.handler(r.personHandler.UpdatePerson))
-		protected.Delete("/api/trees/{tree_id}/persons/{person_id}", r.handler(r.personHandler.DeletePerson))
+		protected.With(readTree).Get("/api/trees/{tree_id}/persons", r.handler(r.personHandler.GetPersons))
+		protected.With(readTree).Get("/api/trees/{tree_id}/persons/search", r.handler(r.personHandler.SearchPersons))
+		protected.With(writeTree).Post("/api/trees/{tree_id}/persons", r.handler(r.personHandler.CreatePerson))
+		protected.With(readPerson).Get("/api/trees/{tree_id}/persons/{person_id}", r.handler(r.personHandler.GetPerson))
+		protected.With(writePerson).Put("/api/trees/{tree_id}/persons/{person_id}", r.handler(r.personHandler.UpdatePerson))
+		protected.With(writePerson).Delete("/api/trees/{tree_id}/persons/{person_id}", r.handler(r.personHandler.DeletePerson))
+		protected.With(writePerson).Post("/api/trees/{tree_id}/persons/{person_id}/merge", r.handler(r.personHandler.MergePersons))
+		protected.With(writePerson).Put("/api/trees/{tree_id}/persons/{person_id}/portrait", r.handler(r.mediaHandler.UploadPortrait))
 
 		// Relationships
-		protected.Post("/api/persons/{person_id}/children", r.handler(r.relationshipHandler.AddChild))
-		protected.Post("/api/persons/{person_id}/parents", r.handler(r.relationshipHandler.AddParent))
-		protected.Post("/api/persons/{person_id}/children/new", r.handler(r.relationshipHandler.CreateChildAndLink))
-		protected.Post("/api/persons/{person_id}/parents/new", r.handler(r.relationshipHandler.CreateParentAndLink))
-		protected.Delete("/api/persons/{person_id}/children/{child_id}", r.handler(r.relationshipHandler.RemoveChild))
-		protected.Delete("/api/persons/{person_id}/parents/{parent_id}", r.handler(r.relationshipHandler.RemoveParent))
+		protected.With(writePerson).Post("/api/persons/{person_id}/children", r.handler(r.relationshipHandler.AddChild))
+		protected.With(writePerson).Post("/api/persons/{person_id}/parents", r.handler(r.relationshipHandler.AddParent))
+		protected.With(writePerson).Post("/api/persons/{person_id}/children/new", r.handler(r.relationshipHandler.CreateChildAndLink))
+		protected.With(writePerson).Post("/api/persons/{person_id}/parents/new", r.handler(r.relationshipHandler.CreateParentAndLink))
+		protected.With(writePerson).Delete("/api/persons/{person_id}/children/{child_id}", r.handler(r.relationshipHandler.RemoveChild))
+		protected.With(writePerson).Delete("/api/persons/{person_id}/parents/{parent_id}", r.handler(r.relationshipHandler.RemoveParent))
+
+		// Partners
+		protected.With(readPerson).Get("/api/persons/{person_id}/partners", r.handler(r.partnershipHandler.GetPartners))
+		protected.With(writePerson).Post("/api/persons/{person_id}/partners", r.handler(r.partnershipHandler.AddPartner))
+		protected.With(writePerson).Put("/api/persons/{person_id}/partners/{partnership_id}", r.handler(r.partnershipHandler.UpdatePartner))
+		protected.With(writePerson).Delete("/api/persons/{person_id}/partners/{partnership_id}", r.handler(r.partnershipHandler.RemovePartner))
+
+		// Events
+		protected.With(readPerson).Get("/api/persons/{person_id}/events", r.handler(r.eventHandler.GetEvents))
+		protected.With(writePerson).Post("/api/persons/{person_id}/events", r.handler(r.eventHandler.CreateEvent))
+		protected.With(writePerson).Put("/api/persons/{person_id}/events/{event_id}", r.handler(r.eventHandler.UpdateEvent))
+		protected.With(writePerson).Delete("/api/persons/{person_id}/events/{event_id}", r.handler(r.eventHandler.DeleteEvent))
+
+		// Media
+		protected.With(readPerson).Get("/api/persons/{person_id}/media", r.handler(r.mediaHandler.GetMedia))
+		protected.With(writePerson).Post("/api/persons/{person_id}/media", r.handler(r.mediaHandler.UploadMedia))
+		protected.With(readPerson).Get("/api/persons/{person_id}/media/{media_id}/content", r.handler(r.mediaHandler.GetMediaContent))
+		protected.With(readPerson).Get("/api/persons/{person_id}/media/{media_id}/thumbnails/{size}", r.handler(r.mediaHandler.GetThumbnail))
+		protected.With(writePerson).Delete("/api/persons/{person_id}/media/{media_id}", r.handler(r.mediaHandler.DeleteMedia))
+		protected.With(writePerson).Put("/api/persons/{person_id}/portrait", r.handler(r.mediaHandler.SetPortrait))
 
 		// Available
-		protected.Get("/api/persons/{person_id}/available-children", r.handler(r.relationshipHandler.GetAvailableChildren))
-		protected.Get("/api/persons/{person_id}/available-parents", r.handler(r.relationshipHandler.GetAvailableParents))
+		protected.With(readPerson).Get("/api/persons/{person_id}/available-children", r.handler(r.relationshipHandler.GetAvailableChildren))
+		protected.With(readPerson).Get("/api/persons/{person_id}/available-parents", r.handler(r.relationshipHandler.GetAvailableParents))
+
+		// Ancestors / Descendants
+		protected.With(readPerson).Get("/api/persons/{person_id}/ancestors", r.handler(r.relationshipHandler.GetAncestors))
+		protected.With(readPerson).Get("/api/persons/{person_id}/descendants", r.handler(r.relationshipHandler.GetDescendants))
 
 		// Graph
-		protected.Get("/api/trees/{tree_id}/graph", r.handler(r.treeHandler.GetTreeGraph))
+		protected.With(readTree).Get("/api/trees/{tree_id}/graph", r.handler(r.treeHandler.GetTreeGraph))
+		protected.With(readTree).Get("/api/trees/{tree_id}/kinship", r.handler(r.kinshipHandler.GetKinship))
+		protected.With(readTree).Get("/api/trees/{tree_id}/integrity", r.handler(r.treeHandler.GetIntegrity))
+		protected.With(writeTree).Get("/api/trees/{tree_id}/duplicates", r.handler(r.treeHandler.GetDuplicates))
+
+		// GEDCOM
+		protected.With(writeTree).Post("/api/trees/{tree_id}/import/gedcom", r.handler(r.gedcomHandler.ImportGedcom))
+		protected.With(readTree).Get("/api/trees/{tree_id}/export/gedcom", r.handler(r.gedcomHandler.ExportGedcom))
 	})
 }
 
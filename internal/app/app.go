@@ -2,6 +2,7 @@ package app
 
 import (
 	"GenealogyTree/internal/api"
+	"GenealogyTree/internal/blob"
 	"GenealogyTree/internal/config"
 	_ "GenealogyTree/internal/logger"
 	"GenealogyTree/internal/repo"
@@ -36,8 +37,23 @@ func Run(ctx context.Context) error {
 
 	slog.Info("✅ Connected to database", "db", conf.Database.Name)
 
+	blobs, err := newBlobStore(conf.Media)
+	if err != nil {
+		slog.Error("❌ Failed to initialize media storage", "error", err)
+		return err
+	}
+
+	slog.Info("✅ Media storage initialized", "driver", conf.Media.Driver)
+
+	thumbnails, err := blob.NewLocalStore(conf.Media.ThumbnailDir)
+	if err != nil {
+		slog.Error("❌ Failed to initialize thumbnail storage", "error", err)
+		return err
+	}
+
 	// Создаём контейнер со ВСЕМИ сервисами
-	services := service.NewContainer(storage, conf.JWT.SecretKey)
+	maxUploadSize := int64(conf.Media.MaxUploadMB) << 20
+	services := service.NewContainer(storage, blobs, thumbnails, conf.JWT.SecretKey, conf.Privacy.LivingYears, maxUploadSize)
 	slog.Info("✅ Services initialized")
 
 	// Передаём контейнер в роутер
@@ -52,3 +68,21 @@ func Run(ctx context.Context) error {
 	slog.Info("🟢 Server exited gracefully")
 	return nil
 }
+
+// newBlobStore создаёт хранилище файлов по настройке MEDIA_DRIVER
+func newBlobStore(conf config.MediaConfig) (blob.BlobStore, error) {
+	switch conf.Driver {
+	case "local":
+		return blob.NewLocalStore(conf.LocalDir)
+	case "s3":
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:  conf.S3.Endpoint,
+			Region:    conf.S3.Region,
+			Bucket:    conf.S3.Bucket,
+			AccessKey: conf.S3.AccessKey,
+			SecretKey: conf.S3.SecretKey,
+		})
+	default:
+		return nil, fmt.Errorf("unknown media driver %q, expected local or s3", conf.Driver)
+	}
+}
@@ -0,0 +1,22 @@
+// Package blob хранит содержимое файлов (фото, сканы) вне базы данных.
+// В базе остаются только ключ, хэш и метаданные.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound — объекта с таким ключом нет в хранилище
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore — хранилище файлов по ключу. Ключ — относительный путь через "/".
+type BlobStore interface {
+	// Put сохраняет size байт из r под ключом key, перезаписывая существующий объект
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get открывает объект на чтение; вызывающий закрывает ридер
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект; отсутствие объекта ошибкой не считается
+	Delete(ctx context.Context, key string) error
+}
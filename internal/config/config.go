@@ -3,12 +3,15 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 )
 
 type Config struct {
 	Database DatabaseConfig
 	ApiConf  HttpConfig
 	JWT      JWTConfig
+	Privacy  PrivacyConfig
+	Media    MediaConfig
 }
 
 type DatabaseConfig struct {
@@ -28,6 +31,30 @@ type JWTConfig struct {
 	SecretKey string
 }
 
+type PrivacyConfig struct {
+	// LivingYears — персона без даты смерти, родившаяся менее LivingYears лет назад, считается живой
+	LivingYears int
+}
+
+type MediaConfig struct {
+	// Driver — хранилище файлов: local (каталог на диске) или s3
+	Driver   string
+	LocalDir string
+	// ThumbnailDir — каталог на диске для превью фото при любом Driver
+	ThumbnailDir string
+	// MaxUploadMB — предельный размер одного файла
+	MaxUploadMB int
+	S3          S3Config
+}
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
 func NewConfig() *Config {
 	secret := getEnv("JWT_SECRET_KEY", "")
 	if secret == "" {
@@ -49,6 +76,22 @@ func NewConfig() *Config {
 		JWT: JWTConfig{
 			SecretKey: secret,
 		},
+		Privacy: PrivacyConfig{
+			LivingYears: getEnvInt("PRIVACY_LIVING_YEARS", 100),
+		},
+		Media: MediaConfig{
+			Driver:       getEnv("MEDIA_DRIVER", "local"),
+			LocalDir:     getEnv("MEDIA_LOCAL_DIR", "./data/media"),
+			ThumbnailDir: getEnv("MEDIA_THUMBNAIL_DIR", "./data/thumbnails"),
+			MaxUploadMB:  getEnvInt("MEDIA_MAX_UPLOAD_MB", 20),
+			S3: S3Config{
+				Endpoint:  getEnv("S3_ENDPOINT", "http://localhost:9000"),
+				Region:    getEnv("S3_REGION", "us-east-1"),
+				Bucket:    getEnv("S3_BUCKET", "genealogy-media"),
+				AccessKey: getEnv("S3_ACCESS_KEY", ""),
+				SecretKey: getEnv("S3_SECRET_KEY", ""),
+			},
+		},
 	}
 }
 
@@ -59,6 +102,19 @@ func getEnv(key string, defaultVal string) string {
 	return defaultVal
 }
 
+func getEnvInt(key string, defaultVal int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultVal
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		panic(fmt.Sprintf("%s must be an integer, got %q", key, value))
+	}
+	return n
+}
+
 func (db *DatabaseConfig) BuildDSN() string {
 	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
 		db.User,
@@ -0,0 +1,228 @@
+package gedcom
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Node — одна строка GEDCOM вместе с вложенными строками
+type Node struct {
+	Level    int
+	XRef     string // "@I1@" без собачек → "I1"
+	Tag      string
+	Value    string
+	Line     int // номер строки в файле (для отчётов)
+	Children []*Node
+}
+
+// Document — разобранный GEDCOM файл
+type Document struct {
+	Header  *Node
+	Records []*Node
+	byXRef  map[string]*Node
+}
+
+// maxLineLength — защита от слишком длинных строк
+const maxLineLength = 1 << 20
+
+// Parse читает GEDCOM 5.5.1 / 7.0 и строит дерево записей.
+// Строки CONT/CONC склеиваются со значением родительской строки.
+func Parse(r io.Reader) (*Document, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	scanner.Split(scanLines)
+
+	doc := &Document{byXRef: make(map[string]*Node)}
+	var stack []*Node
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		raw := scanner.Text()
+		if lineNo == 1 {
+			raw = strings.TrimPrefix(raw, "\ufeff")
+		}
+		raw = strings.TrimLeft(raw, " \t")
+		if raw == "" {
+			continue
+		}
+
+		node, err := parseLine(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		node.Line = lineNo
+
+		if node.Level == 0 {
+			stack = append(stack[:0], node)
+			switch node.Tag {
+			case "HEAD":
+				doc.Header = node
+			case "TRLR":
+				// конец файла
+			default:
+				doc.Records = append(doc.Records, node)
+				if node.XRef != "" {
+					doc.byXRef[node.XRef] = node
+				}
+			}
+			continue
+		}
+
+		if node.Level > len(stack) {
+			return nil, fmt.Errorf("line %d: unexpected level %d", lineNo, node.Level)
+		}
+		parent := stack[node.Level-1]
+
+		// Продолжение значения родительской строки
+		switch node.Tag {
+		case "CONT":
+			parent.Value += "\n" + node.Value
+			continue
+		case "CONC":
+			parent.Value += node.Value
+			continue
+		}
+
+		parent.Children = append(parent.Children, node)
+		stack = append(stack[:node.Level], node)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read gedcom: %w", err)
+	}
+
+	if doc.Header == nil {
+		return nil, fmt.Errorf("missing HEAD record")
+	}
+
+	return doc, nil
+}
+
+// parseLine разбирает строку вида "level [@xref@] TAG [value]"
+func parseLine(raw string) (*Node, error) {
+	levelStr, rest, _ := strings.Cut(raw, " ")
+	level, err := strconv.Atoi(levelStr)
+	if err != nil || level < 0 {
+		return nil, fmt.Errorf("invalid level %q", levelStr)
+	}
+
+	node := &Node{Level: level}
+
+	if strings.HasPrefix(rest, "@") {
+		xref, after, ok := strings.Cut(rest, " ")
+		if !ok || len(xref) < 3 || !strings.HasSuffix(xref, "@") {
+			return nil, fmt.Errorf("invalid xref %q", xref)
+		}
+		node.XRef = strings.Trim(xref, "@")
+		rest = after
+	}
+
+	tag, value, _ := strings.Cut(rest, " ")
+	if tag == "" {
+		return nil, fmt.Errorf("missing tag")
+	}
+	node.Tag = strings.ToUpper(tag)
+	node.Value = strings.ReplaceAll(value, "@@", "@")
+
+	return node, nil
+}
+
+// scanLines делит поток по CR, LF или CRLF
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\r' {
+			if i+1 < len(data) {
+				if data[i+1] == '\n' {
+					return i + 2, data[:i], nil
+				}
+				return i + 1, data[:i], nil
+			}
+			if !atEOF {
+				// Ждём следующий байт, чтобы не разорвать CRLF
+				return 0, nil, nil
+			}
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Version возвращает версию GEDCOM из заголовка (HEAD.GEDC.VERS)
+func (d *Document) Version() string {
+	if gedc := d.Header.Child("GEDC"); gedc != nil {
+		return gedc.ChildValue("VERS")
+	}
+	return ""
+}
+
+// Charset возвращает кодировку из заголовка (HEAD.CHAR), в 7.0 всегда UTF-8
+func (d *Document) Charset() string {
+	if char := d.Header.ChildValue("CHAR"); char != "" {
+		return strings.ToUpper(char)
+	}
+	return "UTF-8"
+}
+
+// Lookup находит запись верхнего уровня по xref
+func (d *Document) Lookup(xref string) *Node {
+	return d.byXRef[xref]
+}
+
+// Child возвращает первую вложенную строку с тегом
+func (n *Node) Child(tag string) *Node {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if c.Tag == tag {
+			return c
+		}
+	}
+	return nil
+}
+
+// ChildrenByTag возвращает все вложенные строки с тегом
+func (n *Node) ChildrenByTag(tag string) []*Node {
+	if n == nil {
+		return nil
+	}
+	var result []*Node
+	for _, c := range n.Children {
+		if c.Tag == tag {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// ChildValue возвращает значение первой вложенной строки с тегом
+func (n *Node) ChildValue(tag string) string {
+	if c := n.Child(tag); c != nil {
+		return strings.TrimSpace(c.Value)
+	}
+	return ""
+}
+
+// Pointer возвращает xref из значения вида "@I1@" (или "" если это не указатель)
+func (n *Node) Pointer() string {
+	v := strings.TrimSpace(n.Value)
+	if len(v) >= 3 && strings.HasPrefix(v, "@") && strings.HasSuffix(v, "@") {
+		xref := strings.Trim(v, "@")
+		if xref == "VOID" {
+			return ""
+		}
+		return xref
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package gedcom
+
+import "strings"
+
+// Individual — запись INDI
+type Individual struct {
+	XRef      string
+	Line      int
+	GivenName string
+	Surname   string
+	Sex       string // M, F, U, X
+	BirthDate string // фраза даты GEDCOM как есть
+	DeathDate string
+	Note      string
+	FamC      []ChildLink // семьи, где персона — ребенок
+}
+
+// ChildLink — ссылка INDI.FAMC с типом родства (PEDI)
+type ChildLink struct {
+	Family   string
+	Pedigree string // birth, adopted, foster, sealing, ...
+}
+
+// Family — запись FAM
+type Family struct {
+	XRef     string
+	Line     int
+	Husband  string
+	Wife     string
+	Children []string
+}
+
+// Individuals возвращает все записи INDI документа
+func (d *Document) Individuals() []Individual {
+	var result []Individual
+	for _, rec := range d.Records {
+		if rec.Tag != "INDI" {
+			continue
+		}
+
+		ind := Individual{
+			XRef: rec.XRef,
+			Line: rec.Line,
+			Sex:  strings.ToUpper(rec.ChildValue("SEX")),
+		}
+
+		if name := rec.Child("NAME"); name != nil {
+			ind.GivenName, ind.Surname = splitName(name)
+		}
+
+		if birt := rec.Child("BIRT"); birt != nil {
+			ind.BirthDate = birt.ChildValue("DATE")
+		}
+		if deat := rec.Child("DEAT"); deat != nil {
+			ind.DeathDate = deat.ChildValue("DATE")
+		}
+
+		ind.Note = d.noteText(rec)
+
+		for _, famc := range rec.ChildrenByTag("FAMC") {
+			if xref := famc.Pointer(); xref != "" {
+				ind.FamC = append(ind.FamC, ChildLink{
+					Family:   xref,
+					Pedigree: strings.ToLower(famc.ChildValue("PEDI")),
+				})
+			}
+		}
+
+		result = append(result, ind)
+	}
+	return result
+}
+
+// Families возвращает все записи FAM документа
+func (d *Document) Families() []Family {
+	var result []Family
+	for _, rec := range d.Records {
+		if rec.Tag != "FAM" {
+			continue
+		}
+
+		fam := Family{
+			XRef: rec.XRef,
+			Line: rec.Line,
+		}
+		if husb := rec.Child("HUSB"); husb != nil {
+			fam.Husband = husb.Pointer()
+		}
+		if wife := rec.Child("WIFE"); wife != nil {
+			fam.Wife = wife.Pointer()
+		}
+		for _, chil := range rec.ChildrenByTag("CHIL") {
+			if xref := chil.Pointer(); xref != "" {
+				fam.Children = append(fam.Children, xref)
+			}
+		}
+
+		result = append(result, fam)
+	}
+	return result
+}
+
+// splitName извлекает имя и фамилию из NAME ("John /Smith/") или GIVN/SURN
+func splitName(name *Node) (given, surname string) {
+	value := strings.TrimSpace(name.Value)
+	if start := strings.Index(value, "/"); start < 0 {
+		given = value
+	} else if end := strings.Index(value[start+1:], "/"); end < 0 {
+		given = value[:start]
+		surname = value[start+1:]
+	} else {
+		end += start + 1
+		given = value[:start] + " " + value[end+1:]
+		surname = value[start+1 : end]
+	}
+
+	// Явные GIVN/SURN точнее, чем разбор строки
+	if v := name.ChildValue("GIVN"); v != "" {
+		given = v
+	}
+	if v := name.ChildValue("SURN"); v != "" {
+		surname = v
+	}
+
+	return strings.Join(strings.Fields(given), " "), strings.TrimSpace(surname)
+}
+
+// noteText собирает текст NOTE (встроенные и по ссылке, SNOTE в 7.0)
+func (d *Document) noteText(rec *Node) string {
+	var parts []string
+	for _, c := range rec.Children {
+		if c.Tag != "NOTE" && c.Tag != "SNOTE" {
+			continue
+		}
+		text := c.Value
+		if xref := c.Pointer(); xref != "" {
+			if note := d.Lookup(xref); note != nil {
+				text = note.Value
+			} else {
+				continue
+			}
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
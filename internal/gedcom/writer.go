@@ -0,0 +1,128 @@
+package gedcom
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxValueLength — длина значения, после которой строка переносится через CONC
+const maxValueLength = 200
+
+var monthNames = [...]string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+// Writer пишет GEDCOM построчно, сам разбивая длинные и многострочные значения
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Line пишет строку "level [@xref@] TAG [value]".
+// Переводы строк в value превращаются в CONT, длинные строки — в CONC.
+func (w *Writer) Line(level int, xref, tag, value string) {
+	lines := strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if i == 0 {
+			w.writeChunked(level, xref, tag, line)
+		} else {
+			w.writeChunked(level+1, "", "CONT", line)
+		}
+	}
+}
+
+// Pointer пишет строку со ссылкой на запись: "level TAG @xref@"
+func (w *Writer) Pointer(level int, tag, xref string) {
+	w.write(level, "", tag, "@"+xref+"@")
+}
+
+// Flush дописывает буфер и возвращает первую ошибку записи
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+func (w *Writer) writeChunked(level int, xref, tag, value string) {
+	for {
+		chunk, rest := splitValue(value)
+		w.write(level, xref, tag, escapeValue(chunk))
+		if rest == "" {
+			return
+		}
+		value = rest
+		level, xref, tag = continuationLevel(level, tag), "", "CONC"
+	}
+}
+
+// continuationLevel — CONC на уровень ниже исходной строки
+// (или на том же уровне, если переносится сама CONT/CONC)
+func continuationLevel(level int, tag string) int {
+	if tag == "CONC" || tag == "CONT" {
+		return level
+	}
+	return level + 1
+}
+
+func (w *Writer) write(level int, xref, tag, value string) {
+	if w.err != nil {
+		return
+	}
+
+	line := fmt.Sprintf("%d", level)
+	if xref != "" {
+		line += " @" + xref + "@"
+	}
+	line += " " + tag
+	if value != "" {
+		line += " " + value
+	}
+
+	_, w.err = w.w.WriteString(line + "\r\n")
+}
+
+// splitValue отрезает кусок не длиннее maxValueLength, не разрывая символы
+// и не оставляя пробел на границе (его теряют многие программы)
+func splitValue(value string) (chunk, rest string) {
+	if utf8.RuneCountInString(value) <= maxValueLength {
+		return value, ""
+	}
+
+	cut, count := 0, 0
+	for i := range value {
+		if count == maxValueLength {
+			cut = i
+			break
+		}
+		count++
+	}
+
+	for i := cut; i > 0; {
+		r, size := utf8.DecodeLastRuneInString(value[:i])
+		next, _ := utf8.DecodeRuneInString(value[i:])
+		if r != ' ' && next != ' ' {
+			cut = i
+			break
+		}
+		i -= size
+	}
+
+	return value[:cut], value[cut:]
+}
+
+// escapeValue удваивает "@", чтобы значение не читалось как ссылка (GEDCOM 5.5.1)
+func escapeValue(value string) string {
+	return strings.ReplaceAll(value, "@", "@@")
+}
+
+// FormatDate форматирует дату как "12 JAN 1900"
+func FormatDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), monthNames[t.Month()-1], t.Year())
+}
@@ -0,0 +1,101 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Значения тега EXIF Orientation: как повернуть снимок, чтобы он выглядел правильно
+const (
+	OrientationNormal     = 1
+	OrientationFlipH      = 2
+	OrientationRotate180  = 3
+	OrientationFlipV      = 4
+	OrientationTranspose  = 5
+	OrientationRotate90   = 6 // по часовой стрелке
+	OrientationTransverse = 7
+	OrientationRotate270  = 8
+)
+
+const exifOrientationTag = 0x0112
+
+// Orientation читает тег Orientation из EXIF JPEG-файла.
+// Для файлов без EXIF и с повреждёнными данными возвращает OrientationNormal.
+func Orientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return OrientationNormal
+	}
+
+	// Идём по сегментам до начала сжатых данных (SOS)
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return OrientationNormal
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			pos++ // заполнитель перед маркером
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			return OrientationNormal
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return OrientationNormal
+		}
+		segment := data[pos+4 : pos+2+length]
+
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+
+	return OrientationNormal
+}
+
+// tiffOrientation ищет тег Orientation в IFD0 TIFF-структуры EXIF
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return OrientationNormal
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return OrientationNormal
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return OrientationNormal
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return OrientationNormal
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+		// Тип SHORT: значение лежит в первых двух байтах поля значения
+		value := int(order.Uint16(tiff[entry+8:]))
+		if value >= OrientationNormal && value <= OrientationRotate270 {
+			return value
+		}
+		break
+	}
+
+	return OrientationNormal
+}
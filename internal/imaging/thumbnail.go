@@ -0,0 +1,173 @@
+// Package imaging готовит превью портретов без внешних зависимостей:
+// декодирует JPEG/PNG, учитывает EXIF-ориентацию и уменьшает усреднением по площади.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+// maxPixels — защита от «бомб»: крошечный файл с огромными размерами
+const maxPixels = 50_000_000
+
+// thumbnailQuality — качество JPEG для превью
+const thumbnailQuality = 85
+
+// Decode декодирует JPEG или PNG, разворачивает его по EXIF и кладёт на белый фон,
+// чтобы прозрачные PNG корректно сохранялись в JPEG
+func Decode(data []byte) (*image.RGBA, error) {
+	conf, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image config: %w", err)
+	}
+	if conf.Width <= 0 || conf.Height <= 0 {
+		return nil, errors.New("image has no pixels")
+	}
+	if conf.Width*conf.Height > maxPixels {
+		return nil, fmt.Errorf("image is too large: %dx%d", conf.Width, conf.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(img, img.Bounds(), src, bounds.Min, draw.Over)
+
+	return orient(img, Orientation(data)), nil
+}
+
+// Thumbnail вырезает центральный квадрат и приводит его к size×size
+func Thumbnail(img *image.RGBA, size int) *image.RGBA {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+	square := img.SubImage(image.Rect(x0, y0, x0+side, y0+side)).(*image.RGBA)
+
+	return resize(square, size, size)
+}
+
+// EncodeJPEG кодирует превью в JPEG
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: thumbnailQuality})
+}
+
+// orient поворачивает и отражает снимок согласно EXIF Orientation
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	// src возвращает исходную точку для точки (x, y) результата
+	var src func(x, y int) (int, int)
+	dw, dh := w, h
+	switch orientation {
+	case OrientationFlipH:
+		src = func(x, y int) (int, int) { return w - 1 - x, y }
+	case OrientationRotate180:
+		src = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case OrientationFlipV:
+		src = func(x, y int) (int, int) { return x, h - 1 - y }
+	case OrientationTranspose:
+		dw, dh = h, w
+		src = func(x, y int) (int, int) { return y, x }
+	case OrientationRotate90:
+		dw, dh = h, w
+		src = func(x, y int) (int, int) { return y, h - 1 - x }
+	case OrientationTransverse:
+		dw, dh = h, w
+		src = func(x, y int) (int, int) { return w - 1 - y, h - 1 - x }
+	case OrientationRotate270:
+		dw, dh = h, w
+		src = func(x, y int) (int, int) { return w - 1 - y, x }
+	default:
+		return img
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := src(x, y)
+			si := img.PixOffset(sx, sy)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], img.Pix[si:si+4])
+		}
+	}
+
+	return dst
+}
+
+// weight — вклад исходного пикселя index в пиксель результата
+type weight struct {
+	index int
+	value float32
+}
+
+// areaWeights делит отрезок исходных пикселей на dstLen равных частей:
+// каждый пиксель результата — среднее покрытых им исходных с учётом долей
+func areaWeights(srcLen, dstLen int) [][]weight {
+	scale := float64(srcLen) / float64(dstLen)
+	weights := make([][]weight, dstLen)
+	for i := range weights {
+		start := float64(i) * scale
+		end := start + scale
+		for j := int(start); j < srcLen && float64(j) < end; j++ {
+			overlap := min(end, float64(j+1)) - max(start, float64(j))
+			if overlap > 0 {
+				weights[i] = append(weights[i], weight{index: j, value: float32(overlap / scale)})
+			}
+		}
+	}
+	return weights
+}
+
+// resize масштабирует снимок в два прохода: по горизонтали, затем по вертикали
+func resize(img *image.RGBA, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+
+	// Промежуточный результат width×sh в float, чтобы не терять точность между проходами
+	columns := areaWeights(sw, width)
+	tmp := make([]float32, width*sh*4)
+	for y := 0; y < sh; y++ {
+		for x, ws := range columns {
+			var px [4]float32
+			for _, w := range ws {
+				si := img.PixOffset(bounds.Min.X+w.index, bounds.Min.Y+y)
+				for c := 0; c < 4; c++ {
+					px[c] += float32(img.Pix[si+c]) * w.value
+				}
+			}
+			copy(tmp[(y*width+x)*4:], px[:])
+		}
+	}
+
+	rows := areaWeights(sh, height)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y, ws := range rows {
+		for x := 0; x < width; x++ {
+			var px [4]float32
+			for _, w := range ws {
+				ti := (w.index*width + x) * 4
+				for c := 0; c < 4; c++ {
+					px[c] += tmp[ti+c] * w.value
+				}
+			}
+			di := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[di+c] = uint8(min(max(px[c]+0.5, 0), 255))
+			}
+		}
+	}
+
+	return dst
+}
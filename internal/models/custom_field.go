@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Типы пользовательских полей
+const (
+	FieldTypeString  = "string"
+	FieldTypeNumber  = "number"
+	FieldTypeBoolean = "boolean"
+	FieldTypeDate    = "date" // строка YYYY-MM-DD
+	FieldTypeEnum    = "enum" // строка из AllowedValues
+)
+
+// CustomField — пользовательское поле дерева; значения хранятся в Person.Metadata под ключом Name
+type CustomField struct {
+	ID            int       `json:"id"`
+	TreeID        int       `json:"tree_id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	AllowedValues []string  `json:"allowed_values,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
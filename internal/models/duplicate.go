@@ -0,0 +1,25 @@
+package models
+
+// Причины, по которым две персоны похожи на одного человека
+const (
+	DuplicateSameName          = "same_name"           // имя и фамилия совпали написанием
+	DuplicateSimilarName       = "similar_name"        // совпали по звучанию или транслитерации
+	DuplicateSameBirthDate     = "same_birth_date"     // точные даты рождения равны
+	DuplicateMatchingBirthDate = "matching_birth_date" // приблизительные даты могут совпасть
+	DuplicateCloseBirthDate    = "close_birth_date"    // годы рождения различаются не больше чем на 2
+	DuplicateSameDeathDate     = "same_death_date"
+	DuplicateMatchingDeathDate = "matching_death_date"
+	DuplicateCloseDeathDate    = "close_death_date"
+	DuplicateSharedParents     = "shared_parents"
+	DuplicateSharedChildren    = "shared_children"
+	DuplicateSharedPartners    = "shared_partners"
+)
+
+// DuplicatePair — две персоны дерева, похожие на одного человека.
+// Person1 — персона с меньшим ID.
+type DuplicatePair struct {
+	Person1 Person
+	Person2 Person
+	Score   int      // 1–100, выше — вероятнее дубль
+	Reasons []string // Duplicate* в порядке убывания веса
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Типы событий персоны
+const (
+	EventBirth      = "birth"
+	EventBaptism    = "baptism"
+	EventDeath      = "death"
+	EventBurial     = "burial"
+	EventResidence  = "residence"
+	EventOccupation = "occupation"
+	EventEducation  = "education"
+	EventEmigration = "emigration"
+	EventMilitary   = "military"
+	EventOther      = "other"
+)
+
+// Типы событий союза
+const (
+	EventEngagement = "engagement"
+	EventMarriage   = "marriage"
+	EventDivorce    = "divorce"
+)
+
+// Event — событие жизни персоны или союза: рождение, крещение, место жительства, брак...
+// Ровно одно из PersonID и PartnershipID задано.
+type Event struct {
+	ID            int       `json:"id"`
+	PersonID      *int      `json:"person_id,omitempty"`
+	PartnershipID *int      `json:"partnership_id,omitempty"`
+	Type          string    `json:"type"`
+	Date          *GenDate  `json:"date,omitempty"`
+	PlaceID       *int      `json:"place_id,omitempty"`
+	Place         string    `json:"place,omitempty"` // название места, только для чтения
+	Description   string    `json:"description,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// IsVital — рождение или смерть: дата синхронизирована с полями персоны
+func (e *Event) IsVital() bool {
+	return e.PersonID != nil && (e.Type == EventBirth || e.Type == EventDeath)
+}
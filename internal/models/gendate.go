@@ -0,0 +1,248 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Квалификаторы генеалогической даты
+const (
+	DateExact      = ""
+	DateAbout      = "about"
+	DateCalculated = "calculated"
+	DateEstimated  = "estimated"
+	DateBefore     = "before"
+	DateAfter      = "after"
+	DateBetween    = "between"
+)
+
+// Точность генеалогической даты
+const (
+	PrecisionDay   = "day"
+	PrecisionMonth = "month"
+	PrecisionYear  = "year"
+)
+
+// approximateYears — на сколько лет в обе стороны расширяются ABT/CAL/EST
+const approximateYears = 5
+
+var dateMonths = [...]string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+// qualifierKeywords — ключевые слова GEDCOM для квалификаторов
+var qualifierKeywords = map[string]string{
+	"ABT": DateAbout,
+	"CAL": DateCalculated,
+	"EST": DateEstimated,
+	"BEF": DateBefore,
+	"AFT": DateAfter,
+	"BET": DateBetween,
+}
+
+// GenDate — генеалогическая дата: "12 JAN 1900", "1850", "ABT 1850",
+// "BEF 1791", "BET 1820 AND 1825".
+// Start и End — первый и последний день, которые покрывает дата из фразы
+// (для "1850" это 1 января и 31 декабря 1850, для BET — начало первой и конец второй даты).
+type GenDate struct {
+	Qualifier    string
+	Precision    string
+	EndPrecision string // точность второй даты BET; пусто — как Precision
+	Start        time.Time
+	End          time.Time
+}
+
+// ExactDate — точная дата с точностью до дня
+func ExactDate(t time.Time) GenDate {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return GenDate{Precision: PrecisionDay, Start: day, End: day}
+}
+
+// ParseGenDate разбирает фразу даты GEDCOM (григорианский календарь)
+func ParseGenDate(value string) (GenDate, error) {
+	fields := strings.Fields(strings.ToUpper(value))
+	if len(fields) > 0 && (fields[0] == "@#DGREGORIAN@" || fields[0] == "GREGORIAN") {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return GenDate{}, fmt.Errorf("date %q is empty", value)
+	}
+
+	qualifier, ok := qualifierKeywords[fields[0]]
+	if ok {
+		fields = fields[1:]
+	}
+
+	if qualifier == DateBetween {
+		and := -1
+		for i, f := range fields {
+			if f == "AND" {
+				and = i
+			}
+		}
+		if and < 0 {
+			return GenDate{}, fmt.Errorf("date %q: BET without AND", value)
+		}
+
+		from, err := parseDateRange(fields[:and], value)
+		if err != nil {
+			return GenDate{}, err
+		}
+		to, err := parseDateRange(fields[and+1:], value)
+		if err != nil {
+			return GenDate{}, err
+		}
+		if to.End.Before(from.Start) {
+			return GenDate{}, fmt.Errorf("date %q: range ends before it starts", value)
+		}
+
+		return GenDate{
+			Qualifier:    DateBetween,
+			Precision:    from.Precision,
+			EndPrecision: to.Precision,
+			Start:        from.Start,
+			End:          to.End,
+		}, nil
+	}
+
+	d, err := parseDateRange(fields, value)
+	if err != nil {
+		return GenDate{}, err
+	}
+	d.Qualifier = qualifier
+	return d, nil
+}
+
+// parseDateRange разбирает "[день] [месяц] год" в диапазон дней
+func parseDateRange(fields []string, value string) (GenDate, error) {
+	if len(fields) == 0 || len(fields) > 3 {
+		return GenDate{}, fmt.Errorf("date %q is not a valid GEDCOM date", value)
+	}
+
+	year, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || year <= 0 {
+		return GenDate{}, fmt.Errorf("date %q has invalid year", value)
+	}
+
+	if len(fields) == 1 {
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return GenDate{Precision: PrecisionYear, Start: start, End: start.AddDate(1, 0, -1)}, nil
+	}
+
+	month := time.Month(0)
+	for i, name := range dateMonths {
+		if fields[len(fields)-2] == name {
+			month = time.Month(i + 1)
+		}
+	}
+	if month == 0 {
+		return GenDate{}, fmt.Errorf("date %q has unknown month", value)
+	}
+
+	if len(fields) == 2 {
+		start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		return GenDate{Precision: PrecisionMonth, Start: start, End: start.AddDate(0, 1, -1)}, nil
+	}
+
+	day, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return GenDate{}, fmt.Errorf("date %q has invalid day", value)
+	}
+
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if t.Day() != day || t.Month() != month {
+		return GenDate{}, fmt.Errorf("date %q does not exist", value)
+	}
+
+	return GenDate{Precision: PrecisionDay, Start: t, End: t}, nil
+}
+
+// String форматирует дату фразой GEDCOM
+func (d GenDate) String() string {
+	switch d.Qualifier {
+	case DateBetween:
+		endPrecision := d.EndPrecision
+		if endPrecision == "" {
+			endPrecision = d.Precision
+		}
+		return "BET " + formatDay(d.Start, d.Precision) + " AND " + formatDay(d.End, endPrecision)
+	case DateExact:
+		return formatDay(d.Start, d.Precision)
+	}
+
+	for keyword, qualifier := range qualifierKeywords {
+		if qualifier == d.Qualifier {
+			return keyword + " " + formatDay(d.Start, d.Precision)
+		}
+	}
+	return formatDay(d.Start, d.Precision)
+}
+
+func formatDay(t time.Time, precision string) string {
+	switch precision {
+	case PrecisionYear:
+		return strconv.Itoa(t.Year())
+	case PrecisionMonth:
+		return fmt.Sprintf("%s %d", dateMonths[t.Month()-1], t.Year())
+	default:
+		return fmt.Sprintf("%d %s %d", t.Day(), dateMonths[t.Month()-1], t.Year())
+	}
+}
+
+// IsExact — дата известна с точностью до дня и без квалификатора
+func (d GenDate) IsExact() bool {
+	return d.Qualifier == DateExact && d.Precision == PrecisionDay
+}
+
+// bounds возвращает самый ранний и самый поздний возможный день.
+// hasLow/hasHigh = false означает открытую границу (BEF/AFT).
+func (d GenDate) bounds() (low time.Time, hasLow bool, high time.Time, hasHigh bool) {
+	switch d.Qualifier {
+	case DateBefore:
+		return time.Time{}, false, d.Start.AddDate(0, 0, -1), true
+	case DateAfter:
+		return d.End.AddDate(0, 0, 1), true, time.Time{}, false
+	case DateAbout, DateCalculated, DateEstimated:
+		return d.Start.AddDate(-approximateYears, 0, 0), true, d.End.AddDate(approximateYears, 0, 0), true
+	default:
+		return d.Start, true, d.End, true
+	}
+}
+
+// CanBeBefore — хотя бы один возможный день d раньше хотя бы одного возможного дня o
+func (d GenDate) CanBeBefore(o GenDate) bool {
+	low, hasLow, _, _ := d.bounds()
+	_, _, high, hasHigh := o.bounds()
+	if !hasLow || !hasHigh {
+		return true
+	}
+	return low.Before(high)
+}
+
+// DefinitelyBefore — любой возможный день d раньше любого возможного дня o
+func (d GenDate) DefinitelyBefore(o GenDate) bool {
+	_, _, high, hasHigh := d.bounds()
+	low, hasLow, _, _ := o.bounds()
+	if !hasHigh || !hasLow {
+		return false
+	}
+	return high.Before(low)
+}
+
+// DefinitelyAfterTime — любой возможный день d позже t
+func (d GenDate) DefinitelyAfterTime(t time.Time) bool {
+	low, hasLow, _, _ := d.bounds()
+	return hasLow && low.After(t)
+}
+
+// CanBeAfterTime — хотя бы один возможный день d позже t
+func (d GenDate) CanBeAfterTime(t time.Time) bool {
+	_, _, high, hasHigh := d.bounds()
+	return !hasHigh || high.After(t)
+}
+
+// CanBeBeforeTime — хотя бы один возможный день d раньше t
+func (d GenDate) CanBeBeforeTime(t time.Time) bool {
+	low, hasLow, _, _ := d.bounds()
+	return !hasLow || low.Before(t)
+}
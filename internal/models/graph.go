@@ -0,0 +1,8 @@
+package models
+
+// TreeGraph — все персоны дерева и связи между ними
+type TreeGraph struct {
+	Persons       []Person
+	Relationships []Relationship
+	Partnerships  []Partnership
+}
@@ -0,0 +1,17 @@
+package models
+
+// ImportIssue — запись или связь, которая не была импортирована как есть
+type ImportIssue struct {
+	XRef    string `json:"xref,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport — результат импорта файла в дерево
+type ImportReport struct {
+	PersonsImported       int           `json:"persons_imported"`
+	RelationshipsImported int           `json:"relationships_imported"`
+	Skipped               []ImportIssue `json:"skipped"`
+	Failures              []ImportIssue `json:"failures"`
+	Warnings              []ImportIssue `json:"warnings"`
+}
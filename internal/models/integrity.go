@@ -0,0 +1,7 @@
+package models
+
+// IntegrityReport — проблемы целостности связей дерева
+type IntegrityReport struct {
+	TreeID int
+	Cycles [][]int // ID персон цикла: каждая следующая — ребенок предыдущей, первая — ребенок последней
+}
@@ -0,0 +1,13 @@
+package models
+
+// Kinship — степень родства персоны B по отношению к персоне A
+type Kinship struct {
+	PersonAID       int    `json:"person_a_id"`
+	PersonBID       int    `json:"person_b_id"`
+	Related         bool   `json:"related"`
+	Relationship    string `json:"relationship,omitempty"` // кем B приходится A: "great-aunt", "second cousin once removed"...
+	GenerationsA    int    `json:"generations_a"`          // поколений от A до общего предка
+	GenerationsB    int    `json:"generations_b"`          // поколений от B до общего предка
+	CommonAncestors []int  `json:"common_ancestors"`
+	Path            []int  `json:"path"` // ID персон от A через общего предка к B
+}
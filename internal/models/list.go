@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Сортировки списка персон
+const (
+	PersonSortName      = "name"       // фамилия, затем имя
+	PersonSortBirthDate = "birth_date" // персоны без даты рождения — в конце
+	PersonSortUpdatedAt = "updated_at"
+)
+
+// PersonSorts — допустимые сортировки списка персон
+var PersonSorts = []string{PersonSortName, PersonSortBirthDate, PersonSortUpdatedAt}
+
+// ListCursor — позиция в постраничном списке: значения ключа сортировки
+// и ID последней выданной записи. Клиенту отдаётся непрозрачной строкой.
+type ListCursor struct {
+	Sort string   `json:"s,omitempty"`
+	Desc bool     `json:"d,omitempty"`
+	Key  []string `json:"k,omitempty"`
+	ID   int      `json:"id"`
+}
+
+// ListPage — страница, которую сервис запрашивает у репозитория
+type ListPage struct {
+	Sort  string
+	Desc  bool
+	Limit int
+	After *ListCursor // nil — с начала списка
+}
+
+// PersonListQuery — параметры списка персон дерева
+type PersonListQuery struct {
+	Limit    int    // 0 — размер страницы по умолчанию
+	Cursor   string // next_cursor предыдущей страницы
+	Sort     string // одна из PersonSorts, по умолчанию name
+	Desc     bool
+	Sex      string // одно из Sexes; пусто — любой
+	Living   *bool  // живые — без даты смерти и моложе порога приватности
+	BornFrom int    // год; 0 — граница не задана
+	BornTo   int
+	Metadata map[string]string // имя пользовательского поля → значение
+}
+
+// PersonListFilter — условия выборки персон, разобранные сервисом для репозитория
+type PersonListFilter struct {
+	Sex           string
+	Living        *bool
+	LivingSince   time.Time // родившиеся позже без даты смерти считаются живыми
+	BornFrom      *time.Time
+	BornBefore    *time.Time
+	MetadataMatch map[string]any
+	MetadataKeys  []string
+	VisibleOnly   bool // только персоны, которые не скрываются правилами приватности
+	Redact        bool // список для зрителя: ключи сортировки скрытых персон без их данных
+}
+
+// PersonPage — страница списка персон
+type PersonPage struct {
+	Persons    []Person
+	Total      int    // всего персон под фильтрами
+	NextCursor string // пусто на последней странице
+}
+
+// TreeListQuery — параметры списка деревьев пользователя
+type TreeListQuery struct {
+	Limit  int
+	Cursor string
+}
+
+// TreePage — страница списка деревьев
+type TreePage struct {
+	Trees      []Tree
+	Total      int
+	NextCursor string
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Размеры квадратных превью портрета в пикселях
+const (
+	ThumbnailSmall = 64  // узлы графа
+	ThumbnailLarge = 256 // карточка персоны
+)
+
+var ThumbnailSizes = []int{ThumbnailSmall, ThumbnailLarge}
+
+// thumbnailMimeTypes — форматы, из которых строятся превью и которые годятся в портрет
+var thumbnailMimeTypes = []string{"image/jpeg", "image/png"}
+
+// Media — файл, прикреплённый к персоне: фотография или скан документа
+type Media struct {
+	ID          int       `json:"id"`
+	PersonID    int       `json:"person_id"`
+	StorageKey  string    `json:"-"`
+	ContentHash string    `json:"content_hash"` // SHA-256 в hex
+	MimeType    string    `json:"mime_type"`
+	Size        int64     `json:"size"`
+	FileName    string    `json:"file_name,omitempty"`
+	Title       string    `json:"title,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// HasThumbnails — у файла есть превью, и его можно назначить портретом
+func (m *Media) HasThumbnails() bool {
+	return IsThumbnailMimeType(m.MimeType)
+}
+
+// IsThumbnailMimeType — из файла такого типа можно построить превью
+func IsThumbnailMimeType(mimeType string) bool {
+	return slices.Contains(thumbnailMimeTypes, mimeType)
+}
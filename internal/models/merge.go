@@ -0,0 +1,33 @@
+package models
+
+// Поля, которые при слиянии персон можно взять у поглощаемой персоны.
+// Дата и место рождения (и смерти) выбираются вместе.
+const (
+	MergeFieldFirstName = "first_name"
+	MergeFieldLastName  = "last_name"
+	MergeFieldSex       = "sex"
+	MergeFieldBirth     = "birth"
+	MergeFieldDeath     = "death"
+	MergeFieldBiography = "biography"
+	MergeFieldPrivacy   = "privacy"
+	MergeFieldPortrait  = "portrait"
+	MergeFieldMetadata  = "metadata"
+)
+
+// MergeFields — все поля, доступные для выбора при слиянии
+var MergeFields = []string{
+	MergeFieldFirstName, MergeFieldLastName, MergeFieldSex, MergeFieldBirth, MergeFieldDeath,
+	MergeFieldBiography, MergeFieldPrivacy, MergeFieldPortrait, MergeFieldMetadata,
+}
+
+// Чьё значение поля остаётся после слияния
+const (
+	MergeKeepTarget = "target" // оставшейся персоны (по умолчанию)
+	MergeKeepSource = "source" // поглощаемой персоны
+)
+
+// MergeConflict — правило дерева, которое нарушило бы слияние
+type MergeConflict struct {
+	PersonID int // персона, у которой нарушается правило
+	Reason   string
+}
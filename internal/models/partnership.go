@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Partnership — союз двух персон (брак, гражданский союз, совместное родительство...)
+type Partnership struct {
+	ID        int        `json:"id"`
+	Person1ID int        `json:"person1_id"`
+	Person2ID int        `json:"person2_id"`
+	Type      string     `json:"type"`
+	Status    string     `json:"status"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// PartnerOf возвращает ID второго участника союза
+func (p *Partnership) PartnerOf(personID int) int {
+	if p.Person1ID == personID {
+		return p.Person2ID
+	}
+	return p.Person1ID
+}
+
+// Partner — партнёр персоны вместе с данными о союзе
+type Partner struct {
+	Person
+	Partnership Partnership `json:"partnership"`
+}
@@ -2,15 +2,60 @@ package models
 
 import "time"
 
+// Настройки приватности персоны
+const (
+	PrivacyAuto    = "auto"    // скрывать, пока персона считается живой
+	PrivacyPublic  = "public"  // никогда не скрывать
+	PrivacyPrivate = "private" // всегда скрывать
+)
+
+// Пол персоны
+const (
+	SexMale    = "male"
+	SexFemale  = "female"
+	SexUnknown = "unknown" // не указан в источниках
+	SexOther   = "other"   // интерсекс, небинарная персона и т.п.
+)
+
+// Sexes — допустимые значения пола
+var Sexes = []string{SexMale, SexFemale, SexUnknown, SexOther}
+
 type Person struct {
-	ID        int        `json:"id"`
-	FirstName string     `json:"first_name"`
-	LastName  string     `json:"last_name"`
-	BirthDate *time.Time `json:"birth_date,omitempty"`
-	DeathDate *time.Time `json:"death_date,omitempty"`
-	IsMale    bool       `json:"is_male"`
-	Biography string     `json:"biography,omitempty"`
-	TreeID    int        `json:"tree_id"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID           int            `json:"id"`
+	FirstName    string         `json:"first_name"`
+	LastName     string         `json:"last_name"`
+	BirthDate    *GenDate       `json:"birth_date,omitempty"`
+	DeathDate    *GenDate       `json:"death_date,omitempty"`
+	BirthPlaceID *int           `json:"birth_place_id,omitempty"`
+	DeathPlaceID *int           `json:"death_place_id,omitempty"`
+	PortraitID   *int           `json:"portrait_id,omitempty"` // основное фото из media
+	Sex          string         `json:"sex"`                   // одно из Sexes
+	Biography    string         `json:"biography,omitempty"`
+	Privacy      string         `json:"privacy"`
+	Metadata     map[string]any `json:"metadata,omitempty"` // значения пользовательских полей дерева
+	TreeID       int            `json:"tree_id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// Hidden — персона скрывается от зрителей: закрыта или, при auto, может быть живой —
+// нет даты смерти и родилась позже livingSince (или дата рождения неизвестна)
+func (p *Person) Hidden(livingSince time.Time) bool {
+	switch p.Privacy {
+	case PrivacyPublic:
+		return false
+	case PrivacyPrivate:
+		return true
+	}
+
+	if p.DeathDate != nil {
+		return false
+	}
+	// Для приблизительной даты скрываем, если персона может быть моложе порога
+	return p.BirthDate == nil || p.BirthDate.CanBeAfterTime(livingSince)
+}
+
+// IsMale — пол в старом формате для клиентов, которые ещё читают is_male
+func (p *Person) IsMale() bool {
+	return p.Sex == SexMale
 }
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Типы мест: деревня → район → область → страна
+const (
+	PlaceVillage  = "village"
+	PlaceCity     = "city"
+	PlaceDistrict = "district"
+	PlaceRegion   = "region"
+	PlaceCountry  = "country"
+	PlaceOther    = "other"
+)
+
+// Place — место в дереве. Места образуют иерархию через ParentID.
+type Place struct {
+	ID        int         `json:"id"`
+	TreeID    int         `json:"tree_id"`
+	ParentID  *int        `json:"parent_id,omitempty"`
+	Name      string      `json:"name"`
+	Type      string      `json:"type"`
+	Latitude  *float64    `json:"latitude,omitempty"`
+	Longitude *float64    `json:"longitude,omitempty"`
+	Names     []PlaceName `json:"names,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// HasCoordinates — у места заданы широта и долгота
+func (p *Place) HasCoordinates() bool {
+	return p.Latitude != nil && p.Longitude != nil
+}
+
+// PlaceName — историческое название места; годы включительно, nil — без границы
+type PlaceName struct {
+	Name     string `json:"name"`
+	FromYear *int   `json:"from_year,omitempty"`
+	ToYear   *int   `json:"to_year,omitempty"`
+}
+
+// VitalPlace — место рождения или смерти персоны с координатами для карты.
+// Координаты могут быть взяты у родительского места, если у самого места их нет.
+type VitalPlace struct {
+	Person    Person
+	EventType string // birth или death
+	Date      *GenDate
+	Place     Place
+	Latitude  float64
+	Longitude float64
+}
@@ -6,3 +6,16 @@ type Relationship struct {
 	ChildID          int    `json:"child_id"`
 	RelationshipType string `json:"relationship_type"`
 }
+
+// ParentLink — родитель персоны и тип связи с ним
+type ParentLink struct {
+	Parent           Person
+	RelationshipType string
+}
+
+// Relative — родственник с номером поколения относительно корневой персоны
+// (1 — родители или дети, 2 — бабушки/дедушки или внуки и т.д.)
+type Relative struct {
+	Person
+	Generation int `json:"generation"`
+}
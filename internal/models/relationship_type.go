@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Встроенные типы связей родитель-ребенок
+const (
+	RelationshipBiological    = "biological"
+	RelationshipNotBiological = "not_biological" // небиологическое родство без уточнения
+	RelationshipAdoptive      = "adoptive"
+	RelationshipStep          = "step"
+	RelationshipFoster        = "foster"
+	RelationshipGuardian      = "guardian"
+	RelationshipSurrogate     = "surrogate" // суррогатная мать
+	RelationshipDonor         = "donor"     // донор яйцеклетки или спермы
+)
+
+// RelationshipType — тип связи родитель-ребенок из реестра и его смысл для правил дерева
+type RelationshipType struct {
+	ID                  int       `json:"id"`
+	TreeID              *int      `json:"tree_id,omitempty"` // nil — встроенный тип
+	Code                string    `json:"code"`              // значение Relationship.RelationshipType
+	Name                string    `json:"name"`
+	CountsTowardParents bool      `json:"counts_toward_parents"` // входит в лимит двух родителей и проверку их пола
+	Blood               bool      `json:"blood"`                 // учитывается в кровном родстве
+	CreatedAt           time.Time `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// PersonSearch — параметры поиска персон по имени
+type PersonSearch struct {
+	Query    string
+	BornFrom int // год; 0 — без ограничения
+	BornTo   int
+	Limit    int
+}
+
+// PersonMatch — найденная персона и оценка совпадения от 1 до 100
+type PersonMatch struct {
+	Person
+	Score int `json:"score"`
+}
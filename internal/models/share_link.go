@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ShareLink — публичная ссылка на чтение дерева без аккаунта
+type ShareLink struct {
+	ID        int        `json:"id"`
+	TreeID    int        `json:"tree_id"`
+	TokenHash string     `json:"-"`
+	CreatedBy int        `json:"created_by"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IsActive — ссылка не отозвана и не истекла
+func (l *ShareLink) IsActive(now time.Time) bool {
+	if l.RevokedAt != nil {
+		return false
+	}
+	return l.ExpiresAt == nil || now.Before(*l.ExpiresAt)
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Факты персоны, которые может подтверждать цитата
+const (
+	FactName       = "name"
+	FactSex        = "sex"
+	FactBirthDate  = "birth_date"
+	FactBirthPlace = "birth_place"
+	FactDeathDate  = "death_date"
+	FactDeathPlace = "death_place"
+)
+
+// FactRelationship — факт связи родитель-ребенок
+const FactRelationship = "relationship"
+
+// Source — источник: документ, книга, архивное дело
+type Source struct {
+	ID         int       `json:"id"`
+	TreeID     int       `json:"tree_id"`
+	Title      string    `json:"title"`
+	Author     string    `json:"author,omitempty"`
+	Repository string    `json:"repository,omitempty"` // где хранится: архив, библиотека
+	URL        string    `json:"url,omitempty"`
+	ArchiveRef string    `json:"archive_ref,omitempty"` // фонд, опись, дело
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Citation — ссылка на источник, подтверждающая персону (или один её факт) или связь.
+// Ровно одно из PersonID и RelationshipID задано; Field — только для персоны.
+type Citation struct {
+	ID             int       `json:"id"`
+	SourceID       int       `json:"source_id"`
+	PersonID       *int      `json:"person_id,omitempty"`
+	RelationshipID *int      `json:"relationship_id,omitempty"`
+	Field          string    `json:"field,omitempty"` // пусто — персона целиком
+	Page           string    `json:"page,omitempty"`
+	Quality        *int      `json:"quality,omitempty"` // 0..3, как QUAY в GEDCOM
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Fact — утверждение дерева: факт персоны или связь родитель-ребенок
+type Fact struct {
+	Person       *Person       `json:"person,omitempty"`
+	Relationship *Relationship `json:"relationship,omitempty"`
+	Field        string        `json:"field"` // один из Fact*
+}
+
+// CitedFact — факт вместе с цитатой, которая его подтверждает
+type CitedFact struct {
+	Fact
+	Citation Citation `json:"citation"`
+}
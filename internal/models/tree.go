@@ -3,9 +3,11 @@ package models
 import "time"
 
 type Tree struct {
-	ID        int       `json:"id"`
-	OwnerID   int       `json:"owner_id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                  int       `json:"id"`
+	OwnerID             int       `json:"owner_id"`
+	Name                string    `json:"name"`
+	Role                string    `json:"role,omitempty"`         // роль текущего пользователя, если известна
+	AllowSameSexParents bool      `json:"allow_same_sex_parents"` // у ребенка могут быть два родителя одного пола
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Роли участников дерева (владелец задаётся trees.owner_id)
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// TreeMember — пользователь, приглашённый в дерево с ролью
+type TreeMember struct {
+	TreeID    int       `json:"tree_id"`
+	UserID    int       `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
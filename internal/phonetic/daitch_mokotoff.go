@@ -0,0 +1,172 @@
+package phonetic
+
+import (
+	"slices"
+	"strings"
+)
+
+// dmRule — правило Daitch–Mokotoff: код сочетания букв в начале слова,
+// перед гласной и в остальных случаях. "" — не кодируется,
+// "5|4" — два варианта произношения, каждый даёт свою ветку кода.
+type dmRule struct {
+	pattern     string
+	start       string
+	beforeVowel string
+	other       string
+}
+
+// dmRules — таблица Daitch–Mokotoff Soundex
+var dmRules = []dmRule{
+	{"ai", "0", "1", ""}, {"aj", "0", "1", ""}, {"ay", "0", "1", ""},
+	{"au", "0", "7", ""},
+	{"a", "0", "", ""},
+	{"b", "7", "7", "7"},
+	{"chs", "5", "54", "54"},
+	{"ch", "5|4", "5|4", "5|4"},
+	{"ck", "5|45", "5|45", "5|45"},
+	{"csz", "4", "4", "4"}, {"czs", "4", "4", "4"}, {"cz", "4", "4", "4"}, {"cs", "4", "4", "4"},
+	{"c", "5|4", "5|4", "5|4"},
+	{"drz", "4", "4", "4"}, {"drs", "4", "4", "4"},
+	{"dsh", "4", "4", "4"}, {"dsz", "4", "4", "4"}, {"ds", "4", "4", "4"},
+	{"dzh", "4", "4", "4"}, {"dzs", "4", "4", "4"}, {"dz", "4", "4", "4"},
+	{"dt", "3", "3", "3"}, {"d", "3", "3", "3"},
+	{"ei", "0", "1", ""}, {"ej", "0", "1", ""}, {"ey", "0", "1", ""},
+	{"eu", "1", "1", ""},
+	{"e", "0", "", ""},
+	{"fb", "7", "7", "7"}, {"f", "7", "7", "7"},
+	{"g", "5", "5", "5"},
+	{"h", "5", "5", ""},
+	{"ia", "1", "", ""}, {"ie", "1", "", ""}, {"io", "1", "", ""}, {"iu", "1", "", ""},
+	{"i", "0", "", ""},
+	{"j", "1|4", "1|4", "1|4"},
+	{"ks", "5", "54", "54"},
+	{"kh", "5", "5", "5"},
+	{"k", "5", "5", "5"},
+	{"l", "8", "8", "8"},
+	{"mn", "", "66", "66"},
+	{"m", "6", "6", "6"},
+	{"nm", "", "66", "66"},
+	{"n", "6", "6", "6"},
+	{"oi", "0", "1", ""}, {"oj", "0", "1", ""}, {"oy", "0", "1", ""},
+	{"o", "0", "", ""},
+	{"pf", "7", "7", "7"}, {"ph", "7", "7", "7"}, {"p", "7", "7", "7"},
+	{"q", "5", "5", "5"},
+	{"rz", "94|4", "94|4", "94|4"}, {"rs", "94|4", "94|4", "94|4"},
+	{"r", "9", "9", "9"},
+	{"schtsch", "2", "4", "4"}, {"schtsh", "2", "4", "4"}, {"schtch", "2", "4", "4"},
+	{"shtch", "2", "4", "4"}, {"shtsh", "2", "4", "4"}, {"shch", "2", "4", "4"},
+	{"stsch", "2", "4", "4"}, {"stch", "2", "4", "4"},
+	{"strz", "2", "4", "4"}, {"strs", "2", "4", "4"}, {"stsh", "2", "4", "4"},
+	{"szcz", "2", "4", "4"}, {"szcs", "2", "4", "4"},
+	{"scht", "2", "43", "43"}, {"schd", "2", "43", "43"},
+	{"sch", "4", "4", "4"},
+	{"sht", "2", "43", "43"}, {"szt", "2", "43", "43"}, {"shd", "2", "43", "43"}, {"szd", "2", "43", "43"},
+	{"sc", "2", "4", "4"},
+	{"sh", "4", "4", "4"},
+	{"st", "2", "43", "43"}, {"sd", "2", "43", "43"},
+	{"sz", "4", "4", "4"},
+	{"s", "4", "4", "4"},
+	{"ttsch", "4", "4", "4"}, {"ttch", "4", "4", "4"}, {"tch", "4", "4", "4"},
+	{"ttsz", "4", "4", "4"}, {"tts", "4", "4", "4"},
+	{"tsch", "4", "4", "4"}, {"tsh", "4", "4", "4"},
+	{"trz", "4", "4", "4"}, {"trs", "4", "4", "4"},
+	{"tzs", "4", "4", "4"}, {"tsz", "4", "4", "4"}, {"ttz", "4", "4", "4"}, {"tz", "4", "4", "4"},
+	{"ts", "4", "4", "4"}, {"tc", "4", "4", "4"},
+	{"th", "3", "3", "3"},
+	{"t", "3", "3", "3"},
+	{"ui", "0", "1", ""}, {"uj", "0", "1", ""}, {"uy", "0", "1", ""},
+	{"ue", "0", "", ""}, {"u", "0", "", ""},
+	{"v", "7", "7", "7"},
+	{"w", "7", "7", "7"},
+	{"x", "5", "54", "54"},
+	{"y", "1", "", ""},
+	{"zhdzh", "2", "4", "4"}, {"zdzh", "2", "4", "4"}, {"zdz", "2", "4", "4"},
+	{"zhd", "2", "43", "43"}, {"zd", "2", "43", "43"},
+	{"zsch", "4", "4", "4"}, {"zsh", "4", "4", "4"}, {"zh", "4", "4", "4"}, {"zs", "4", "4", "4"},
+	{"z", "4", "4", "4"},
+}
+
+// dmCodeLength — длина кода Daitch–Mokotoff
+const dmCodeLength = 6
+
+func init() {
+	// Ищем самое длинное совпадение: длинные сочетания проверяются первыми
+	slices.SortStableFunc(dmRules, func(a, b dmRule) int {
+		return len(b.pattern) - len(a.pattern)
+	})
+}
+
+// dmBranch — один из вариантов кода при неоднозначном произношении
+type dmBranch struct {
+	code string
+	last string // код предыдущего сочетания: одинаковые соседние коды пишутся один раз
+}
+
+// DaitchMokotoff возвращает коды Daitch–Mokotoff нормализованного слова.
+// Кодов может быть несколько: "c", "ch", "j", "rz" читаются по-разному.
+func DaitchMokotoff(word string) []string {
+	branches := []dmBranch{{}}
+	coded := false
+
+	for pos := 0; pos < len(word); {
+		rule, ok := matchDMRule(word, pos)
+		if !ok {
+			pos++ // не буква a-z
+			continue
+		}
+
+		coded = true
+		next := pos + len(rule.pattern)
+		column := rule.other
+		switch {
+		case pos == 0:
+			column = rule.start
+		case next < len(word) && strings.IndexByte("aeiou", word[next]) >= 0:
+			column = rule.beforeVowel
+		}
+
+		variants := strings.Split(column, "|")
+		forked := make([]dmBranch, 0, len(branches)*len(variants))
+		for _, b := range branches {
+			for _, code := range variants {
+				nb := b
+				if code != nb.last && len(nb.code) < dmCodeLength {
+					nb.code += code
+				}
+				nb.last = code
+				// Ветки, сошедшиеся в одно состояние, дальше не различаются
+				if !slices.Contains(forked, nb) {
+					forked = append(forked, nb)
+				}
+			}
+		}
+		branches = forked
+		pos = next
+	}
+
+	if !coded {
+		return nil
+	}
+
+	codes := make([]string, 0, len(branches))
+	for _, b := range branches {
+		code := b.code
+		if len(code) > dmCodeLength {
+			code = code[:dmCodeLength]
+		}
+		code += strings.Repeat("0", dmCodeLength-len(code))
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+func matchDMRule(word string, pos int) (dmRule, bool) {
+	for _, rule := range dmRules {
+		if strings.HasPrefix(word[pos:], rule.pattern) {
+			return rule, true
+		}
+	}
+	return dmRule{}, false
+}
@@ -0,0 +1,59 @@
+// Package phonetic сравнивает имена по звучанию: приводит кириллицу и латиницу
+// к общей записи и считает коды Soundex и Daitch–Mokotoff.
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cyrillic — транслитерация русских, украинских и белорусских букв (близко к BGN/PCGN)
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// latin — латинские буквы с диакритикой, частые в польских, чешских и немецких записях
+var latin = map[rune]string{
+	'á': "a", 'à': "a", 'â': "a", 'ä': "a", 'ą': "a", 'å': "a",
+	'ć': "c", 'č': "c", 'ç': "c", 'ď': "d",
+	'é': "e", 'è': "e", 'ê': "e", 'ë': "e", 'ę': "e", 'ě': "e",
+	'í': "i", 'ì': "i", 'î': "i", 'ï': "i",
+	'ł': "l", 'ľ': "l", 'ĺ': "l", 'ń': "n", 'ň': "n", 'ñ': "n",
+	'ó': "o", 'ò': "o", 'ô': "o", 'ö': "o", 'ő': "o", 'ø': "o",
+	'ř': "r", 'ŕ': "r", 'ś': "s", 'š': "s", 'ß': "ss", 'ť': "t",
+	'ú': "u", 'ù': "u", 'û': "u", 'ü': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// Normalize приводит имя к строчной латинице без диакритики:
+// "Иванов", "IVANOV" и "Ivanov" дают "ivanov". Прочие символы отбрасываются,
+// кроме пробелов и дефисов, которые становятся пробелами.
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if t, ok := cyrillic[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		if t, ok := latin[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		switch {
+		case 'a' <= r && r <= 'z':
+			b.WriteRune(r)
+		case r == '-' || unicode.IsSpace(r):
+			b.WriteByte(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Tokens разбивает имя на нормализованные слова: "Анна-Мария" → ["anna", "mariya"]
+func Tokens(s string) []string {
+	return strings.Fields(Normalize(s))
+}
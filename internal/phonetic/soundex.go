@@ -0,0 +1,43 @@
+package phonetic
+
+// soundexCodes — цифры American Soundex; 0 — гласные, разделяющие одинаковые коды
+var soundexCodes = [26]byte{
+	'0', '1', '2', '3', '0', '1', '2', '0', '0', '2', '2', '4', '5', // a-m
+	'5', '0', '1', '2', '6', '2', '3', '0', '1', '0', '2', '0', '2', // n-z
+}
+
+// Soundex возвращает код American Soundex нормализованного слова ("robert" → "R163").
+// Для пустого слова возвращает пустую строку.
+func Soundex(word string) string {
+	code := make([]byte, 0, 4)
+	var last byte
+	for i := 0; i < len(word) && len(code) < 4; i++ {
+		c := word[i]
+		if c < 'a' || c > 'z' {
+			continue
+		}
+		digit := soundexCodes[c-'a']
+
+		if len(code) == 0 {
+			code = append(code, c-'a'+'A')
+			last = digit
+			continue
+		}
+		// h и w не разделяют одинаковые согласные, гласные — разделяют
+		if c == 'h' || c == 'w' {
+			continue
+		}
+		if digit != '0' && digit != last {
+			code = append(code, digit)
+		}
+		last = digit
+	}
+
+	if len(code) == 0 {
+		return ""
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
@@ -0,0 +1,155 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// citationColumns — столбцы цитаты (таблица citations с псевдонимом c) в порядке citationFields
+const citationColumns = `c.id, c.source_id, c.person_id, c.relationship_id, COALESCE(c.field, ''),
+               c.page, c.quality, c.created_at, c.updated_at`
+
+// citationFields возвращает адреса полей цитаты для Scan в порядке citationColumns
+func citationFields(c *models.Citation) []any {
+	return []any{
+		&c.ID,
+		&c.SourceID,
+		&c.PersonID,
+		&c.RelationshipID,
+		&c.Field,
+		&c.Page,
+		&c.Quality,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	}
+}
+
+// CreateCitation создаёт цитату
+func (s *Storage) CreateCitation(ctx context.Context, c *models.Citation) (int, error) {
+	query := `
+        INSERT INTO citations (source_id, person_id, relationship_id, field, page, quality)
+        VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+        RETURNING id, created_at, updated_at
+    `
+
+	err := s.DB.QueryRow(ctx, query,
+		c.SourceID,
+		c.PersonID,
+		c.RelationshipID,
+		c.Field,
+		c.Page,
+		c.Quality,
+	).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+
+	if err != nil {
+		return 0, fmt.Errorf("create citation: %w", err)
+	}
+
+	return c.ID, nil
+}
+
+// GetCitationByID получает цитату по ID
+func (s *Storage) GetCitationByID(ctx context.Context, id int) (*models.Citation, error) {
+	query := `
+        SELECT ` + citationColumns + `
+        FROM citations c
+        WHERE c.id = $1
+    `
+
+	var c models.Citation
+	if err := s.DB.QueryRow(ctx, query, id).Scan(citationFields(&c)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCitationNotFound
+		}
+		return nil, fmt.Errorf("get citation by id: %w", err)
+	}
+
+	return &c, nil
+}
+
+// GetCitationsByTreeID получает все цитаты источников дерева
+func (s *Storage) GetCitationsByTreeID(ctx context.Context, treeID int) ([]models.Citation, error) {
+	query := `
+        SELECT ` + citationColumns + `
+        FROM citations c
+        INNER JOIN sources src ON src.id = c.source_id
+        WHERE src.tree_id = $1
+        ORDER BY c.id
+    `
+
+	return s.queryCitations(ctx, query, treeID)
+}
+
+// GetCitationsBySourceID получает все цитаты источника
+func (s *Storage) GetCitationsBySourceID(ctx context.Context, sourceID int) ([]models.Citation, error) {
+	query := `
+        SELECT ` + citationColumns + `
+        FROM citations c
+        WHERE c.source_id = $1
+        ORDER BY c.id
+    `
+
+	return s.queryCitations(ctx, query, sourceID)
+}
+
+func (s *Storage) queryCitations(ctx context.Context, query string, args ...any) ([]models.Citation, error) {
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get citations: %w", err)
+	}
+	defer rows.Close()
+
+	var citations []models.Citation
+	for rows.Next() {
+		var c models.Citation
+		if err := rows.Scan(citationFields(&c)...); err != nil {
+			return nil, fmt.Errorf("scan citation: %w", err)
+		}
+		citations = append(citations, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return citations, nil
+}
+
+// UpdateCitation обновляет страницу и качество цитаты
+func (s *Storage) UpdateCitation(ctx context.Context, c *models.Citation) error {
+	query := `
+        UPDATE citations
+        SET page = $1,
+            quality = $2,
+            updated_at = NOW()
+        WHERE id = $3
+        RETURNING updated_at
+    `
+
+	if err := s.DB.QueryRow(ctx, query, c.Page, c.Quality, c.ID).Scan(&c.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCitationNotFound
+		}
+		return fmt.Errorf("update citation: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCitation удаляет цитату
+func (s *Storage) DeleteCitation(ctx context.Context, id int) error {
+	commandTag, err := s.DB.Exec(ctx, `DELETE FROM citations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete citation: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrCitationNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// customFieldColumns — столбцы пользовательского поля в порядке customFieldFields
+const customFieldColumns = `id, tree_id, name, field_type, allowed_values, created_at, updated_at`
+
+// customFieldFields возвращает адреса полей для Scan в порядке customFieldColumns
+func customFieldFields(f *models.CustomField) []any {
+	return []any{
+		&f.ID,
+		&f.TreeID,
+		&f.Name,
+		&f.Type,
+		&f.AllowedValues,
+		&f.CreatedAt,
+		&f.UpdatedAt,
+	}
+}
+
+// allowedValues — допустимые значения для записи: NULL в столбце не допускается
+func allowedValues(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// CreateCustomField добавляет поле в схему дерева
+func (s *Storage) CreateCustomField(ctx context.Context, f *models.CustomField) (int, error) {
+	query := `
+        INSERT INTO custom_fields (tree_id, name, field_type, allowed_values)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at, updated_at
+    `
+
+	err := s.DB.QueryRow(ctx, query,
+		f.TreeID,
+		f.Name,
+		f.Type,
+		allowedValues(f.AllowedValues),
+	).Scan(&f.ID, &f.CreatedAt, &f.UpdatedAt)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			// 23505 - unique_violation (поле с таким именем уже есть в дереве)
+			if pgErr.Code == "23505" {
+				return 0, ErrCustomFieldAlreadyExists
+			}
+		}
+		return 0, fmt.Errorf("create custom field: %w", err)
+	}
+
+	return f.ID, nil
+}
+
+// GetCustomFieldByID получает пользовательское поле по ID
+func (s *Storage) GetCustomFieldByID(ctx context.Context, id int) (*models.CustomField, error) {
+	query := `SELECT ` + customFieldColumns + ` FROM custom_fields WHERE id = $1`
+
+	var f models.CustomField
+	if err := s.DB.QueryRow(ctx, query, id).Scan(customFieldFields(&f)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCustomFieldNotFound
+		}
+		return nil, fmt.Errorf("get custom field by id: %w", err)
+	}
+
+	return &f, nil
+}
+
+// GetCustomFieldsByTreeID получает схему пользовательских полей дерева
+func (s *Storage) GetCustomFieldsByTreeID(ctx context.Context, treeID int) ([]models.CustomField, error) {
+	query := `
+        SELECT ` + customFieldColumns + `
+        FROM custom_fields
+        WHERE tree_id = $1
+        ORDER BY name
+    `
+
+	rows, err := s.DB.Query(ctx, query, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("get custom fields by tree: %w", err)
+	}
+	defer rows.Close()
+
+	var fields []models.CustomField
+	for rows.Next() {
+		var f models.CustomField
+		if err := rows.Scan(customFieldFields(&f)...); err != nil {
+			return nil, fmt.Errorf("scan custom field: %w", err)
+		}
+		fields = append(fields, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return fields, nil
+}
+
+// UpdateCustomField меняет тип и допустимые значения поля; имя не меняется
+func (s *Storage) UpdateCustomField(ctx context.Context, f *models.CustomField) error {
+	query := `
+        UPDATE custom_fields
+        SET field_type = $2, allowed_values = $3, updated_at = NOW()
+        WHERE id = $1
+        RETURNING updated_at
+    `
+
+	err := s.DB.QueryRow(ctx, query, f.ID, f.Type, allowedValues(f.AllowedValues)).Scan(&f.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCustomFieldNotFound
+		}
+		return fmt.Errorf("update custom field: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCustomField удаляет поле из схемы и его значения у всех персон дерева
+func (s *Storage) DeleteCustomField(ctx context.Context, f *models.CustomField) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin delete custom field: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	commandTag, err := tx.Exec(ctx, `DELETE FROM custom_fields WHERE id = $1`, f.ID)
+	if err != nil {
+		return fmt.Errorf("delete custom field: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrCustomFieldNotFound
+	}
+
+	query := `
+        UPDATE persons
+        SET metadata = metadata - $2::text
+        WHERE tree_id = $1 AND metadata ? $2::text
+    `
+
+	if _, err := tx.Exec(ctx, query, f.TreeID, f.Name); err != nil {
+		return fmt.Errorf("delete custom field values: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit delete custom field: %w", err)
+	}
+
+	return nil
+}
+
+// GetMetadataValues возвращает различные значения поля name у персон дерева
+func (s *Storage) GetMetadataValues(ctx context.Context, treeID int, name string) ([]any, error) {
+	query := `
+        SELECT DISTINCT p.metadata -> $2::text
+        FROM persons p
+        WHERE p.tree_id = $1 AND p.metadata ? $2::text
+    `
+
+	rows, err := s.DB.Query(ctx, query, treeID, name)
+	if err != nil {
+		return nil, fmt.Errorf("get metadata values: %w", err)
+	}
+	defer rows.Close()
+
+	var values []any
+	for rows.Next() {
+		var value any
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("scan metadata value: %w", err)
+		}
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return values, nil
+}
@@ -6,11 +6,23 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Querier — общее у пула соединений и транзакции, поэтому методы Storage работают
+// внутри WithTx без изменений. Begin в транзакции открывает точку сохранения.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 type Storage struct {
-	DB *pgxpool.Pool
+	DB   Querier       // пул соединений, а внутри WithTx — транзакция
+	pool *pgxpool.Pool // nil у Storage транзакции
 }
 
 func NewDB(ctx context.Context, cfg *config.Config) (*Storage, error) {
@@ -29,12 +41,33 @@ func NewDB(ctx context.Context, cfg *config.Config) (*Storage, error) {
 
 	slog.Debug("connected to postgres")
 
-	return &Storage{DB: pool}, nil
+	return &Storage{DB: pool, pool: pool}, nil
+}
+
+// WithTx выполняет fn в одной транзакции: все вызовы методов tx идут в неё.
+// Ошибка fn откатывает транзакцию, иначе она фиксируется.
+// Внутри уже открытой транзакции WithTx работает через точку сохранения.
+func (s *Storage) WithTx(ctx context.Context, fn func(tx *Storage) error) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(&Storage{DB: tx}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
 }
 
 func (s *Storage) Close() {
-	if s.DB != nil {
-		s.DB.Close()
+	if s.pool != nil {
+		s.pool.Close()
 		slog.Debug("database connection pool closed") // Правильное сообщение
 	} else {
 		slog.Debug("database pool was already nil")
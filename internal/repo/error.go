@@ -4,9 +4,34 @@ import "errors"
 
 // Sentinel errors для репозитория
 var (
-	ErrTreeNotFound         = errors.New("tree not found")
-	ErrPersonNotFound       = errors.New("person not found")
-	ErrRelationshipNotFound = errors.New("relationship not found")
-	ErrUserNotFound         = errors.New("user not found")
-	ErrEmailAlreadyExists   = errors.New("email already exists")
+	ErrTreeNotFound             = errors.New("tree not found")
+	ErrPersonNotFound           = errors.New("person not found")
+	ErrRelationshipNotFound     = errors.New("relationship not found")
+	ErrUserNotFound             = errors.New("user not found")
+	ErrEmailAlreadyExists       = errors.New("email already exists")
+	ErrRelationshipCycle        = errors.New("relationship would create a cycle")
+	ErrRelationshipExists       = errors.New("relationship already exists")
+	ErrSelfParent               = errors.New("person cannot be their own parent")
+	ErrTooManyParents           = errors.New("child already has 2 parents")
+	ErrSameSexParents           = errors.New("same-sex parents are not allowed in this tree")
+	ErrCrossTreeRelationship    = errors.New("parent and child must be in the same tree")
+	ErrPartnershipNotFound      = errors.New("partnership not found")
+	ErrMemberNotFound           = errors.New("tree member not found")
+	ErrMemberAlreadyExists      = errors.New("user is already a member of the tree")
+	ErrShareLinkNotFound        = errors.New("share link not found")
+	ErrEventNotFound            = errors.New("event not found")
+	ErrEventAlreadyExists       = errors.New("person already has an event of this type")
+	ErrPlaceNotFound            = errors.New("place not found")
+	ErrPlaceAlreadyExists       = errors.New("place with this name already exists")
+	ErrSourceNotFound           = errors.New("source not found")
+	ErrCitationNotFound         = errors.New("citation not found")
+	ErrMediaNotFound            = errors.New("media not found")
+	ErrMediaAlreadyExists       = errors.New("this file is already attached to the person")
+	ErrCustomFieldNotFound      = errors.New("custom field not found")
+	ErrCustomFieldAlreadyExists = errors.New("custom field with this name already exists")
+	ErrRelationshipTypeNotFound = errors.New("relationship type not found")
+	ErrRelationshipTypeExists   = errors.New("relationship type with this code already exists")
+	ErrRelationshipTypeInUse    = errors.New("relationship type is used by relationships")
+	ErrUnknownRelationshipType  = errors.New("unknown relationship type")
+	ErrInvalidCursor            = errors.New("invalid cursor")
 )
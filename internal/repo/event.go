@@ -0,0 +1,285 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// eventColumns — столбцы события (events e, places pl) в порядке eventFields
+const eventColumns = `e.id, e.person_id, e.partnership_id, e.event_type,
+               e.date, e.date_end, e.date_qualifier, e.date_precision, e.date_end_precision,
+               e.place_id, COALESCE(pl.name, ''), e.description, e.created_at, e.updated_at`
+
+// eventFields возвращает адреса полей события для Scan в порядке eventColumns
+func eventFields(e *models.Event) []any {
+	fields := []any{&e.ID, &e.PersonID, &e.PartnershipID, &e.Type}
+	fields = append(fields, genDateFields(&e.Date)...)
+	return append(fields,
+		&e.PlaceID,
+		&e.Place,
+		&e.Description,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+}
+
+// vitalEventQuery создаёт или обновляет дату и место события рождения/смерти персоны
+const vitalEventQuery = `
+        INSERT INTO events (person_id, event_type, date, date_end, date_qualifier, date_precision,
+                            date_end_precision, place_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        ON CONFLICT (person_id, event_type) WHERE event_type IN ('birth', 'death')
+        DO UPDATE SET date = EXCLUDED.date,
+                      date_end = EXCLUDED.date_end,
+                      date_qualifier = EXCLUDED.date_qualifier,
+                      date_precision = EXCLUDED.date_precision,
+                      date_end_precision = EXCLUDED.date_end_precision,
+                      place_id = EXCLUDED.place_id,
+                      updated_at = NOW()
+    `
+
+// clearVitalEventQuery стирает дату и место события рождения/смерти; описание остаётся
+const clearVitalEventQuery = `
+        UPDATE events
+        SET date = NULL, date_end = NULL, date_qualifier = NULL, date_precision = NULL, date_end_precision = NULL,
+            place_id = NULL, updated_at = NOW()
+        WHERE person_id = $1 AND event_type = $2
+    `
+
+// vitalEventArgs возвращает аргументы vitalEventQuery
+func vitalEventArgs(personID int, eventType string, date *models.GenDate, placeID *int) []any {
+	args := append([]any{personID, eventType}, genDateValues(date)...)
+	return append(args, placeID)
+}
+
+// syncVitalEvents переносит даты и места рождения и смерти персоны в её события
+func syncVitalEvents(ctx context.Context, tx pgx.Tx, p *models.Person) error {
+	for _, vital := range []struct {
+		eventType string
+		date      *models.GenDate
+		placeID   *int
+	}{
+		{models.EventBirth, p.BirthDate, p.BirthPlaceID},
+		{models.EventDeath, p.DeathDate, p.DeathPlaceID},
+	} {
+		var err error
+		if vital.date != nil || vital.placeID != nil {
+			_, err = tx.Exec(ctx, vitalEventQuery, vitalEventArgs(p.ID, vital.eventType, vital.date, vital.placeID)...)
+		} else {
+			_, err = tx.Exec(ctx, clearVitalEventQuery, p.ID, vital.eventType)
+		}
+		if err != nil {
+			return fmt.Errorf("sync %s event: %w", vital.eventType, err)
+		}
+	}
+
+	return nil
+}
+
+// syncPersonVital переносит дату и место события рождения/смерти в персону.
+// nil стирает соответствующее поле персоны.
+func syncPersonVital(ctx context.Context, tx pgx.Tx, personID int, eventType string, date *models.GenDate, placeID *int) error {
+	prefix := "birth"
+	if eventType == models.EventDeath {
+		prefix = "death"
+	}
+
+	query := `
+        UPDATE persons
+        SET ` + prefix + `_date = $2,
+            ` + prefix + `_date_end = $3,
+            ` + prefix + `_date_qualifier = $4,
+            ` + prefix + `_date_precision = $5,
+            ` + prefix + `_date_end_precision = $6,
+            ` + prefix + `_place_id = $7,
+            updated_at = NOW()
+        WHERE id = $1
+    `
+
+	args := append([]any{personID}, genDateValues(date)...)
+	args = append(args, placeID)
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("sync person %s: %w", prefix, err)
+	}
+
+	return nil
+}
+
+// CreateEvent создаёт событие. Дата и место рождения и смерти сразу переносятся в персону.
+func (s *Storage) CreateEvent(ctx context.Context, e *models.Event) (int, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin create event: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+        INSERT INTO events (person_id, partnership_id, event_type,
+                            date, date_end, date_qualifier, date_precision, date_end_precision,
+                            place_id, description)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING id, created_at, updated_at
+    `
+
+	args := []any{e.PersonID, e.PartnershipID, e.Type}
+	args = append(args, genDateValues(e.Date)...)
+	args = append(args, e.PlaceID, e.Description)
+
+	if err := tx.QueryRow(ctx, query, args...).Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			// 23505 - unique_violation (у персоны уже есть рождение/смерть)
+			if pgErr.Code == "23505" {
+				return 0, ErrEventAlreadyExists
+			}
+		}
+		return 0, fmt.Errorf("create event: %w", err)
+	}
+
+	if e.IsVital() {
+		if err := syncPersonVital(ctx, tx, *e.PersonID, e.Type, e.Date, e.PlaceID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit create event: %w", err)
+	}
+
+	return e.ID, nil
+}
+
+// GetEventByID получает событие по ID
+func (s *Storage) GetEventByID(ctx context.Context, id int) (*models.Event, error) {
+	query := `
+        SELECT ` + eventColumns + `
+        FROM events e
+        LEFT JOIN places pl ON pl.id = e.place_id
+        WHERE e.id = $1
+    `
+
+	var event models.Event
+	if err := s.DB.QueryRow(ctx, query, id).Scan(eventFields(&event)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEventNotFound
+		}
+		return nil, fmt.Errorf("get event by id: %w", err)
+	}
+
+	return &event, nil
+}
+
+// GetEventsByPersonID получает события персоны и её союзов в хронологическом порядке
+func (s *Storage) GetEventsByPersonID(ctx context.Context, personID int) ([]models.Event, error) {
+	query := `
+        SELECT ` + eventColumns + `
+        FROM events e
+        LEFT JOIN places pl ON pl.id = e.place_id
+        WHERE e.person_id = $1
+           OR e.partnership_id IN (
+               SELECT id FROM partnerships WHERE person1_id = $1 OR person2_id = $1
+           )
+        ORDER BY e.date ASC NULLS LAST, e.id
+    `
+
+	rows, err := s.DB.Query(ctx, query, personID)
+	if err != nil {
+		return nil, fmt.Errorf("get events by person: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.Event
+	for rows.Next() {
+		var event models.Event
+		if err := rows.Scan(eventFields(&event)...); err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return events, nil
+}
+
+// UpdateEvent обновляет дату, место и описание события (тип и владелец не меняются)
+func (s *Storage) UpdateEvent(ctx context.Context, e *models.Event) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin update event: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+        UPDATE events
+        SET date = $1,
+            date_end = $2,
+            date_qualifier = $3,
+            date_precision = $4,
+            date_end_precision = $5,
+            place_id = $6,
+            description = $7,
+            updated_at = NOW()
+        WHERE id = $8
+        RETURNING updated_at
+    `
+
+	args := genDateValues(e.Date)                       // $1-$5
+	args = append(args, e.PlaceID, e.Description, e.ID) // $6-$8
+
+	if err := tx.QueryRow(ctx, query, args...).Scan(&e.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrEventNotFound
+		}
+		return fmt.Errorf("update event: %w", err)
+	}
+
+	if e.IsVital() {
+		if err := syncPersonVital(ctx, tx, *e.PersonID, e.Type, e.Date, e.PlaceID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit update event: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteEvent удаляет событие. Удаление рождения или смерти стирает дату и место персоны.
+func (s *Storage) DeleteEvent(ctx context.Context, e *models.Event) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin delete event: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	commandTag, err := tx.Exec(ctx, `DELETE FROM events WHERE id = $1`, e.ID)
+	if err != nil {
+		return fmt.Errorf("delete event: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrEventNotFound
+	}
+
+	if e.IsVital() {
+		if err := syncPersonVital(ctx, tx, *e.PersonID, e.Type, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit delete event: %w", err)
+	}
+
+	return nil
+}
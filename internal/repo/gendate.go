@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Генеалогическая дата хранится пятью столбцами: <name>, <name>_end, <name>_qualifier,
+// <name>_precision, <name>_end_precision. Если даты нет, все пять равны NULL;
+// <name>_end_precision задан только у BET.
+
+// genDateFields возвращает цели Scan для столбцов даты в этом порядке.
+// Дата создаётся при первом не-NULL столбце, поэтому порядок сканирования не важен.
+func genDateFields(d **models.GenDate) []any {
+	return []any{
+		genDateBound{date: d},
+		genDateBound{date: d, end: true},
+		genDateLabel{date: d, label: labelQualifier},
+		genDateLabel{date: d, label: labelPrecision},
+		genDateLabel{date: d, label: labelEndPrecision},
+	}
+}
+
+// genDateValues возвращает значения столбцов даты для INSERT/UPDATE
+func genDateValues(d *models.GenDate) []any {
+	if d == nil {
+		return []any{nil, nil, nil, nil, nil}
+	}
+	var endPrecision *string
+	if d.Qualifier == models.DateBetween {
+		endPrecision = &d.EndPrecision
+		if d.EndPrecision == "" {
+			endPrecision = &d.Precision
+		}
+	}
+	return []any{d.Start, d.End, d.Qualifier, d.Precision, endPrecision}
+}
+
+func ensureGenDate(d **models.GenDate) *models.GenDate {
+	if *d == nil {
+		*d = &models.GenDate{}
+	}
+	return *d
+}
+
+// genDateBound сканирует начало или конец диапазона даты
+type genDateBound struct {
+	date **models.GenDate
+	end  bool
+}
+
+func (b genDateBound) ScanDate(v pgtype.Date) error {
+	if !v.Valid {
+		return nil
+	}
+	d := ensureGenDate(b.date)
+	if b.end {
+		d.End = v.Time
+	} else {
+		d.Start = v.Time
+	}
+	return nil
+}
+
+// Текстовые столбцы даты
+const (
+	labelQualifier = iota
+	labelPrecision
+	labelEndPrecision
+)
+
+// genDateLabel сканирует квалификатор или точность даты
+type genDateLabel struct {
+	date  **models.GenDate
+	label int
+}
+
+func (l genDateLabel) ScanText(v pgtype.Text) error {
+	if !v.Valid {
+		return nil
+	}
+	d := ensureGenDate(l.date)
+	switch l.label {
+	case labelQualifier:
+		d.Qualifier = v.String
+	case labelPrecision:
+		d.Precision = v.String
+	case labelEndPrecision:
+		d.EndPrecision = v.String
+	}
+	return nil
+}
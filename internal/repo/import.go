@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ImportLink — связь родитель-ребенок между ещё не сохранёнными персонами
+type ImportLink struct {
+	Parent           *models.Person
+	Child            *models.Person
+	RelationshipType string
+}
+
+// ImportPersons сохраняет персоны и связи одной транзакцией.
+// ID и даты создания проставляются в переданные персоны.
+func (s *Storage) ImportPersons(ctx context.Context, persons []*models.Person, links []ImportLink) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin import: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, p := range persons {
+		batch.Queue(personInsertQuery, personInsertArgs(p)...).QueryRow(func(row pgx.Row) error {
+			return row.Scan(&p.ID, &p.Privacy, &p.CreatedAt, &p.UpdatedAt)
+		})
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("import persons: %w", err)
+	}
+
+	// События рождения и смерти для импортированных дат
+	batch = &pgx.Batch{}
+	for _, p := range persons {
+		if p.BirthDate != nil || p.BirthPlaceID != nil {
+			batch.Queue(vitalEventQuery, vitalEventArgs(p.ID, models.EventBirth, p.BirthDate, p.BirthPlaceID)...)
+		}
+		if p.DeathDate != nil || p.DeathPlaceID != nil {
+			batch.Queue(vitalEventQuery, vitalEventArgs(p.ID, models.EventDeath, p.DeathDate, p.DeathPlaceID)...)
+		}
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("import vital events: %w", err)
+	}
+
+	relationshipsQuery := `
+        INSERT INTO relationships (parent_id, child_id, relationship_type)
+        VALUES ($1, $2, $3)
+    `
+
+	batch = &pgx.Batch{}
+	for _, link := range links {
+		batch.Queue(relationshipsQuery,
+			link.Parent.ID,
+			link.Child.ID,
+			link.RelationshipType,
+		)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		if constraintErr := relationshipConstraintError(err); constraintErr != nil {
+			err = constraintErr
+		}
+		return fmt.Errorf("import relationships: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit import: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// cursorTimeLayout — формат TIMESTAMP в ключе курсора, с микросекундами как в Postgres
+const cursorTimeLayout = "2006-01-02T15:04:05.999999"
+
+// listSort — ключ сортировки постраничного списка. После него всегда сортируется ID,
+// поэтому порядок однозначен и курсор указывает ровно на одну запись.
+type listSort[T any] struct {
+	columns []string          // выражения ключа без ID
+	types   []string          // SQL-типы значений ключа в курсоре
+	key     func(*T) []string // значения ключа записи в виде текста
+}
+
+// keyColumns возвращает выражения ключа вместе с idColumn
+func (s listSort[T]) keyColumns(idColumn string) []string {
+	return slices.Concat(s.columns, []string{idColumn})
+}
+
+// orderBy возвращает ORDER BY по ключу и idColumn
+func (s listSort[T]) orderBy(idColumn string, desc bool) string {
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+
+	columns := s.keyColumns(idColumn)
+	for i := range columns {
+		columns[i] += " " + dir
+	}
+	return "ORDER BY " + strings.Join(columns, ", ")
+}
+
+// after возвращает условие «строго после курсора» с параметрами начиная с $n и их значения.
+// Без курсора условие всегда истинно.
+func (s listSort[T]) after(idColumn string, page models.ListPage, n int) (string, []any, error) {
+	c := page.After
+	if c == nil {
+		return "TRUE", nil, nil
+	}
+	if c.Sort != page.Sort || c.Desc != page.Desc || len(c.Key) != len(s.types) {
+		return "", nil, ErrInvalidCursor
+	}
+
+	params := make([]string, 0, len(s.types)+1)
+	args := make([]any, 0, len(s.types)+1)
+	for i, typ := range s.types {
+		// Значение приходит текстом и приводится на стороне Postgres
+		param := fmt.Sprintf("$%d::text", n+i)
+		if typ != "text" {
+			param += "::" + typ
+		}
+		params = append(params, param)
+		args = append(args, c.Key[i])
+	}
+	params = append(params, fmt.Sprintf("$%d::integer", n+len(s.types)))
+	args = append(args, c.ID)
+
+	op := ">"
+	if page.Desc {
+		op = "<"
+	}
+	return "(" + strings.Join(s.keyColumns(idColumn), ", ") + ") " + op + " (" + strings.Join(params, ", ") + ")", args, nil
+}
+
+// cursor возвращает курсор, указывающий на запись item с идентификатором id
+func (s listSort[T]) cursor(page models.ListPage, item *T, id int) *models.ListCursor {
+	return &models.ListCursor{
+		Sort: page.Sort,
+		Desc: page.Desc,
+		Key:  s.key(item),
+		ID:   id,
+	}
+}
@@ -0,0 +1,152 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// mediaColumns — столбцы файла в порядке mediaFields
+const mediaColumns = `id, person_id, storage_key, content_hash, mime_type, size_bytes, file_name, title, created_at`
+
+// mediaFields возвращает адреса полей файла для Scan в порядке mediaColumns
+func mediaFields(m *models.Media) []any {
+	return []any{
+		&m.ID,
+		&m.PersonID,
+		&m.StorageKey,
+		&m.ContentHash,
+		&m.MimeType,
+		&m.Size,
+		&m.FileName,
+		&m.Title,
+		&m.CreatedAt,
+	}
+}
+
+// CreateMedia сохраняет метаданные загруженного файла
+func (s *Storage) CreateMedia(ctx context.Context, m *models.Media) (int, error) {
+	query := `
+        INSERT INTO media (person_id, storage_key, content_hash, mime_type, size_bytes, file_name, title)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, created_at
+    `
+
+	err := s.DB.QueryRow(ctx, query,
+		m.PersonID,
+		m.StorageKey,
+		m.ContentHash,
+		m.MimeType,
+		m.Size,
+		m.FileName,
+		m.Title,
+	).Scan(&m.ID, &m.CreatedAt)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			// 23505 - unique_violation (этот файл уже загружен к персоне)
+			if pgErr.Code == "23505" {
+				return 0, ErrMediaAlreadyExists
+			}
+		}
+		return 0, fmt.Errorf("create media: %w", err)
+	}
+
+	return m.ID, nil
+}
+
+// GetMediaByID получает файл по ID
+func (s *Storage) GetMediaByID(ctx context.Context, id int) (*models.Media, error) {
+	query := `SELECT ` + mediaColumns + ` FROM media WHERE id = $1`
+
+	var m models.Media
+	if err := s.DB.QueryRow(ctx, query, id).Scan(mediaFields(&m)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, fmt.Errorf("get media by id: %w", err)
+	}
+
+	return &m, nil
+}
+
+// GetMediaByHash получает файл персоны по хэшу содержимого
+func (s *Storage) GetMediaByHash(ctx context.Context, personID int, contentHash string) (*models.Media, error) {
+	query := `SELECT ` + mediaColumns + ` FROM media WHERE person_id = $1 AND content_hash = $2`
+
+	var m models.Media
+	if err := s.DB.QueryRow(ctx, query, personID, contentHash).Scan(mediaFields(&m)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, fmt.Errorf("get media by hash: %w", err)
+	}
+
+	return &m, nil
+}
+
+// GetMediaByPersonID получает файлы персоны, новые первыми
+func (s *Storage) GetMediaByPersonID(ctx context.Context, personID int) ([]models.Media, error) {
+	query := `
+        SELECT ` + mediaColumns + `
+        FROM media
+        WHERE person_id = $1
+        ORDER BY created_at DESC, id DESC
+    `
+
+	rows, err := s.DB.Query(ctx, query, personID)
+	if err != nil {
+		return nil, fmt.Errorf("get media by person: %w", err)
+	}
+	defer rows.Close()
+
+	var media []models.Media
+	for rows.Next() {
+		var m models.Media
+		if err := rows.Scan(mediaFields(&m)...); err != nil {
+			return nil, fmt.Errorf("scan media: %w", err)
+		}
+		media = append(media, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return media, nil
+}
+
+// DeleteMedia удаляет метаданные файла; портрет персоны сбрасывается внешним ключом
+func (s *Storage) DeleteMedia(ctx context.Context, id int) error {
+	commandTag, err := s.DB.Exec(ctx, `DELETE FROM media WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete media: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrMediaNotFound
+	}
+
+	return nil
+}
+
+// SetPersonPortrait назначает основной портрет персоны; nil убирает портрет
+func (s *Storage) SetPersonPortrait(ctx context.Context, personID int, mediaID *int) error {
+	query := `UPDATE persons SET portrait_id = $2, updated_at = NOW() WHERE id = $1`
+
+	commandTag, err := s.DB.Exec(ctx, query, personID, mediaID)
+	if err != nil {
+		return fmt.Errorf("set person portrait: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrPersonNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MergePersons сливает персону sourceID в merged одной транзакцией: связи, союзы, события,
+// цитаты и файлы sourceID переходят к merged.ID, поля merged сохраняются, sourceID удаляется.
+// Правила дерева (два родителя, пол, циклы) проверяет сервис до вызова; триггеры БД
+// лишь страхуют и возвращают ErrTooManyParents, ErrSameSexParents и т.п.
+// Возвращает файлы sourceID, которые удалены вместе с ней (копии файлов merged):
+// их объекты в хранилище удаляет вызывающий после коммита.
+func (s *Storage) MergePersons(ctx context.Context, merged *models.Person, sourceID int) ([]models.Media, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin merge persons: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	targetID := merged.ID
+
+	// Сначала удаляются связи, которые у merged уже есть, затем меняется пол merged
+	// и только после этого переносятся остальные связи: триггеры relationships
+	// проверяют каждую строку сразу и сравнивают пол родителей с уже новым полом merged
+	if err := mergeSteps(ctx, tx, targetID, sourceID, []mergeStep{
+		// Связь с тем же родителем или ребенком уже есть у merged: цитаты переходят
+		// на неё, а копия удаляется
+		{"move duplicate relationship citations", `
+            UPDATE citations c
+            SET relationship_id = t.id, updated_at = NOW()
+            FROM relationships s
+            INNER JOIN relationships t
+                ON (s.child_id = $2 AND t.child_id = $1 AND t.parent_id = s.parent_id)
+                OR (s.parent_id = $2 AND t.parent_id = $1 AND t.child_id = s.child_id)
+            WHERE c.relationship_id = s.id
+        `},
+		{"delete duplicate relationships", `
+            DELETE FROM relationships s
+            USING relationships t
+            WHERE (s.child_id = $2 AND t.child_id = $1 AND t.parent_id = s.parent_id)
+               OR (s.parent_id = $2 AND t.parent_id = $1 AND t.child_id = s.child_id)
+        `},
+	}); err != nil {
+		return nil, err
+	}
+
+	commandTag, err := tx.Exec(ctx, personUpdateQuery, personUpdateArgs(merged)...)
+	if err != nil {
+		if constraintErr := relationshipConstraintError(err); constraintErr != nil {
+			return nil, constraintErr
+		}
+		return nil, fmt.Errorf("merge persons: update person: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return nil, ErrPersonNotFound
+	}
+
+	if err := mergeSteps(ctx, tx, targetID, sourceID, []mergeStep{
+		{"move parents", `UPDATE relationships SET child_id = $1 WHERE child_id = $2`},
+		{"move children", `UPDATE relationships SET parent_id = $1 WHERE parent_id = $2`},
+		// Такой же союз с тем же партнёром уже есть у merged: события переходят к нему
+		{"move duplicate partnership events", `
+            UPDATE events e
+            SET partnership_id = t.id, updated_at = NOW()
+            FROM partnerships s
+            INNER JOIN partnerships t
+                ON t.person1_id = LEAST($1, CASE WHEN s.person1_id = $2 THEN s.person2_id ELSE s.person1_id END)
+               AND t.person2_id = GREATEST($1, CASE WHEN s.person1_id = $2 THEN s.person2_id ELSE s.person1_id END)
+               AND t.partnership_type = s.partnership_type
+            WHERE $2 IN (s.person1_id, s.person2_id)
+              AND e.partnership_id = s.id
+        `},
+		{"delete duplicate partnerships", `
+            DELETE FROM partnerships s
+            USING partnerships t
+            WHERE $2 IN (s.person1_id, s.person2_id)
+              AND t.person1_id = LEAST($1, CASE WHEN s.person1_id = $2 THEN s.person2_id ELSE s.person1_id END)
+              AND t.person2_id = GREATEST($1, CASE WHEN s.person1_id = $2 THEN s.person2_id ELSE s.person1_id END)
+              AND t.partnership_type = s.partnership_type
+        `},
+		// Пара хранится упорядоченно, поэтому участники пересчитываются через LEAST/GREATEST
+		{"move partnerships", `
+            UPDATE partnerships
+            SET person1_id = LEAST($1, CASE WHEN person1_id = $2 THEN person2_id ELSE person1_id END),
+                person2_id = GREATEST($1, CASE WHEN person1_id = $2 THEN person2_id ELSE person1_id END),
+                updated_at = NOW()
+            WHERE $2 IN (person1_id, person2_id)
+        `},
+		// Рождение и смерть не переносятся: их даты уже выбраны в merged
+		{"move events", `
+            UPDATE events
+            SET person_id = $1, updated_at = NOW()
+            WHERE person_id = $2 AND event_type NOT IN ('birth', 'death')
+        `},
+		{"move citations", `UPDATE citations SET person_id = $1, updated_at = NOW() WHERE person_id = $2`},
+		// Файл, который уже есть у merged, остаётся у sourceID и удаляется вместе с ней
+		{"move media", `
+            UPDATE media
+            SET person_id = $1
+            WHERE person_id = $2
+              AND content_hash NOT IN (SELECT content_hash FROM media WHERE person_id = $1)
+        `},
+	}); err != nil {
+		return nil, err
+	}
+
+	// Портрет мог остаться у sourceID как копия файла merged — берём файл merged
+	if merged.PortraitID != nil {
+		var portraitID int
+		err := tx.QueryRow(ctx, `
+            SELECT t.id
+            FROM media s
+            INNER JOIN media t ON t.content_hash = s.content_hash AND t.person_id = $1
+            WHERE s.id = $2 AND s.person_id = $3
+        `, targetID, *merged.PortraitID, sourceID).Scan(&portraitID)
+		if err == nil {
+			merged.PortraitID = &portraitID
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("merge persons: find portrait: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE persons SET portrait_id = $2 WHERE id = $1`, targetID, merged.PortraitID); err != nil {
+		return nil, fmt.Errorf("merge persons: set portrait: %w", err)
+	}
+
+	if err := syncVitalEvents(ctx, tx, merged); err != nil {
+		return nil, err
+	}
+
+	// Оставшиеся файлы sourceID удалятся каскадно вместе с ней
+	removed, err := (&Storage{DB: tx}).GetMediaByPersonID(ctx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("merge persons: %w", err)
+	}
+
+	commandTag, err = tx.Exec(ctx, `DELETE FROM persons WHERE id = $1`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("merge persons: delete source: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return nil, ErrPersonNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit merge persons: %w", err)
+	}
+
+	return removed, nil
+}
+
+// mergeStep — один запрос слияния с параметрами $1 — merged.ID, $2 — sourceID
+type mergeStep struct {
+	name  string
+	query string
+}
+
+// mergeSteps выполняет шаги слияния по порядку
+func mergeSteps(ctx context.Context, tx pgx.Tx, targetID, sourceID int, steps []mergeStep) error {
+	for _, step := range steps {
+		if _, err := tx.Exec(ctx, step.query, targetID, sourceID); err != nil {
+			if constraintErr := relationshipConstraintError(err); constraintErr != nil {
+				return constraintErr
+			}
+			return fmt.Errorf("merge persons: %s: %w", step.name, err)
+		}
+	}
+	return nil
+}
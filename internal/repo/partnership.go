@@ -0,0 +1,159 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreatePartnership создаёт союз двух персон
+func (s *Storage) CreatePartnership(ctx context.Context, p *models.Partnership) (int, error) {
+	query := `
+        INSERT INTO partnerships (person1_id, person2_id, partnership_type, status, start_date, end_date)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at, updated_at
+    `
+
+	err := s.DB.QueryRow(ctx, query,
+		p.Person1ID,
+		p.Person2ID,
+		p.Type,
+		p.Status,
+		p.StartDate,
+		p.EndDate,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+
+	if err != nil {
+		return 0, fmt.Errorf("create partnership: %w", err)
+	}
+
+	return p.ID, nil
+}
+
+// GetPartnershipByID получает союз по ID
+func (s *Storage) GetPartnershipByID(ctx context.Context, id int) (*models.Partnership, error) {
+	query := `
+        SELECT id, person1_id, person2_id, partnership_type, status,
+               start_date, end_date, created_at, updated_at
+        FROM partnerships
+        WHERE id = $1
+    `
+
+	var p models.Partnership
+	err := s.DB.QueryRow(ctx, query, id).Scan(
+		&p.ID,
+		&p.Person1ID,
+		&p.Person2ID,
+		&p.Type,
+		&p.Status,
+		&p.StartDate,
+		&p.EndDate,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPartnershipNotFound
+		}
+		return nil, fmt.Errorf("get partnership by id: %w", err)
+	}
+
+	return &p, nil
+}
+
+// GetPartnersByPersonID получает всех партнёров персоны вместе с данными союзов
+func (s *Storage) GetPartnersByPersonID(ctx context.Context, personID int) ([]models.Partner, error) {
+	query := `
+        SELECT ` + personColumns + `,
+               pr.id, pr.person1_id, pr.person2_id, pr.partnership_type, pr.status,
+               pr.start_date, pr.end_date, pr.created_at, pr.updated_at
+        FROM partnerships pr
+        INNER JOIN persons p
+            ON p.id = CASE WHEN pr.person1_id = $1 THEN pr.person2_id ELSE pr.person1_id END
+        WHERE pr.person1_id = $1 OR pr.person2_id = $1
+        ORDER BY pr.start_date ASC NULLS LAST, pr.id
+    `
+
+	rows, err := s.DB.Query(ctx, query, personID)
+	if err != nil {
+		return nil, fmt.Errorf("get partners by person: %w", err)
+	}
+	defer rows.Close()
+
+	var partners []models.Partner
+	for rows.Next() {
+		var partner models.Partner
+		fields := append(personFields(&partner.Person),
+			&partner.Partnership.ID,
+			&partner.Partnership.Person1ID,
+			&partner.Partnership.Person2ID,
+			&partner.Partnership.Type,
+			&partner.Partnership.Status,
+			&partner.Partnership.StartDate,
+			&partner.Partnership.EndDate,
+			&partner.Partnership.CreatedAt,
+			&partner.Partnership.UpdatedAt,
+		)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, fmt.Errorf("scan partner: %w", err)
+		}
+		partners = append(partners, partner)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return partners, nil
+}
+
+// UpdatePartnership обновляет тип, статус и даты союза (участники не меняются)
+func (s *Storage) UpdatePartnership(ctx context.Context, p *models.Partnership) error {
+	query := `
+        UPDATE partnerships
+        SET partnership_type = $1,
+            status = $2,
+            start_date = $3,
+            end_date = $4,
+            updated_at = NOW()
+        WHERE id = $5
+        RETURNING updated_at
+    `
+
+	err := s.DB.QueryRow(ctx, query,
+		p.Type,
+		p.Status,
+		p.StartDate,
+		p.EndDate,
+		p.ID,
+	).Scan(&p.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPartnershipNotFound
+		}
+		return fmt.Errorf("update partnership: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePartnership удаляет союз
+func (s *Storage) DeletePartnership(ctx context.Context, id int) error {
+	query := `DELETE FROM partnerships WHERE id = $1`
+
+	commandTag, err := s.DB.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete partnership: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrPartnershipNotFound
+	}
+
+	return nil
+}
@@ -5,26 +5,67 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
 
-func (s *Storage) CreatePerson(ctx context.Context, p *models.Person) (int, error) {
-	query := `
-		INSERT INTO persons (first_name, last_name, birth_date, death_date, is_male, biography, tree_id)
-        VALUES ($1, $2, $3, $4, $5, $6, $7)
-        RETURNING id, created_at, updated_at
+// personColumns — столбцы персоны (таблица persons с псевдонимом p) в порядке personFields
+const personColumns = `p.id, p.first_name, p.last_name,
+               p.birth_date, p.birth_date_end, p.birth_date_qualifier, p.birth_date_precision, p.birth_date_end_precision,
+               p.death_date, p.death_date_end, p.death_date_qualifier, p.death_date_precision, p.death_date_end_precision,
+               p.birth_place_id, p.death_place_id, p.portrait_id, p.sex, p.biography, p.privacy, p.metadata, p.tree_id, p.created_at, p.updated_at`
+
+// personFields возвращает адреса полей персоны для Scan в порядке personColumns
+func personFields(p *models.Person) []any {
+	fields := []any{&p.ID, &p.FirstName, &p.LastName}
+	fields = append(fields, genDateFields(&p.BirthDate)...)
+	fields = append(fields, genDateFields(&p.DeathDate)...)
+	return append(fields,
+		&p.BirthPlaceID,
+		&p.DeathPlaceID,
+		&p.PortraitID,
+		&p.Sex,
+		&p.Biography,
+		&p.Privacy,
+		&p.Metadata,
+		&p.TreeID,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+}
+
+// personInsertQuery вставляет персону; аргументы — personInsertArgs
+const personInsertQuery = `
+		INSERT INTO persons (first_name, last_name,
+                             birth_date, birth_date_end, birth_date_qualifier, birth_date_precision, birth_date_end_precision,
+                             death_date, death_date_end, death_date_qualifier, death_date_precision, death_date_end_precision,
+                             birth_place_id, death_place_id, sex, biography, privacy, tree_id, metadata)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
+                COALESCE(NULLIF($17, ''), 'auto'), $18, COALESCE($19::jsonb, '{}'))
+        RETURNING id, privacy, created_at, updated_at
 	`
 
-	err := s.DB.QueryRow(ctx, query,
-		p.FirstName,
-		p.LastName,
-		p.BirthDate,
-		p.DeathDate,
-		p.IsMale,
-		p.Biography,
-		p.TreeID,
-	).Scan(&p.ID,
+// personInsertArgs возвращает аргументы personInsertQuery
+func personInsertArgs(p *models.Person) []any {
+	args := []any{p.FirstName, p.LastName}
+	args = append(args, genDateValues(p.BirthDate)...)
+	args = append(args, genDateValues(p.DeathDate)...)
+	return append(args, p.BirthPlaceID, p.DeathPlaceID, p.Sex, p.Biography, p.Privacy, p.TreeID, p.Metadata)
+}
+
+// CreatePerson создаёт персону вместе с событиями рождения и смерти
+func (s *Storage) CreatePerson(ctx context.Context, p *models.Person) (int, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin create person: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, personInsertQuery, personInsertArgs(p)...).Scan(&p.ID,
+		&p.Privacy,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	)
@@ -33,32 +74,28 @@ func (s *Storage) CreatePerson(ctx context.Context, p *models.Person) (int, erro
 		return 0, fmt.Errorf("create person: %w", err)
 	}
 
+	if err := syncVitalEvents(ctx, tx, p); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit create person: %w", err)
+	}
+
 	return p.ID, nil
 }
 
 func (s *Storage) GetPersonByID(ctx context.Context, id int) (*models.Person, error) {
 	query := `
-		SELECT id, first_name, last_name, birth_date, death_date, 
-		       is_male, biography, tree_id, created_at, updated_at
-        FROM persons
-        WHERE id = $1
+		SELECT ` + personColumns + `
+        FROM persons p
+        WHERE p.id = $1
 	`
 	var person models.Person
 
 	row := s.DB.QueryRow(ctx, query, id)
 
-	err := row.Scan(
-		&person.ID,
-		&person.FirstName,
-		&person.LastName,
-		&person.BirthDate,
-		&person.DeathDate,
-		&person.IsMale,
-		&person.Biography,
-		&person.TreeID,
-		&person.CreatedAt,
-		&person.UpdatedAt,
-	)
+	err := row.Scan(personFields(&person)...)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -72,11 +109,10 @@ func (s *Storage) GetPersonByID(ctx context.Context, id int) (*models.Person, er
 
 func (s *Storage) GetPersonsByTreeID(ctx context.Context, treeID int) ([]models.Person, error) {
 	query := `
-        SELECT id, first_name, last_name, birth_date, death_date, 
-               is_male, biography, tree_id, created_at, updated_at
-        FROM persons
-        WHERE tree_id = $1
-        ORDER BY created_at DESC
+        SELECT ` + personColumns + `
+        FROM persons p
+        WHERE p.tree_id = $1
+        ORDER BY p.created_at DESC
     `
 
 	rows, err := s.DB.Query(ctx, query, treeID)
@@ -88,18 +124,7 @@ func (s *Storage) GetPersonsByTreeID(ctx context.Context, treeID int) ([]models.
 	var persons []models.Person
 	for rows.Next() {
 		var person models.Person
-		if err := rows.Scan(
-			&person.ID,
-			&person.FirstName,
-			&person.LastName,
-			&person.BirthDate,
-			&person.DeathDate,
-			&person.IsMale,
-			&person.Biography,
-			&person.TreeID,
-			&person.CreatedAt,
-			&person.UpdatedAt,
-		); err != nil {
+		if err := rows.Scan(personFields(&person)...); err != nil {
 			return nil, fmt.Errorf("scan person: %w", err)
 		}
 		persons = append(persons, person)
@@ -112,30 +137,237 @@ func (s *Storage) GetPersonsByTreeID(ctx context.Context, treeID int) ([]models.
 	return persons, nil
 }
 
-func (s *Storage) UpdatePerson(ctx context.Context, p *models.Person) error {
+// birthDateLow и birthDateHigh — самый ранний и самый поздний возможный день рождения,
+// как в GenDate.bounds (ABT/CAL/EST расширяются на 5 лет). NULL — открытая граница (BEF/AFT).
+const (
+	birthDateLow = `CASE
+                WHEN p.birth_date_qualifier = 'before' THEN NULL
+                WHEN p.birth_date_qualifier = 'after' THEN p.birth_date_end + 1
+                WHEN p.birth_date_qualifier IN ('about', 'calculated', 'estimated')
+                    THEN (p.birth_date - INTERVAL '5 years')::date
+                ELSE p.birth_date
+            END`
+	birthDateHigh = `CASE
+                WHEN p.birth_date_qualifier = 'before' THEN p.birth_date - 1
+                WHEN p.birth_date_qualifier = 'after' THEN NULL
+                WHEN p.birth_date_qualifier IN ('about', 'calculated', 'estimated')
+                    THEN (p.birth_date_end + INTERVAL '5 years')::date
+                ELSE p.birth_date_end
+            END`
+)
+
+// personLiving — персона считается живой, как в PrivacyService.IsHidden для auto:
+// нет даты смерти и она могла родиться позже $4
+const personLiving = `(p.death_date IS NULL
+            AND (p.birth_date IS NULL OR COALESCE(` + birthDateHigh + ` > $4::date, TRUE)))`
+
+// personHidden — персона скрывается от зрителей, как в models.Person.Hidden
+const personHidden = `(p.privacy = 'private' OR (p.privacy = 'auto' AND ` + personLiving + `))`
+
+// personListWhere — условия списка персон; аргументы — personListArgs
+const personListWhere = `
+        WHERE p.tree_id = $1
+          AND ($2::text = '' OR p.sex = $2)
+          AND ($3::boolean IS NULL OR ` + personLiving + ` = $3)
+          AND ($5::date IS NULL OR (p.birth_date IS NOT NULL AND COALESCE(` + birthDateHigh + ` >= $5, TRUE)))
+          AND ($6::date IS NULL OR (p.birth_date IS NOT NULL AND COALESCE(` + birthDateLow + ` < $6, TRUE)))
+          AND p.metadata @> COALESCE($7::jsonb, '{}')
+          AND p.metadata ?& $8::text[]
+          AND NOT ($9::boolean AND ` + personHidden + `)
+    `
+
+// personListArgs возвращает аргументы personListWhere
+func personListArgs(treeID int, f models.PersonListFilter) []any {
+	return []any{
+		treeID,
+		f.Sex,
+		f.Living,
+		f.LivingSince,
+		f.BornFrom,
+		f.BornBefore,
+		f.MetadataMatch,
+		allowedValues(f.MetadataKeys),
+		f.VisibleOnly,
+	}
+}
+
+// personSorts — ключи сортировки списка персон
+var personSorts = map[string]listSort[models.Person]{
+	models.PersonSortName: {
+		columns: []string{"p.last_name", "p.first_name"},
+		types:   []string{"text", "text"},
+		key: func(p *models.Person) []string {
+			return []string{p.LastName, p.FirstName}
+		},
+	},
+	models.PersonSortBirthDate: {
+		// 'infinity' ставит персоны без даты рождения в конец и сравнивается как обычная дата
+		columns: []string{"COALESCE(p.birth_date, 'infinity')"},
+		types:   []string{"date"},
+		key: func(p *models.Person) []string {
+			if p.BirthDate == nil {
+				return []string{"infinity"}
+			}
+			return []string{p.BirthDate.Start.Format(time.DateOnly)}
+		},
+	},
+	models.PersonSortUpdatedAt: {
+		columns: []string{"p.updated_at"},
+		types:   []string{"timestamp"},
+		key: func(p *models.Person) []string {
+			return []string{p.UpdatedAt.Format(cursorTimeLayout)}
+		},
+	},
+}
+
+// redactedPersonSorts — ключи сортировки списка для зрителя. Курсор уходит клиенту, поэтому
+// у скрытых персон вместо first_name и updated_at в ключе и в сортировке стоят заглушки.
+// Сортировка по дате рождения для зрителя идёт только по видимым персонам (VisibleOnly).
+func redactedPersonSorts(livingSince time.Time) map[string]listSort[models.Person] {
+	return map[string]listSort[models.Person]{
+		models.PersonSortName: {
+			columns: []string{"p.last_name", "CASE WHEN " + personHidden + " THEN '' ELSE p.first_name END"},
+			types:   []string{"text", "text"},
+			key: func(p *models.Person) []string {
+				if p.Hidden(livingSince) {
+					return []string{p.LastName, ""}
+				}
+				return []string{p.LastName, p.FirstName}
+			},
+		},
+		models.PersonSortBirthDate: personSorts[models.PersonSortBirthDate],
+		models.PersonSortUpdatedAt: {
+			columns: []string{"CASE WHEN " + personHidden + " THEN '-infinity'::timestamp ELSE p.updated_at END"},
+			types:   []string{"timestamp"},
+			key: func(p *models.Person) []string {
+				if p.Hidden(livingSince) {
+					return []string{"-infinity"}
+				}
+				return []string{p.UpdatedAt.Format(cursorTimeLayout)}
+			},
+		},
+	}
+}
+
+// ListPersons возвращает страницу персон дерева под фильтром и общее число таких персон.
+// Курсор следующей страницы равен nil на последней странице.
+func (s *Storage) ListPersons(ctx context.Context, treeID int, f models.PersonListFilter, page models.ListPage) ([]models.Person, int, *models.ListCursor, error) {
+	sorts := personSorts
+	if f.Redact {
+		sorts = redactedPersonSorts(f.LivingSince)
+	}
+	sort, ok := sorts[page.Sort]
+	if !ok {
+		return nil, 0, nil, fmt.Errorf("unknown person sort %q", page.Sort)
+	}
+
+	args := personListArgs(treeID, f)
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM persons p` + personListWhere
+	if err := s.DB.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, nil, fmt.Errorf("count persons: %w", err)
+	}
+
+	after, afterArgs, err := sort.after("p.id", page, len(args)+1)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	args = append(args, afterArgs...)
+	// Берём на одну запись больше, чтобы узнать, есть ли следующая страница
+	args = append(args, page.Limit+1)
+
 	query := `
+        SELECT ` + personColumns + `
+        FROM persons p` + personListWhere + `
+          AND ` + after + `
+        ` + sort.orderBy("p.id", page.Desc) + `
+        LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("list persons: %w", err)
+	}
+	defer rows.Close()
+
+	var persons []models.Person
+	for rows.Next() {
+		var person models.Person
+		if err := rows.Scan(personFields(&person)...); err != nil {
+			return nil, 0, nil, fmt.Errorf("scan person: %w", err)
+		}
+		persons = append(persons, person)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	var next *models.ListCursor
+	if len(persons) > page.Limit {
+		persons = persons[:page.Limit]
+		last := &persons[len(persons)-1]
+		next = sort.cursor(page, last, last.ID)
+	}
+
+	return persons, total, next, nil
+}
+
+// personUpdateQuery обновляет поля персоны; аргументы — personUpdateArgs
+const personUpdateQuery = `
        UPDATE persons 
        SET first_name = $1, 
            last_name = $2, 
            birth_date = $3, 
-           death_date = $4, 
-           is_male = $5, 
-           biography = $6,
+           birth_date_end = $4, 
+           birth_date_qualifier = $5, 
+           birth_date_precision = $6, 
+           birth_date_end_precision = $7, 
+           death_date = $8, 
+           death_date_end = $9, 
+           death_date_qualifier = $10, 
+           death_date_precision = $11, 
+           death_date_end_precision = $12, 
+           birth_place_id = $13,
+           death_place_id = $14,
+           sex = $15, 
+           biography = $16,
+           privacy = COALESCE(NULLIF($17, ''), privacy),
+           metadata = COALESCE($18::jsonb, metadata), -- nil — без изменений
            updated_at = NOW()
-       WHERE id = $7
+       WHERE id = $19
     `
 
-	commandTag, err := s.DB.Exec(ctx, query,
-		p.FirstName, // $1
-		p.LastName,  // $2
-		p.BirthDate, // $3
-		p.DeathDate, // $4
-		p.IsMale,    // $5
-		p.Biography, // $6
-		p.ID,        // $7
+// personUpdateArgs возвращает аргументы personUpdateQuery
+func personUpdateArgs(p *models.Person) []any {
+	args := []any{p.FirstName, p.LastName}             // $1, $2
+	args = append(args, genDateValues(p.BirthDate)...) // $3-$7
+	args = append(args, genDateValues(p.DeathDate)...) // $8-$12
+	return append(args,
+		p.BirthPlaceID, // $13
+		p.DeathPlaceID, // $14
+		p.Sex,          // $15
+		p.Biography,    // $16
+		p.Privacy,      // $17
+		p.Metadata,     // $18
+		p.ID,           // $19
 	)
+}
+
+func (s *Storage) UpdatePerson(ctx context.Context, p *models.Person) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin update person: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	commandTag, err := tx.Exec(ctx, personUpdateQuery, personUpdateArgs(p)...)
 
 	if err != nil {
+		// Смена пола, при которой у общего ребенка оба родителя одного пола
+		if constraintErr := relationshipConstraintError(err); constraintErr != nil {
+			return constraintErr
+		}
 		return fmt.Errorf("update person: %w", err)
 	}
 
@@ -144,6 +376,50 @@ func (s *Storage) UpdatePerson(ctx context.Context, p *models.Person) error {
 		return ErrPersonNotFound
 	}
 
+	// События рождения и смерти повторяют даты персоны
+	if err := syncVitalEvents(ctx, tx, p); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit update person: %w", err)
+	}
+
+	return nil
+}
+
+// LockPersons блокирует строки персон до конца транзакции, чтобы параллельные запросы
+// не меняли их связи между проверкой и записью. Строки блокируются по возрастанию ID,
+// поэтому два запроса с общими персонами не ждут друг друга по кругу.
+// Имеет смысл только внутри WithTx.
+func (s *Storage) LockPersons(ctx context.Context, ids ...int) error {
+	query := `
+        SELECT id
+        FROM persons
+        WHERE id = ANY($1)
+        ORDER BY id
+        FOR UPDATE
+    `
+
+	rows, err := s.DB.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("lock persons: %w", err)
+	}
+	defer rows.Close()
+
+	locked := 0
+	for rows.Next() {
+		locked++
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	if locked < len(slices.Compact(slices.Sorted(slices.Values(ids)))) {
+		return ErrPersonNotFound
+	}
+
 	return nil
 }
 
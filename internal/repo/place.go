@@ -0,0 +1,287 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// placeColumns — столбцы места (таблица places с псевдонимом pl) в порядке placeFields
+const placeColumns = `pl.id, pl.tree_id, pl.parent_id, pl.name, pl.place_type,
+               pl.latitude, pl.longitude, pl.created_at, pl.updated_at`
+
+// placeFields возвращает адреса полей места для Scan в порядке placeColumns
+func placeFields(p *models.Place) []any {
+	return []any{
+		&p.ID,
+		&p.TreeID,
+		&p.ParentID,
+		&p.Name,
+		&p.Type,
+		&p.Latitude,
+		&p.Longitude,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	}
+}
+
+// placeError переводит нарушение уникальности в ErrPlaceAlreadyExists
+func placeError(err error, action string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 23505 - unique_violation (у родителя уже есть место с таким названием)
+		if pgErr.Code == "23505" {
+			return ErrPlaceAlreadyExists
+		}
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+// CreatePlace создаёт место вместе с историческими названиями
+func (s *Storage) CreatePlace(ctx context.Context, p *models.Place) (int, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin create place: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+        INSERT INTO places (tree_id, parent_id, name, place_type, latitude, longitude)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at, updated_at
+    `
+
+	err = tx.QueryRow(ctx, query,
+		p.TreeID,
+		p.ParentID,
+		p.Name,
+		p.Type,
+		p.Latitude,
+		p.Longitude,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+
+	if err != nil {
+		return 0, placeError(err, "create place")
+	}
+
+	if err := replacePlaceNames(ctx, tx, p); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit create place: %w", err)
+	}
+
+	return p.ID, nil
+}
+
+// GetOrCreatePlace возвращает место верхнего уровня с таким названием, создавая его при необходимости
+func (s *Storage) GetOrCreatePlace(ctx context.Context, treeID int, name string) (*models.Place, error) {
+	// DO UPDATE (а не DO NOTHING), чтобы RETURNING вернул и уже существующую строку
+	query := `
+        INSERT INTO places AS pl (tree_id, name)
+        VALUES ($1, $2)
+        ON CONFLICT ON CONSTRAINT places_tree_parent_name_key DO UPDATE SET name = EXCLUDED.name
+        RETURNING ` + placeColumns + `
+    `
+
+	var place models.Place
+	if err := s.DB.QueryRow(ctx, query, treeID, name).Scan(placeFields(&place)...); err != nil {
+		return nil, fmt.Errorf("get or create place: %w", err)
+	}
+
+	return &place, nil
+}
+
+// GetPlaceByID получает место с историческими названиями
+func (s *Storage) GetPlaceByID(ctx context.Context, id int) (*models.Place, error) {
+	query := `
+        SELECT ` + placeColumns + `
+        FROM places pl
+        WHERE pl.id = $1
+    `
+
+	var place models.Place
+	if err := s.DB.QueryRow(ctx, query, id).Scan(placeFields(&place)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPlaceNotFound
+		}
+		return nil, fmt.Errorf("get place by id: %w", err)
+	}
+
+	namesQuery := `
+        SELECT name, from_year, to_year
+        FROM place_names
+        WHERE place_id = $1
+        ORDER BY from_year ASC NULLS FIRST, id
+    `
+
+	rows, err := s.DB.Query(ctx, namesQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("get place names: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name models.PlaceName
+		if err := rows.Scan(&name.Name, &name.FromYear, &name.ToYear); err != nil {
+			return nil, fmt.Errorf("scan place name: %w", err)
+		}
+		place.Names = append(place.Names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return &place, nil
+}
+
+// GetPlacesByTreeID получает все места дерева с историческими названиями
+func (s *Storage) GetPlacesByTreeID(ctx context.Context, treeID int) ([]models.Place, error) {
+	query := `
+        SELECT ` + placeColumns + `
+        FROM places pl
+        WHERE pl.tree_id = $1
+        ORDER BY pl.name, pl.id
+    `
+
+	rows, err := s.DB.Query(ctx, query, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("get places by tree: %w", err)
+	}
+	defer rows.Close()
+
+	var places []models.Place
+	byID := make(map[int]int)
+	for rows.Next() {
+		var place models.Place
+		if err := rows.Scan(placeFields(&place)...); err != nil {
+			return nil, fmt.Errorf("scan place: %w", err)
+		}
+		byID[place.ID] = len(places)
+		places = append(places, place)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	namesQuery := `
+        SELECT pn.place_id, pn.name, pn.from_year, pn.to_year
+        FROM place_names pn
+        INNER JOIN places pl ON pl.id = pn.place_id
+        WHERE pl.tree_id = $1
+        ORDER BY pn.from_year ASC NULLS FIRST, pn.id
+    `
+
+	nameRows, err := s.DB.Query(ctx, namesQuery, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("get place names: %w", err)
+	}
+	defer nameRows.Close()
+
+	for nameRows.Next() {
+		var placeID int
+		var name models.PlaceName
+		if err := nameRows.Scan(&placeID, &name.Name, &name.FromYear, &name.ToYear); err != nil {
+			return nil, fmt.Errorf("scan place name: %w", err)
+		}
+		if i, ok := byID[placeID]; ok {
+			places[i].Names = append(places[i].Names, name)
+		}
+	}
+
+	if err := nameRows.Err(); err != nil {
+		return nil, fmt.Errorf("place names rows error: %w", err)
+	}
+
+	return places, nil
+}
+
+// UpdatePlace обновляет место и заменяет его исторические названия
+func (s *Storage) UpdatePlace(ctx context.Context, p *models.Place) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin update place: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+        UPDATE places
+        SET parent_id = $1,
+            name = $2,
+            place_type = $3,
+            latitude = $4,
+            longitude = $5,
+            updated_at = NOW()
+        WHERE id = $6
+        RETURNING updated_at
+    `
+
+	err = tx.QueryRow(ctx, query,
+		p.ParentID,
+		p.Name,
+		p.Type,
+		p.Latitude,
+		p.Longitude,
+		p.ID,
+	).Scan(&p.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPlaceNotFound
+		}
+		return placeError(err, "update place")
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM place_names WHERE place_id = $1`, p.ID); err != nil {
+		return fmt.Errorf("delete place names: %w", err)
+	}
+
+	if err := replacePlaceNames(ctx, tx, p); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit update place: %w", err)
+	}
+
+	return nil
+}
+
+// replacePlaceNames вставляет исторические названия места
+func replacePlaceNames(ctx context.Context, tx pgx.Tx, p *models.Place) error {
+	query := `
+        INSERT INTO place_names (place_id, name, from_year, to_year)
+        VALUES ($1, $2, $3, $4)
+    `
+
+	batch := &pgx.Batch{}
+	for _, name := range p.Names {
+		batch.Queue(query, p.ID, name.Name, name.FromYear, name.ToYear)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("insert place names: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePlace удаляет место. Ссылки персон, событий и дочерних мест обнуляются.
+func (s *Storage) DeletePlace(ctx context.Context, id int) error {
+	commandTag, err := s.DB.Exec(ctx, `DELETE FROM places WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete place: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrPlaceNotFound
+	}
+
+	return nil
+}
@@ -3,9 +3,37 @@ package repo
 import (
 	"GenealogyTree/internal/models"
 	"context"
+	"errors"
 	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// relationshipConstraintErrors — ограничения связей из миграций и соответствующие им ошибки
+var relationshipConstraintErrors = map[string]error{
+	"relationships_parent_id_child_id_key": ErrRelationshipExists,
+	"relationships_no_self_parent":         ErrSelfParent,
+	"relationships_max_two_parents":        ErrTooManyParents,
+	"relationships_parents_sex":            ErrSameSexParents,
+	"relationships_same_tree":              ErrCrossTreeRelationship,
+	"relationships_type":                   ErrUnknownRelationshipType,
+}
+
+// relationshipConstraintError переводит нарушение ограничения связей в sentinel-ошибку;
+// для остальных ошибок возвращает nil
+func relationshipConstraintError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	// 23505 - unique_violation, 23514 - check_violation (в том числе из триггеров)
+	if pgErr.Code != "23505" && pgErr.Code != "23514" {
+		return nil
+	}
+	return relationshipConstraintErrors[pgErr.ConstraintName]
+}
+
 // CreateRelationship создаёт связь родитель-ребенок
 func (s *Storage) CreateRelationship(ctx context.Context, rel *models.Relationship) (int, error) {
 	query := `
@@ -21,12 +49,41 @@ func (s *Storage) CreateRelationship(ctx context.Context, rel *models.Relationsh
 	).Scan(&rel.ID)
 
 	if err != nil {
+		if constraintErr := relationshipConstraintError(err); constraintErr != nil {
+			return 0, constraintErr
+		}
 		return 0, fmt.Errorf("create relationship: %w", err)
 	}
 
 	return rel.ID, nil
 }
 
+// GetRelationshipByID получает связь родитель-ребенок по ID
+func (s *Storage) GetRelationshipByID(ctx context.Context, id int) (*models.Relationship, error) {
+	query := `
+        SELECT id, parent_id, child_id, relationship_type
+        FROM relationships
+        WHERE id = $1
+    `
+
+	var rel models.Relationship
+	err := s.DB.QueryRow(ctx, query, id).Scan(
+		&rel.ID,
+		&rel.ParentID,
+		&rel.ChildID,
+		&rel.RelationshipType,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRelationshipNotFound
+		}
+		return nil, fmt.Errorf("get relationship by id: %w", err)
+	}
+
+	return &rel, nil
+}
+
 // DeleteRelationship удаляет связь между родителем и ребенком
 func (s *Storage) DeleteRelationship(ctx context.Context, parentID, childID int) error {
 	query := `DELETE FROM relationships WHERE parent_id = $1 AND child_id = $2`
@@ -46,8 +103,7 @@ func (s *Storage) DeleteRelationship(ctx context.Context, parentID, childID int)
 // GetChildrenByParentID получает всех детей персоны
 func (s *Storage) GetChildrenByParentID(ctx context.Context, parentID int) ([]models.Person, error) {
 	query := `
-        SELECT p.id, p.first_name, p.last_name, p.birth_date, p.death_date,
-               p.is_male, p.biography, p.tree_id, p.created_at, p.updated_at
+        SELECT ` + personColumns + `
         FROM persons p
         INNER JOIN relationships r ON p.id = r.child_id
         WHERE r.parent_id = $1
@@ -62,18 +118,7 @@ func (s *Storage) GetChildrenByParentID(ctx context.Context, parentID int) ([]mo
 	var children []models.Person
 	for rows.Next() {
 		var child models.Person
-		if err := rows.Scan(
-			&child.ID,
-			&child.FirstName,
-			&child.LastName,
-			&child.BirthDate,
-			&child.DeathDate,
-			&child.IsMale,
-			&child.Biography,
-			&child.TreeID,
-			&child.CreatedAt,
-			&child.UpdatedAt,
-		); err != nil {
+		if err := rows.Scan(personFields(&child)...); err != nil {
 			return nil, fmt.Errorf("scan child: %w", err)
 		}
 		children = append(children, child)
@@ -86,11 +131,10 @@ func (s *Storage) GetChildrenByParentID(ctx context.Context, parentID int) ([]mo
 	return children, nil
 }
 
-// GetParentsByChildID получает всех родителей персоны
-func (s *Storage) GetParentsByChildID(ctx context.Context, childID int) ([]models.Person, error) {
+// GetParentLinksByChildID получает всех родителей персоны вместе с типами связей
+func (s *Storage) GetParentLinksByChildID(ctx context.Context, childID int) ([]models.ParentLink, error) {
 	query := `
-        SELECT p.id, p.first_name, p.last_name, p.birth_date, p.death_date,
-               p.is_male, p.biography, p.tree_id, p.created_at, p.updated_at
+        SELECT ` + personColumns + `, r.relationship_type
         FROM persons p
         INNER JOIN relationships r ON p.id = r.parent_id
         WHERE r.child_id = $1
@@ -102,46 +146,38 @@ func (s *Storage) GetParentsByChildID(ctx context.Context, childID int) ([]model
 	}
 	defer rows.Close()
 
-	var parents []models.Person
+	var links []models.ParentLink
 	for rows.Next() {
-		var parent models.Person
-		if err := rows.Scan(
-			&parent.ID,
-			&parent.FirstName,
-			&parent.LastName,
-			&parent.BirthDate,
-			&parent.DeathDate,
-			&parent.IsMale,
-			&parent.Biography,
-			&parent.TreeID,
-			&parent.CreatedAt,
-			&parent.UpdatedAt,
-		); err != nil {
+		var link models.ParentLink
+		if err := rows.Scan(append(personFields(&link.Parent), &link.RelationshipType)...); err != nil {
 			return nil, fmt.Errorf("scan parent: %w", err)
 		}
-		parents = append(parents, parent)
+		links = append(links, link)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	return parents, nil
+	return links, nil
 }
 
 // GetAvailableChildren возвращает персон, которые могут быть детьми для данного родителя
+// по связи, входящей в лимит двух родителей. Даты рождения сравниваются в сервисе, здесь — только связи.
 func (s *Storage) GetAvailableChildren(ctx context.Context, parentID int) ([]models.Person, error) {
 	query := `
-        SELECT DISTINCT p.id, p.first_name, p.last_name, p.birth_date, p.is_male, p.tree_id
+        SELECT DISTINCT ` + personColumns + `
         FROM persons p
         WHERE p.tree_id = (SELECT tree_id FROM persons WHERE id = $1)
           AND p.id != $1
-          AND p.birth_date > (SELECT birth_date FROM persons WHERE id = $1)
           AND p.id NOT IN (
               SELECT child_id FROM relationships WHERE parent_id = $1
           )
           AND (
-              SELECT COUNT(*) FROM relationships WHERE child_id = p.id
+              SELECT COUNT(*)
+              FROM relationships r
+              WHERE r.child_id = p.id
+                AND relationship_counts_toward_parents(r.relationship_type, p.tree_id)
           ) < 2
         ORDER BY p.birth_date ASC
     `
@@ -155,14 +191,7 @@ func (s *Storage) GetAvailableChildren(ctx context.Context, parentID int) ([]mod
 	var persons []models.Person
 	for rows.Next() {
 		var person models.Person
-		if err := rows.Scan(
-			&person.ID,
-			&person.FirstName,
-			&person.LastName,
-			&person.BirthDate,
-			&person.IsMale,
-			&person.TreeID,
-		); err != nil {
+		if err := rows.Scan(personFields(&person)...); err != nil {
 			return nil, fmt.Errorf("scan person: %w", err)
 		}
 		persons = append(persons, person)
@@ -176,23 +205,27 @@ func (s *Storage) GetAvailableChildren(ctx context.Context, parentID int) ([]mod
 }
 
 // GetAvailableParents возвращает персон, которые могут быть родителями для данного ребенка
+// по связи, входящей в лимит двух родителей. Даты рождения сравниваются в сервисе,
+// здесь — только связи и пол с учётом политики дерева.
 func (s *Storage) GetAvailableParents(ctx context.Context, childID int) ([]models.Person, error) {
 	query := `
-        SELECT DISTINCT p.id, p.first_name, p.last_name, p.birth_date, p.is_male, p.tree_id
+        WITH counted AS (
+            SELECT parent.sex
+            FROM relationships r
+            INNER JOIN persons parent ON parent.id = r.parent_id
+            WHERE r.child_id = $1
+              AND relationship_counts_toward_parents(r.relationship_type, parent.tree_id)
+        )
+        SELECT DISTINCT ` + personColumns + `
         FROM persons p
         WHERE p.tree_id = (SELECT tree_id FROM persons WHERE id = $1)
           AND p.id != $1
-          AND p.birth_date < (SELECT birth_date FROM persons WHERE id = $1)
           AND p.id NOT IN (
               SELECT parent_id FROM relationships WHERE child_id = $1
           )
-          AND (
-              (SELECT COUNT(*) FROM relationships WHERE child_id = $1) = 0
-              OR p.is_male != (
-                  SELECT is_male 
-                  FROM persons 
-                  WHERE id = (SELECT parent_id FROM relationships WHERE child_id = $1 LIMIT 1)
-              )
+          AND (SELECT COUNT(*) FROM counted) < 2
+          AND NOT EXISTS (
+              SELECT 1 FROM counted WHERE parents_sex_conflict(counted.sex, p.sex, p.tree_id)
           )
         ORDER BY p.birth_date DESC
     `
@@ -206,14 +239,7 @@ func (s *Storage) GetAvailableParents(ctx context.Context, childID int) ([]model
 	var persons []models.Person
 	for rows.Next() {
 		var person models.Person
-		if err := rows.Scan(
-			&person.ID,
-			&person.FirstName,
-			&person.LastName,
-			&person.BirthDate,
-			&person.IsMale,
-			&person.TreeID,
-		); err != nil {
+		if err := rows.Scan(personFields(&person)...); err != nil {
 			return nil, fmt.Errorf("scan person: %w", err)
 		}
 		persons = append(persons, person)
@@ -225,3 +251,136 @@ func (s *Storage) GetAvailableParents(ctx context.Context, childID int) ([]model
 
 	return persons, nil
 }
+
+// GetAncestors возвращает предков персоны не глубже depth поколений.
+// UNION (а не UNION ALL) отбрасывает повторы, а ограничение глубины
+// гарантирует остановку даже при циклах в старых данных.
+func (s *Storage) GetAncestors(ctx context.Context, personID, depth int) ([]models.Relative, []models.Relationship, error) {
+	query := `
+        WITH RECURSIVE ancestors (person_id, generation) AS (
+            SELECT parent_id, 1
+            FROM relationships
+            WHERE child_id = $1
+            UNION
+            SELECT r.parent_id, a.generation + 1
+            FROM relationships r
+            INNER JOIN ancestors a ON r.child_id = a.person_id
+            WHERE a.generation < $2
+        )
+        SELECT ` + personColumns + `,
+               MIN(a.generation) AS generation
+        FROM ancestors a
+        INNER JOIN persons p ON p.id = a.person_id
+        WHERE p.id != $1
+        GROUP BY p.id
+        ORDER BY generation, p.id
+    `
+
+	return s.getRelatives(ctx, query, personID, depth)
+}
+
+// GetDescendants возвращает потомков персоны не глубже depth поколений
+func (s *Storage) GetDescendants(ctx context.Context, personID, depth int) ([]models.Relative, []models.Relationship, error) {
+	query := `
+        WITH RECURSIVE descendants (person_id, generation) AS (
+            SELECT child_id, 1
+            FROM relationships
+            WHERE parent_id = $1
+            UNION
+            SELECT r.child_id, d.generation + 1
+            FROM relationships r
+            INNER JOIN descendants d ON r.parent_id = d.person_id
+            WHERE d.generation < $2
+        )
+        SELECT ` + personColumns + `,
+               MIN(d.generation) AS generation
+        FROM descendants d
+        INNER JOIN persons p ON p.id = d.person_id
+        WHERE p.id != $1
+        GROUP BY p.id
+        ORDER BY generation, p.id
+    `
+
+	return s.getRelatives(ctx, query, personID, depth)
+}
+
+// getRelatives выполняет рекурсивный запрос и дочитывает связи между найденными персонами
+func (s *Storage) getRelatives(ctx context.Context, query string, personID, depth int) ([]models.Relative, []models.Relationship, error) {
+	rows, err := s.DB.Query(ctx, query, personID, depth)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get relatives: %w", err)
+	}
+	defer rows.Close()
+
+	var relatives []models.Relative
+	ids := []int{personID}
+	for rows.Next() {
+		var relative models.Relative
+		if err := rows.Scan(append(personFields(&relative.Person), &relative.Generation)...); err != nil {
+			return nil, nil, fmt.Errorf("scan relative: %w", err)
+		}
+		relatives = append(relatives, relative)
+		ids = append(ids, relative.ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	// Связи внутри найденного множества — чтобы нарисовать схему без доп. запросов
+	relationshipsQuery := `
+        SELECT id, parent_id, child_id, relationship_type
+        FROM relationships
+        WHERE parent_id = ANY($1) AND child_id = ANY($1)
+    `
+
+	relRows, err := s.DB.Query(ctx, relationshipsQuery, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get relatives relationships: %w", err)
+	}
+	defer relRows.Close()
+
+	var relationships []models.Relationship
+	for relRows.Next() {
+		var rel models.Relationship
+		if err := relRows.Scan(
+			&rel.ID,
+			&rel.ParentID,
+			&rel.ChildID,
+			&rel.RelationshipType,
+		); err != nil {
+			return nil, nil, fmt.Errorf("scan relationship: %w", err)
+		}
+		relationships = append(relationships, rel)
+	}
+
+	if err := relRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("relationships rows error: %w", err)
+	}
+
+	return relatives, relationships, nil
+}
+
+// IsAncestor проверяет, является ли ancestorID предком personID.
+// UNION без номера поколения останавливается и на зацикленных данных.
+func (s *Storage) IsAncestor(ctx context.Context, ancestorID, personID int) (bool, error) {
+	query := `
+        WITH RECURSIVE ancestors (person_id) AS (
+            SELECT parent_id
+            FROM relationships
+            WHERE child_id = $1
+            UNION
+            SELECT r.parent_id
+            FROM relationships r
+            INNER JOIN ancestors a ON r.child_id = a.person_id
+        )
+        SELECT EXISTS (SELECT 1 FROM ancestors WHERE person_id = $2)
+    `
+
+	var exists bool
+	if err := s.DB.QueryRow(ctx, query, personID, ancestorID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("is ancestor: %w", err)
+	}
+
+	return exists, nil
+}
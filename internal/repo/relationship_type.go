@@ -0,0 +1,136 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// relationshipTypeColumns — столбцы типа связи в порядке relationshipTypeFields
+const relationshipTypeColumns = `id, tree_id, code, name, counts_toward_parents, blood, created_at`
+
+// relationshipTypeFields возвращает адреса полей для Scan в порядке relationshipTypeColumns
+func relationshipTypeFields(t *models.RelationshipType) []any {
+	return []any{
+		&t.ID,
+		&t.TreeID,
+		&t.Code,
+		&t.Name,
+		&t.CountsTowardParents,
+		&t.Blood,
+		&t.CreatedAt,
+	}
+}
+
+// GetRelationshipTypes получает встроенные типы связей и типы дерева treeID.
+// При treeID = 0 — только встроенные.
+func (s *Storage) GetRelationshipTypes(ctx context.Context, treeID int) ([]models.RelationshipType, error) {
+	query := `
+        SELECT ` + relationshipTypeColumns + `
+        FROM relationship_types
+        WHERE tree_id IS NULL OR tree_id = $1
+        ORDER BY tree_id NULLS FIRST, id
+    `
+
+	rows, err := s.DB.Query(ctx, query, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("get relationship types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []models.RelationshipType
+	for rows.Next() {
+		var t models.RelationshipType
+		if err := rows.Scan(relationshipTypeFields(&t)...); err != nil {
+			return nil, fmt.Errorf("scan relationship type: %w", err)
+		}
+		types = append(types, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return types, nil
+}
+
+// GetRelationshipTypeByID получает тип связи по ID
+func (s *Storage) GetRelationshipTypeByID(ctx context.Context, id int) (*models.RelationshipType, error) {
+	query := `SELECT ` + relationshipTypeColumns + ` FROM relationship_types WHERE id = $1`
+
+	var t models.RelationshipType
+	if err := s.DB.QueryRow(ctx, query, id).Scan(relationshipTypeFields(&t)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRelationshipTypeNotFound
+		}
+		return nil, fmt.Errorf("get relationship type by id: %w", err)
+	}
+
+	return &t, nil
+}
+
+// CreateRelationshipType добавляет тип связи в реестр дерева.
+// Код не должен совпадать ни с типом этого дерева, ни со встроенным типом.
+func (s *Storage) CreateRelationshipType(ctx context.Context, t *models.RelationshipType) (int, error) {
+	query := `
+        INSERT INTO relationship_types (tree_id, code, name, counts_toward_parents, blood)
+        SELECT $1, $2, $3, $4, $5
+        WHERE NOT EXISTS (SELECT 1 FROM relationship_types WHERE tree_id IS NULL AND code = $2)
+        RETURNING id, created_at
+    `
+
+	err := s.DB.QueryRow(ctx, query,
+		t.TreeID,
+		t.Code,
+		t.Name,
+		t.CountsTowardParents,
+		t.Blood,
+	).Scan(&t.ID, &t.CreatedAt)
+
+	if err != nil {
+		// Ни одной строки — код занят встроенным типом
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrRelationshipTypeExists
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			// 23505 - unique_violation (тип с таким кодом уже есть в дереве)
+			if pgErr.Code == "23505" {
+				return 0, ErrRelationshipTypeExists
+			}
+		}
+		return 0, fmt.Errorf("create relationship type: %w", err)
+	}
+
+	return t.ID, nil
+}
+
+// DeleteRelationshipType удаляет тип связи дерева, если им не размечена ни одна связь дерева
+func (s *Storage) DeleteRelationshipType(ctx context.Context, t *models.RelationshipType) error {
+	query := `
+        DELETE FROM relationship_types
+        WHERE id = $1
+          AND NOT EXISTS (
+              SELECT 1
+              FROM relationships r
+              INNER JOIN persons p ON p.id = r.child_id
+              WHERE p.tree_id = $2 AND r.relationship_type = $3
+          )
+    `
+
+	commandTag, err := s.DB.Exec(ctx, query, t.ID, t.TreeID, t.Code)
+	if err != nil {
+		return fmt.Errorf("delete relationship type: %w", err)
+	}
+
+	// Сервис уже нашёл тип, поэтому ноль строк означает, что тип используется
+	if commandTag.RowsAffected() == 0 {
+		return ErrRelationshipTypeInUse
+	}
+
+	return nil
+}
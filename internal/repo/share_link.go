@@ -0,0 +1,120 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateShareLink сохраняет ссылку (только хэш токена)
+func (s *Storage) CreateShareLink(ctx context.Context, l *models.ShareLink) (int, error) {
+	query := `
+        INSERT INTO share_links (tree_id, token_hash, created_by, expires_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at
+    `
+
+	err := s.DB.QueryRow(ctx, query,
+		l.TreeID,
+		l.TokenHash,
+		l.CreatedBy,
+		l.ExpiresAt,
+	).Scan(&l.ID, &l.CreatedAt)
+
+	if err != nil {
+		return 0, fmt.Errorf("create share link: %w", err)
+	}
+
+	return l.ID, nil
+}
+
+// GetShareLinkByTokenHash находит ссылку по хэшу токена
+func (s *Storage) GetShareLinkByTokenHash(ctx context.Context, tokenHash string) (*models.ShareLink, error) {
+	query := `
+        SELECT id, tree_id, token_hash, created_by, expires_at, revoked_at, created_at
+        FROM share_links
+        WHERE token_hash = $1
+    `
+
+	var l models.ShareLink
+	err := s.DB.QueryRow(ctx, query, tokenHash).Scan(
+		&l.ID,
+		&l.TreeID,
+		&l.TokenHash,
+		&l.CreatedBy,
+		&l.ExpiresAt,
+		&l.RevokedAt,
+		&l.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrShareLinkNotFound
+		}
+		return nil, fmt.Errorf("get share link by token: %w", err)
+	}
+
+	return &l, nil
+}
+
+// GetShareLinksByTreeID получает все ссылки дерева, включая отозванные
+func (s *Storage) GetShareLinksByTreeID(ctx context.Context, treeID int) ([]models.ShareLink, error) {
+	query := `
+        SELECT id, tree_id, token_hash, created_by, expires_at, revoked_at, created_at
+        FROM share_links
+        WHERE tree_id = $1
+        ORDER BY created_at DESC
+    `
+
+	rows, err := s.DB.Query(ctx, query, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("get share links by tree: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.ShareLink
+	for rows.Next() {
+		var l models.ShareLink
+		if err := rows.Scan(
+			&l.ID,
+			&l.TreeID,
+			&l.TokenHash,
+			&l.CreatedBy,
+			&l.ExpiresAt,
+			&l.RevokedAt,
+			&l.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan share link: %w", err)
+		}
+		links = append(links, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return links, nil
+}
+
+// RevokeShareLink отзывает ссылку дерева
+func (s *Storage) RevokeShareLink(ctx context.Context, treeID, id int) error {
+	query := `
+        UPDATE share_links
+        SET revoked_at = NOW()
+        WHERE id = $1 AND tree_id = $2 AND revoked_at IS NULL
+    `
+
+	commandTag, err := s.DB.Exec(ctx, query, id, treeID)
+	if err != nil {
+		return fmt.Errorf("revoke share link: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrShareLinkNotFound
+	}
+
+	return nil
+}
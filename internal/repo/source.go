@@ -0,0 +1,145 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// sourceColumns — столбцы источника в порядке sourceFields
+const sourceColumns = `id, tree_id, title, author, repository, url, archive_ref, created_at, updated_at`
+
+// sourceFields возвращает адреса полей источника для Scan в порядке sourceColumns
+func sourceFields(src *models.Source) []any {
+	return []any{
+		&src.ID,
+		&src.TreeID,
+		&src.Title,
+		&src.Author,
+		&src.Repository,
+		&src.URL,
+		&src.ArchiveRef,
+		&src.CreatedAt,
+		&src.UpdatedAt,
+	}
+}
+
+// CreateSource создаёт источник в дереве
+func (s *Storage) CreateSource(ctx context.Context, src *models.Source) (int, error) {
+	query := `
+        INSERT INTO sources (tree_id, title, author, repository, url, archive_ref)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at, updated_at
+    `
+
+	err := s.DB.QueryRow(ctx, query,
+		src.TreeID,
+		src.Title,
+		src.Author,
+		src.Repository,
+		src.URL,
+		src.ArchiveRef,
+	).Scan(&src.ID, &src.CreatedAt, &src.UpdatedAt)
+
+	if err != nil {
+		return 0, fmt.Errorf("create source: %w", err)
+	}
+
+	return src.ID, nil
+}
+
+// GetSourceByID получает источник по ID
+func (s *Storage) GetSourceByID(ctx context.Context, id int) (*models.Source, error) {
+	query := `SELECT ` + sourceColumns + ` FROM sources WHERE id = $1`
+
+	var src models.Source
+	if err := s.DB.QueryRow(ctx, query, id).Scan(sourceFields(&src)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSourceNotFound
+		}
+		return nil, fmt.Errorf("get source by id: %w", err)
+	}
+
+	return &src, nil
+}
+
+// GetSourcesByTreeID получает все источники дерева
+func (s *Storage) GetSourcesByTreeID(ctx context.Context, treeID int) ([]models.Source, error) {
+	query := `
+        SELECT ` + sourceColumns + `
+        FROM sources
+        WHERE tree_id = $1
+        ORDER BY title, id
+    `
+
+	rows, err := s.DB.Query(ctx, query, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("get sources by tree: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []models.Source
+	for rows.Next() {
+		var src models.Source
+		if err := rows.Scan(sourceFields(&src)...); err != nil {
+			return nil, fmt.Errorf("scan source: %w", err)
+		}
+		sources = append(sources, src)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return sources, nil
+}
+
+// UpdateSource обновляет источник
+func (s *Storage) UpdateSource(ctx context.Context, src *models.Source) error {
+	query := `
+        UPDATE sources
+        SET title = $1,
+            author = $2,
+            repository = $3,
+            url = $4,
+            archive_ref = $5,
+            updated_at = NOW()
+        WHERE id = $6
+        RETURNING updated_at
+    `
+
+	err := s.DB.QueryRow(ctx, query,
+		src.Title,
+		src.Author,
+		src.Repository,
+		src.URL,
+		src.ArchiveRef,
+		src.ID,
+	).Scan(&src.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSourceNotFound
+		}
+		return fmt.Errorf("update source: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSource удаляет источник вместе с его цитатами
+func (s *Storage) DeleteSource(ctx context.Context, id int) error {
+	commandTag, err := s.DB.Exec(ctx, `DELETE FROM sources WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete source: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrSourceNotFound
+	}
+
+	return nil
+}
@@ -5,20 +5,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/jackc/pgx/v5"
 )
 
 func (s *Storage) CreateTree(ctx context.Context, t *models.Tree) (int, error) {
 	query := `
-		INSERT INTO trees (owner_id, name)
-		VALUES ($1, $2)
+		INSERT INTO trees (owner_id, name, allow_same_sex_parents)
+		VALUES ($1, $2, $3)
 		RETURNING id, created_at, updated_at
 	`
 
 	err := s.DB.QueryRow(ctx, query,
 		t.OwnerID,
 		t.Name,
+		t.AllowSameSexParents,
 	).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
 
 	if err != nil {
@@ -30,7 +32,7 @@ func (s *Storage) CreateTree(ctx context.Context, t *models.Tree) (int, error) {
 
 func (s *Storage) GetTreeByID(ctx context.Context, id int) (*models.Tree, error) {
 	query := `
-		SELECT id, owner_id, name, created_at, updated_at
+		SELECT id, owner_id, name, allow_same_sex_parents, created_at, updated_at
 		FROM trees
 		WHERE id = $1
 	`
@@ -40,6 +42,7 @@ func (s *Storage) GetTreeByID(ctx context.Context, id int) (*models.Tree, error)
 		&tree.ID,
 		&tree.OwnerID,
 		&tree.Name,
+		&tree.AllowSameSexParents,
 		&tree.CreatedAt,
 		&tree.UpdatedAt,
 	)
@@ -54,16 +57,50 @@ func (s *Storage) GetTreeByID(ctx context.Context, id int) (*models.Tree, error)
 	return &tree, nil
 }
 
-func (s *Storage) GetTreesByOwnerID(ctx context.Context, ownerID int) ([]models.Tree, error) {
-	query := `
-		SELECT id, owner_id, name, created_at, updated_at
-        FROM trees
-        WHERE owner_id = $1
-        ORDER BY created_at DESC
+// GetTreesByUserID получает деревья, которыми пользователь владеет или в которые приглашён,
+// вместе с его ролью
+// treeSort — деревья пользователя идут от новых к старым
+var treeSort = listSort[models.Tree]{
+	columns: []string{"t.created_at"},
+	types:   []string{"timestamp"},
+	key: func(t *models.Tree) []string {
+		return []string{t.CreatedAt.Format(cursorTimeLayout)}
+	},
+}
+
+// treesByUserWhere — деревья, которыми пользователь $1 владеет или в которые приглашён
+const treesByUserWhere = `
+        FROM trees t
+        LEFT JOIN tree_members tm ON tm.tree_id = t.id AND tm.user_id = $1
+        WHERE (t.owner_id = $1 OR tm.user_id IS NOT NULL)
 	`
-	rows, err := s.DB.Query(ctx, query, ownerID)
+
+// GetTreesByUserID возвращает страницу деревьев пользователя и их общее число.
+// Курсор следующей страницы равен nil на последней странице.
+func (s *Storage) GetTreesByUserID(ctx context.Context, userID int, page models.ListPage) ([]models.Tree, int, *models.ListCursor, error) {
+	var total int
+	if err := s.DB.QueryRow(ctx, `SELECT COUNT(*)`+treesByUserWhere, userID).Scan(&total); err != nil {
+		return nil, 0, nil, fmt.Errorf("count trees by user: %w", err)
+	}
+
+	after, afterArgs, err := treeSort.after("t.id", page, 2)
 	if err != nil {
-		return nil, fmt.Errorf("get trees by owner: %w", err)
+		return nil, 0, nil, err
+	}
+	args := append([]any{userID}, afterArgs...)
+	// Берём на одну запись больше, чтобы узнать, есть ли следующая страница
+	args = append(args, page.Limit+1)
+
+	query := `
+		SELECT t.id, t.owner_id, t.name, t.allow_same_sex_parents, t.created_at, t.updated_at,
+		       CASE WHEN t.owner_id = $1 THEN 'owner' ELSE tm.role END` + treesByUserWhere + `
+          AND ` + after + `
+        ` + treeSort.orderBy("t.id", page.Desc) + `
+        LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("get trees by user: %w", err)
 	}
 	defer rows.Close()
 
@@ -74,35 +111,50 @@ func (s *Storage) GetTreesByOwnerID(ctx context.Context, ownerID int) ([]models.
 			&tree.ID,
 			&tree.OwnerID,
 			&tree.Name,
+			&tree.AllowSameSexParents,
 			&tree.CreatedAt,
 			&tree.UpdatedAt,
+			&tree.Role,
 		); err != nil {
-			return nil, fmt.Errorf("scan tree: %w", err)
+			return nil, 0, nil, fmt.Errorf("scan tree: %w", err)
 		}
 		trees = append(trees, tree)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error: %w", err)
+		return nil, 0, nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	return trees, nil
+	var next *models.ListCursor
+	if len(trees) > page.Limit {
+		trees = trees[:page.Limit]
+		last := &trees[len(trees)-1]
+		next = treeSort.cursor(page, last, last.ID)
+	}
+
+	return trees, total, next, nil
 }
 
 func (s *Storage) UpdateTree(ctx context.Context, t *models.Tree) error {
 	query := `
 		UPDATE trees
         SET name = $1,
+            allow_same_sex_parents = $2,
             updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $3
 	`
 
 	commandTag, err := s.DB.Exec(ctx, query,
 		t.Name,
+		t.AllowSameSexParents,
 		t.ID,
 	)
 
 	if err != nil {
+		// Однополых родителей запретили, когда они в дереве уже есть
+		if constraintErr := relationshipConstraintError(err); constraintErr != nil {
+			return constraintErr
+		}
 		return fmt.Errorf("update tree: %w", err)
 	}
 
@@ -129,40 +181,32 @@ func (s *Storage) DeleteTree(ctx context.Context, id int) error {
 	return nil
 }
 
-// GetTreeGraph получает все персоны и связи для визуализации дерева
-func (s *Storage) GetTreeGraph(ctx context.Context, treeID int) ([]models.Person, []models.Relationship, error) {
-	// Получаем ТОЛЬКО нужные поля для графа
+// GetTreeGraph получает все персоны, связи и союзы для визуализации дерева
+func (s *Storage) GetTreeGraph(ctx context.Context, treeID int) (*models.TreeGraph, error) {
 	personsQuery := `
-        SELECT id, first_name, last_name, birth_date, death_date, is_male
-        FROM persons
-        WHERE tree_id = $1
-        ORDER BY birth_date ASC
+        SELECT ` + personColumns + `
+        FROM persons p
+        WHERE p.tree_id = $1
+        ORDER BY p.birth_date ASC
     `
 
 	rows, err := s.DB.Query(ctx, personsQuery, treeID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("get persons for graph: %w", err)
+		return nil, fmt.Errorf("get persons for graph: %w", err)
 	}
 	defer rows.Close()
 
-	var persons []models.Person
+	graph := &models.TreeGraph{}
 	for rows.Next() {
 		var person models.Person
-		if err := rows.Scan(
-			&person.ID,
-			&person.FirstName,
-			&person.LastName,
-			&person.BirthDate,
-			&person.DeathDate,
-			&person.IsMale,
-		); err != nil {
-			return nil, nil, fmt.Errorf("scan person: %w", err)
+		if err := rows.Scan(personFields(&person)...); err != nil {
+			return nil, fmt.Errorf("scan person: %w", err)
 		}
-		persons = append(persons, person)
+		graph.Persons = append(graph.Persons, person)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, nil, fmt.Errorf("rows error: %w", err)
+		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
 	// Получаем все связи для персон в этом дереве
@@ -175,11 +219,10 @@ func (s *Storage) GetTreeGraph(ctx context.Context, treeID int) ([]models.Person
 
 	relRows, err := s.DB.Query(ctx, relationshipsQuery, treeID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("get relationships for graph: %w", err)
+		return nil, fmt.Errorf("get relationships for graph: %w", err)
 	}
 	defer relRows.Close()
 
-	var relationships []models.Relationship
 	for relRows.Next() {
 		var rel models.Relationship
 		if err := relRows.Scan(
@@ -188,14 +231,51 @@ func (s *Storage) GetTreeGraph(ctx context.Context, treeID int) ([]models.Person
 			&rel.ChildID,
 			&rel.RelationshipType,
 		); err != nil {
-			return nil, nil, fmt.Errorf("scan relationship: %w", err)
+			return nil, fmt.Errorf("scan relationship: %w", err)
 		}
-		relationships = append(relationships, rel)
+		graph.Relationships = append(graph.Relationships, rel)
 	}
 
 	if err := relRows.Err(); err != nil {
-		return nil, nil, fmt.Errorf("relationships rows error: %w", err)
+		return nil, fmt.Errorf("relationships rows error: %w", err)
+	}
+
+	// Получаем все союзы между персонами этого дерева
+	partnershipsQuery := `
+        SELECT pr.id, pr.person1_id, pr.person2_id, pr.partnership_type, pr.status,
+               pr.start_date, pr.end_date, pr.created_at, pr.updated_at
+        FROM partnerships pr
+        INNER JOIN persons p ON pr.person1_id = p.id
+        WHERE p.tree_id = $1
+    `
+
+	partRows, err := s.DB.Query(ctx, partnershipsQuery, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("get partnerships for graph: %w", err)
+	}
+	defer partRows.Close()
+
+	for partRows.Next() {
+		var p models.Partnership
+		if err := partRows.Scan(
+			&p.ID,
+			&p.Person1ID,
+			&p.Person2ID,
+			&p.Type,
+			&p.Status,
+			&p.StartDate,
+			&p.EndDate,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan partnership: %w", err)
+		}
+		graph.Partnerships = append(graph.Partnerships, p)
+	}
+
+	if err := partRows.Err(); err != nil {
+		return nil, fmt.Errorf("partnerships rows error: %w", err)
 	}
 
-	return persons, relationships, nil
+	return graph, nil
 }
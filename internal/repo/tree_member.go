@@ -0,0 +1,141 @@
+package repo
+
+import (
+	"GenealogyTree/internal/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// AddTreeMember добавляет пользователя в дерево с ролью
+func (s *Storage) AddTreeMember(ctx context.Context, m *models.TreeMember) error {
+	query := `
+        INSERT INTO tree_members (tree_id, user_id, role)
+        VALUES ($1, $2, $3)
+        RETURNING created_at, updated_at
+    `
+
+	err := s.DB.QueryRow(ctx, query,
+		m.TreeID,
+		m.UserID,
+		m.Role,
+	).Scan(&m.CreatedAt, &m.UpdatedAt)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			// 23505 - unique_violation (пользователь уже в дереве)
+			if pgErr.Code == "23505" {
+				return ErrMemberAlreadyExists
+			}
+		}
+		return fmt.Errorf("add tree member: %w", err)
+	}
+
+	return nil
+}
+
+// GetTreeMemberRole получает роль пользователя в дереве
+func (s *Storage) GetTreeMemberRole(ctx context.Context, treeID, userID int) (string, error) {
+	query := `
+        SELECT role
+        FROM tree_members
+        WHERE tree_id = $1 AND user_id = $2
+    `
+
+	var role string
+	err := s.DB.QueryRow(ctx, query, treeID, userID).Scan(&role)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrMemberNotFound
+		}
+		return "", fmt.Errorf("get tree member role: %w", err)
+	}
+
+	return role, nil
+}
+
+// GetTreeMembers получает всех приглашённых участников дерева
+func (s *Storage) GetTreeMembers(ctx context.Context, treeID int) ([]models.TreeMember, error) {
+	query := `
+        SELECT tm.tree_id, tm.user_id, u.email, tm.role, tm.created_at, tm.updated_at
+        FROM tree_members tm
+        INNER JOIN users u ON u.id = tm.user_id
+        WHERE tm.tree_id = $1
+        ORDER BY tm.created_at
+    `
+
+	rows, err := s.DB.Query(ctx, query, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("get tree members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []models.TreeMember
+	for rows.Next() {
+		var m models.TreeMember
+		if err := rows.Scan(
+			&m.TreeID,
+			&m.UserID,
+			&m.Email,
+			&m.Role,
+			&m.CreatedAt,
+			&m.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan tree member: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return members, nil
+}
+
+// UpdateTreeMemberRole меняет роль участника дерева
+func (s *Storage) UpdateTreeMemberRole(ctx context.Context, m *models.TreeMember) error {
+	query := `
+        UPDATE tree_members
+        SET role = $1,
+            updated_at = NOW()
+        WHERE tree_id = $2 AND user_id = $3
+        RETURNING created_at, updated_at
+    `
+
+	err := s.DB.QueryRow(ctx, query,
+		m.Role,
+		m.TreeID,
+		m.UserID,
+	).Scan(&m.CreatedAt, &m.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrMemberNotFound
+		}
+		return fmt.Errorf("update tree member role: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTreeMember отзывает доступ пользователя к дереву
+func (s *Storage) DeleteTreeMember(ctx context.Context, treeID, userID int) error {
+	query := `DELETE FROM tree_members WHERE tree_id = $1 AND user_id = $2`
+
+	commandTag, err := s.DB.Exec(ctx, query, treeID, userID)
+	if err != nil {
+		return fmt.Errorf("delete tree member: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Permission — уровень доступа к дереву, уровни упорядочены по возрастанию
+type Permission int
+
+const (
+	PermissionNone Permission = iota
+	PermissionRead
+	PermissionWrite
+	PermissionAdmin
+	PermissionOwner
+)
+
+// rolePermissions — уровень доступа для каждой роли участника
+var rolePermissions = map[string]Permission{
+	models.RoleViewer: PermissionRead,
+	models.RoleEditor: PermissionWrite,
+	models.RoleAdmin:  PermissionAdmin,
+	models.RoleOwner:  PermissionOwner,
+}
+
+// AccessService решает, что пользователь может делать с деревом и его персонами
+type AccessService struct {
+	repo *repo.Storage
+}
+
+func NewAccessService(storage *repo.Storage) *AccessService {
+	return &AccessService{
+		repo: storage,
+	}
+}
+
+// AuthorizeTree проверяет доступ пользователя к дереву.
+// Чужое дерево неотличимо от несуществующего (ErrTreeNotFound),
+// недостаточный уровень доступа — ErrForbidden.
+func (s *AccessService) AuthorizeTree(ctx context.Context, userID, treeID int, required Permission) (*models.Tree, error) {
+	if treeID <= 0 {
+		return nil, repo.ErrTreeNotFound
+	}
+
+	tree, err := s.repo.GetTreeByID(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("authorize tree: %w", err)
+	}
+
+	role, err := s.role(ctx, tree, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := rolePermissions[role]
+	if granted == PermissionNone {
+		return nil, repo.ErrTreeNotFound
+	}
+	if granted < required {
+		return nil, ErrForbidden
+	}
+
+	tree.Role = role
+	return tree, nil
+}
+
+// AuthorizePerson проверяет доступ пользователя к дереву, в котором находится персона
+func (s *AccessService) AuthorizePerson(ctx context.Context, userID, personID int, required Permission) (*models.Person, *models.Tree, error) {
+	if personID <= 0 {
+		return nil, nil, repo.ErrPersonNotFound
+	}
+
+	person, err := s.repo.GetPersonByID(ctx, personID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("authorize person: %w", err)
+	}
+
+	tree, err := s.AuthorizeTree(ctx, userID, person.TreeID, required)
+	if err != nil {
+		// Персона из чужого дерева — тоже "не найдена"
+		if errors.Is(err, repo.ErrTreeNotFound) {
+			return nil, nil, repo.ErrPersonNotFound
+		}
+		return nil, nil, err
+	}
+
+	return person, tree, nil
+}
+
+// CanSeePrivate — данные живых и закрытых персон видны тем, кто может редактировать дерево.
+// tree должен быть получен через AuthorizeTree (с ролью пользователя).
+func (s *AccessService) CanSeePrivate(tree *models.Tree) bool {
+	return rolePermissions[tree.Role] >= PermissionWrite
+}
+
+// role определяет роль пользователя в дереве ("" — нет доступа)
+func (s *AccessService) role(ctx context.Context, tree *models.Tree, userID int) (string, error) {
+	if tree.OwnerID == userID {
+		return models.RoleOwner, nil
+	}
+
+	role, err := s.repo.GetTreeMemberRole(ctx, tree.ID, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrMemberNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("authorize tree: %w", err)
+	}
+
+	return role, nil
+}
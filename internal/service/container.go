@@ -1,19 +1,53 @@
 package service
 
-import "GenealogyTree/internal/repo"
+import (
+	"GenealogyTree/internal/blob"
+	"GenealogyTree/internal/repo"
+)
 
 type Container struct {
-	Person       *PersonService
-	Tree         *TreeService
-	Relationship *RelationshipService
-	Auth         *AuthService
+	Person           *PersonService
+	Tree             *TreeService
+	Relationship     *RelationshipService
+	Auth             *AuthService
+	Gedcom           *GedcomService
+	Kinship          *KinshipService
+	Partnership      *PartnershipService
+	Access           *AccessService
+	Member           *MemberService
+	Share            *ShareService
+	Privacy          *PrivacyService
+	Event            *EventService
+	Place            *PlaceService
+	Source           *SourceService
+	Media            *MediaService
+	CustomField      *CustomFieldService
+	RelationshipType *RelationshipTypeService
 }
 
-func NewContainer(storage *repo.Storage, jwtSecret string) *Container {
+func NewContainer(storage *repo.Storage, blobs blob.BlobStore, thumbnails *blob.LocalStore, jwtSecret string, livingYears int, maxUploadSize int64) *Container {
+	privacy := NewPrivacyService(livingYears)
+	media := NewMediaService(storage, blobs, thumbnails, maxUploadSize)
+	person := NewPersonService(storage, privacy, media)
+	relationship := NewRelationshipService(storage, privacy)
+
 	return &Container{
-		Person:       NewPersonService(storage),
-		Tree:         NewTreeService(storage),
-		Relationship: NewRelationshipService(storage),
-		Auth:         NewAuthService(storage, jwtSecret),
+		Person:           person,
+		Tree:             NewTreeService(storage),
+		Relationship:     relationship,
+		Auth:             NewAuthService(storage, jwtSecret),
+		Gedcom:           NewGedcomService(storage, person, relationship, privacy),
+		Kinship:          NewKinshipService(storage),
+		Partnership:      NewPartnershipService(storage),
+		Access:           NewAccessService(storage),
+		Member:           NewMemberService(storage),
+		Share:            NewShareService(storage),
+		Privacy:          privacy,
+		Event:            NewEventService(storage),
+		Place:            NewPlaceService(storage),
+		Source:           NewSourceService(storage, privacy),
+		Media:            media,
+		CustomField:      NewCustomFieldService(storage),
+		RelationshipType: NewRelationshipTypeService(storage),
 	}
 }
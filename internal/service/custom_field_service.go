@@ -0,0 +1,248 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+var customFieldTypes = []string{
+	models.FieldTypeString, models.FieldTypeNumber, models.FieldTypeBoolean,
+	models.FieldTypeDate, models.FieldTypeEnum,
+}
+
+// customFieldName — имя поля служит ключом в metadata и в фильтре ?meta.<name>=
+var customFieldName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+type CustomFieldService struct {
+	repo *repo.Storage
+}
+
+func NewCustomFieldService(storage *repo.Storage) *CustomFieldService {
+	return &CustomFieldService{
+		repo: storage,
+	}
+}
+
+// GetCustomFields получает схему пользовательских полей дерева
+func (s *CustomFieldService) GetCustomFields(ctx context.Context, treeID int) ([]models.CustomField, error) {
+	if treeID <= 0 {
+		return nil, errors.New("invalid tree id")
+	}
+
+	fields, err := s.repo.GetCustomFieldsByTreeID(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get custom fields: %w", err)
+	}
+
+	return fields, nil
+}
+
+// GetCustomField получает поле дерева; поле чужого дерева считаем ненайденным
+func (s *CustomFieldService) GetCustomField(ctx context.Context, treeID, fieldID int) (*models.CustomField, error) {
+	if fieldID <= 0 {
+		return nil, errors.New("invalid custom field id")
+	}
+
+	f, err := s.repo.GetCustomFieldByID(ctx, fieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.TreeID != treeID {
+		return nil, repo.ErrCustomFieldNotFound
+	}
+
+	return f, nil
+}
+
+// CreateCustomField добавляет поле в схему дерева
+func (s *CustomFieldService) CreateCustomField(ctx context.Context, f *models.CustomField) (int, error) {
+	if !customFieldName.MatchString(f.Name) {
+		return 0, errors.New("field name must start with a lowercase letter and contain only a-z, 0-9 and _ (up to 50 characters)")
+	}
+	if err := validateCustomFieldType(f); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.CreateCustomField(ctx, f)
+	if err != nil {
+		return 0, fmt.Errorf("service create custom field: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateCustomField меняет тип и допустимые значения поля.
+// Значения, уже сохранённые у персон, должны подходить под новую схему.
+func (s *CustomFieldService) UpdateCustomField(ctx context.Context, treeID int, f *models.CustomField) error {
+	existing, err := s.GetCustomField(ctx, treeID, f.ID)
+	if err != nil {
+		return err
+	}
+
+	f.TreeID = existing.TreeID
+	f.Name = existing.Name
+	f.CreatedAt = existing.CreatedAt
+	if err := validateCustomFieldType(f); err != nil {
+		return err
+	}
+
+	values, err := s.repo.GetMetadataValues(ctx, treeID, f.Name)
+	if err != nil {
+		return fmt.Errorf("service get metadata values: %w", err)
+	}
+	for _, value := range values {
+		if err := checkFieldValue(f, value); err != nil {
+			return fmt.Errorf("existing person data does not fit the new schema: %w", err)
+		}
+	}
+
+	if err := s.repo.UpdateCustomField(ctx, f); err != nil {
+		return fmt.Errorf("service update custom field: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCustomField удаляет поле и его значения у персон дерева
+func (s *CustomFieldService) DeleteCustomField(ctx context.Context, treeID, fieldID int) error {
+	f, err := s.GetCustomField(ctx, treeID, fieldID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteCustomField(ctx, f); err != nil {
+		return fmt.Errorf("service delete custom field: %w", err)
+	}
+
+	return nil
+}
+
+func validateCustomFieldType(f *models.CustomField) error {
+	if !slices.Contains(customFieldTypes, f.Type) {
+		return fmt.Errorf("field type must be one of %v", customFieldTypes)
+	}
+
+	if f.Type != models.FieldTypeEnum {
+		if len(f.AllowedValues) > 0 {
+			return errors.New("allowed values are only supported for enum fields")
+		}
+		return nil
+	}
+
+	if len(f.AllowedValues) == 0 {
+		return errors.New("enum field requires allowed values")
+	}
+	for i, value := range f.AllowedValues {
+		if value == "" {
+			return errors.New("allowed values cannot be empty")
+		}
+		if slices.Contains(f.AllowedValues[:i], value) {
+			return fmt.Errorf("duplicate allowed value %q", value)
+		}
+	}
+
+	return nil
+}
+
+// validateMetadata проверяет метаданные персоны по схеме дерева.
+// Ключи со значением null удаляются: так клиент стирает значение поля.
+func validateMetadata(fields []models.CustomField, metadata map[string]any) error {
+	for key, value := range metadata {
+		if value == nil {
+			delete(metadata, key)
+			continue
+		}
+
+		i := slices.IndexFunc(fields, func(f models.CustomField) bool { return f.Name == key })
+		if i < 0 {
+			return fmt.Errorf("unknown metadata field %q", key)
+		}
+		if err := checkFieldValue(&fields[i], value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkFieldValue проверяет значение из JSON по типу поля
+func checkFieldValue(f *models.CustomField, value any) error {
+	switch f.Type {
+	case models.FieldTypeString:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+	case models.FieldTypeNumber:
+		if _, ok := value.(float64); ok {
+			return nil
+		}
+	case models.FieldTypeBoolean:
+		if _, ok := value.(bool); ok {
+			return nil
+		}
+	case models.FieldTypeDate:
+		if str, ok := value.(string); ok {
+			if _, err := time.Parse(time.DateOnly, str); err == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("field %q must be a date in YYYY-MM-DD format", f.Name)
+	case models.FieldTypeEnum:
+		if str, ok := value.(string); ok && slices.Contains(f.AllowedValues, str) {
+			return nil
+		}
+		return fmt.Errorf("field %q must be one of %v", f.Name, f.AllowedValues)
+	}
+
+	return fmt.Errorf("field %q must be a %s", f.Name, f.Type)
+}
+
+// parseMetadataFilter переводит фильтр из строки запроса в типизированные значения по схеме.
+// Пустое значение означает «поле заполнено».
+func parseMetadataFilter(fields []models.CustomField, filter map[string]string) (map[string]any, []string, error) {
+	match := make(map[string]any)
+	var keys []string
+
+	for key, raw := range filter {
+		i := slices.IndexFunc(fields, func(f models.CustomField) bool { return f.Name == key })
+		if i < 0 {
+			return nil, nil, fmt.Errorf("unknown metadata field %q", key)
+		}
+		f := &fields[i]
+
+		if raw == "" {
+			keys = append(keys, key)
+			continue
+		}
+
+		var value any = raw
+		switch f.Type {
+		case models.FieldTypeNumber:
+			n, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("field %q must be a number", key)
+			}
+			value = n
+		case models.FieldTypeBoolean:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, nil, fmt.Errorf("field %q must be true or false", key)
+			}
+			value = b
+		}
+		if err := checkFieldValue(f, value); err != nil {
+			return nil, nil, err
+		}
+		match[key] = value
+	}
+
+	return match, keys, nil
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"cmp"
+	"slices"
+)
+
+// Оценка пары — сумма весов совпавших признаков, в сумме — 100:
+//   - имя и фамилия — до 40, поровну; точное совпадение даёт полный вес,
+//     совпадение по звучанию или транслитерации — часть веса;
+//   - даты рождения и смерти — полный вес за равные точные даты, 3/5 — за приблизительные,
+//     которые могут совпасть, 1/4 — за годы, разошедшиеся не больше чем на maxDuplicateYearGap;
+//   - общие родители, дети и партнёры — по весу за каждый признак.
+//
+// Пара считается дублем от minDuplicateScore. Исключение — полное совпадение имени и фамилии
+// без противоречащих дат: такие дубли без дат чаще всего появляются после импорта GEDCOM,
+// поэтому для них хватает minSameNameDuplicateScore.
+const (
+	duplicateNameWeight     = 40 // поровну на имя и фамилию
+	duplicateBirthWeight    = 25
+	duplicateDeathWeight    = 15
+	duplicateParentsWeight  = 10
+	duplicateChildrenWeight = 5
+	duplicatePartnersWeight = 5
+)
+
+const (
+	// minDuplicateScore — пары с меньшей оценкой не считаются дублями
+	minDuplicateScore = 50
+	// minSameNameDuplicateScore — порог для пар с одинаковыми именем и фамилией
+	minSameNameDuplicateScore = duplicateNameWeight
+	// maxDuplicateYearGap — на сколько лет могут разойтись точно известные даты у дублей
+	maxDuplicateYearGap = 2
+)
+
+// duplicateCandidate — персона с заранее разобранным именем и родственниками
+type duplicateCandidate struct {
+	person    *models.Person
+	blocks    []string // ключи блоков по возрастанию
+	firstName []nameKey
+	lastName  []nameKey
+	parents   []int
+	children  []int
+	partners  []int
+}
+
+// FindDuplicates ищет в дереве пары персон, похожих на одного человека, и сортирует их
+// по убыванию оценки. Сравниваются только персоны из одного блока — с общим кодом
+// Daitch–Mokotoff фамилии и первого имени, иначе на больших деревьях пар слишком много.
+// Персоны разного известного пола, родители с детьми и партнёры между собой, а также персоны
+// с противоречащими датами дублями не считаются.
+func FindDuplicates(graph *models.TreeGraph) []models.DuplicatePair {
+	family := newFamilyGraph(graph.Relationships)
+	partners := make(map[int][]int)
+	for _, p := range graph.Partnerships {
+		partners[p.Person1ID] = append(partners[p.Person1ID], p.Person2ID)
+		partners[p.Person2ID] = append(partners[p.Person2ID], p.Person1ID)
+	}
+
+	candidates := make([]duplicateCandidate, 0, len(graph.Persons))
+	for i := range graph.Persons {
+		p := &graph.Persons[i]
+		candidates = append(candidates, duplicateCandidate{
+			person:    p,
+			firstName: nameKeys(p.FirstName),
+			lastName:  nameKeys(p.LastName),
+			parents:   family.parents[p.ID],
+			children:  family.children[p.ID],
+			partners:  partners[p.ID],
+		})
+	}
+
+	blocks := make(map[string][]int)
+	for i := range candidates {
+		c := &candidates[i]
+		c.blocks = duplicateBlockKeys(c)
+		for _, key := range c.blocks {
+			blocks[key] = append(blocks[key], i)
+		}
+	}
+
+	var pairs []models.DuplicatePair
+	for key, block := range blocks {
+		for i, a := range block {
+			for _, b := range block[i+1:] {
+				// У имени бывает несколько кодов, и пара может встретиться в нескольких
+				// блоках — сравниваем её только в первом общем
+				if firstSharedBlock(candidates[a].blocks, candidates[b].blocks) != key {
+					continue
+				}
+				if pair, ok := compareCandidates(&candidates[a], &candidates[b]); ok {
+					pairs = append(pairs, pair)
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(pairs, func(a, b models.DuplicatePair) int {
+		if a.Score != b.Score {
+			return b.Score - a.Score
+		}
+		if c := cmp.Compare(a.Person1.ID, b.Person1.ID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Person2.ID, b.Person2.ID)
+	})
+
+	return pairs
+}
+
+// duplicateBlockKeys возвращает ключи блоков персоны: код фамилии и код первого имени.
+// Персоны без имени и фамилии не сравниваются.
+func duplicateBlockKeys(c *duplicateCandidate) []string {
+	if len(c.firstName) == 0 && len(c.lastName) == 0 {
+		return nil
+	}
+
+	lastCodes := []string{""}
+	if len(c.lastName) > 0 {
+		lastCodes = nil
+		for _, word := range c.lastName {
+			lastCodes = append(lastCodes, nameKeyCodes(word)...)
+		}
+	}
+	firstCodes := []string{""}
+	if len(c.firstName) > 0 {
+		firstCodes = nameKeyCodes(c.firstName[0])
+	}
+
+	var keys []string
+	for _, last := range lastCodes {
+		for _, first := range firstCodes {
+			keys = append(keys, last+"/"+first)
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// firstSharedBlock возвращает наименьший общий ключ двух отсортированных списков блоков
+func firstSharedBlock(a, b []string) string {
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			return a[i]
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return ""
+}
+
+// nameKeyCodes — коды слова для блока; слово без кода Daitch–Mokotoff блокируется само по себе
+func nameKeyCodes(k nameKey) []string {
+	if len(k.dm) == 0 {
+		return []string{k.word}
+	}
+	return k.dm
+}
+
+// compareCandidates оценивает пару; false — персоны точно разные или слишком мало похожи.
+// Дешёвые проверки идут первыми: в больших блоках большинство пар отсеивается по полу и датам.
+func compareCandidates(a, b *duplicateCandidate) (models.DuplicatePair, bool) {
+	// Неизвестный пол совместим с любым
+	if a.person.Sex != b.person.Sex && a.person.Sex != models.SexUnknown && b.person.Sex != models.SexUnknown {
+		return models.DuplicatePair{}, false
+	}
+	if slices.Contains(a.parents, b.person.ID) || slices.Contains(a.children, b.person.ID) ||
+		slices.Contains(a.partners, b.person.ID) {
+		return models.DuplicatePair{}, false
+	}
+
+	score := 0
+	var dateReasons []string
+	for _, d := range []struct {
+		a, b                  *models.GenDate
+		weight                int
+		same, matching, close string
+	}{
+		{a.person.BirthDate, b.person.BirthDate, duplicateBirthWeight,
+			models.DuplicateSameBirthDate, models.DuplicateMatchingBirthDate, models.DuplicateCloseBirthDate},
+		{a.person.DeathDate, b.person.DeathDate, duplicateDeathWeight,
+			models.DuplicateSameDeathDate, models.DuplicateMatchingDeathDate, models.DuplicateCloseDeathDate},
+	} {
+		if d.a == nil || d.b == nil {
+			continue
+		}
+		switch {
+		case d.a.IsExact() && d.b.IsExact() && d.a.Start.Equal(d.b.Start):
+			score += d.weight
+			dateReasons = append(dateReasons, d.same)
+		case !d.a.DefinitelyBefore(*d.b) && !d.b.DefinitelyBefore(*d.a):
+			score += d.weight * 3 / 5
+			dateReasons = append(dateReasons, d.matching)
+		case abs(d.a.Start.Year()-d.b.Start.Year()) <= maxDuplicateYearGap:
+			score += d.weight / 4
+			dateReasons = append(dateReasons, d.close)
+		default:
+			// Тёзки из разных поколений
+			return models.DuplicatePair{}, false
+		}
+	}
+
+	// Персоны без фамилии блокируются отдельно от остальных, поэтому фамилия в паре
+	// либо есть у обеих, либо нет у обеих — тогда сравниваются только имена
+	firstScore := nameSimilarity(a.firstName, b.firstName)
+	lastScore := matchExact
+	hasLastName := len(a.lastName) > 0 && len(b.lastName) > 0
+	if hasLastName {
+		lastScore = nameSimilarity(a.lastName, b.lastName)
+	}
+	if firstScore == 0 || lastScore == 0 {
+		return models.DuplicatePair{}, false
+	}
+	score += duplicateNameWeight / 2 * (firstScore + lastScore) / matchExact
+
+	reasons := []string{models.DuplicateSimilarName}
+	if firstScore == matchExact && lastScore == matchExact {
+		reasons[0] = models.DuplicateSameName
+	}
+	reasons = append(reasons, dateReasons...)
+
+	for _, r := range []struct {
+		a, b   []int
+		weight int
+		reason string
+	}{
+		{a.parents, b.parents, duplicateParentsWeight, models.DuplicateSharedParents},
+		{a.children, b.children, duplicateChildrenWeight, models.DuplicateSharedChildren},
+		{a.partners, b.partners, duplicatePartnersWeight, models.DuplicateSharedPartners},
+	} {
+		if slices.ContainsFunc(r.a, func(id int) bool { return slices.Contains(r.b, id) }) {
+			score += r.weight
+			reasons = append(reasons, r.reason)
+		}
+	}
+
+	minScore := minDuplicateScore
+	// Одного имени без фамилии для дубля мало
+	if reasons[0] == models.DuplicateSameName && hasLastName {
+		minScore = minSameNameDuplicateScore
+	}
+	if score < minScore {
+		return models.DuplicatePair{}, false
+	}
+	if a.person.ID > b.person.ID {
+		a, b = b, a
+	}
+	return models.DuplicatePair{
+		Person1: *a.person,
+		Person2: *b.person,
+		Score:   score,
+		Reasons: reasons,
+	}, true
+}
+
+// nameSimilarity — оценка совпадения двух имён 0–100. Берётся лучшее из двух направлений,
+// чтобы "Иван" совпадал с "Иван Петрович".
+func nameSimilarity(a, b []nameKey) int {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	return max(matchWords(a, b), matchWords(b, a))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,9 @@
+package service
+
+import "errors"
+
+// Sentinel errors для сервисов
+var (
+	ErrForbidden     = errors.New("access denied")
+	ErrMergeConflict = errors.New("merge would break tree rules")
+)
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Допустимые типы событий персоны и союза
+var (
+	personEventTypes = []string{
+		models.EventBirth, models.EventBaptism, models.EventDeath, models.EventBurial,
+		models.EventResidence, models.EventOccupation, models.EventEducation,
+		models.EventEmigration, models.EventMilitary, models.EventOther,
+	}
+	partnershipEventTypes = []string{
+		models.EventEngagement, models.EventMarriage, models.EventDivorce, models.EventOther,
+	}
+)
+
+type EventService struct {
+	repo *repo.Storage
+}
+
+func NewEventService(storage *repo.Storage) *EventService {
+	return &EventService{
+		repo: storage,
+	}
+}
+
+// GetEvents получает события персоны и её союзов
+func (s *EventService) GetEvents(ctx context.Context, personID int) ([]models.Event, error) {
+	if personID <= 0 {
+		return nil, errors.New("invalid person id")
+	}
+
+	events, err := s.repo.GetEventsByPersonID(ctx, personID)
+	if err != nil {
+		return nil, fmt.Errorf("service get events: %w", err)
+	}
+
+	return events, nil
+}
+
+// CreateEvent создаёт событие персоны или, если задан e.PartnershipID, её союза.
+// place — название места, используется если e.PlaceID не задан; пустое значение — место неизвестно.
+func (s *EventService) CreateEvent(ctx context.Context, person *models.Person, e *models.Event, place string) (int, error) {
+	if e.PartnershipID != nil {
+		// Союз должен быть союзом этой персоны
+		partnership, err := s.repo.GetPartnershipByID(ctx, *e.PartnershipID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get partnership: %w", err)
+		}
+		if partnership.Person1ID != person.ID && partnership.Person2ID != person.ID {
+			return 0, repo.ErrPartnershipNotFound
+		}
+
+		if !slices.Contains(partnershipEventTypes, e.Type) {
+			return 0, fmt.Errorf("partnership event type must be one of %v", partnershipEventTypes)
+		}
+		e.PersonID = nil
+	} else {
+		if !slices.Contains(personEventTypes, e.Type) {
+			return 0, fmt.Errorf("person event type must be one of %v", personEventTypes)
+		}
+		e.PersonID = &person.ID
+	}
+
+	if err := s.prepareEvent(ctx, person, e, place); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.CreateEvent(ctx, e)
+	if err != nil {
+		return 0, fmt.Errorf("service create event: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateEvent обновляет дату, место и описание события персоны
+func (s *EventService) UpdateEvent(ctx context.Context, person *models.Person, e *models.Event, place string) error {
+	existing, err := s.getPersonEvent(ctx, person.ID, e.ID)
+	if err != nil {
+		return err
+	}
+
+	e.PersonID = existing.PersonID
+	e.PartnershipID = existing.PartnershipID
+	e.Type = existing.Type
+	e.CreatedAt = existing.CreatedAt
+
+	if err := s.prepareEvent(ctx, person, e, place); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateEvent(ctx, e); err != nil {
+		return fmt.Errorf("service update event: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteEvent удаляет событие персоны
+func (s *EventService) DeleteEvent(ctx context.Context, personID, eventID int) error {
+	event, err := s.getPersonEvent(ctx, personID, eventID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteEvent(ctx, event); err != nil {
+		return fmt.Errorf("service delete event: %w", err)
+	}
+
+	return nil
+}
+
+// prepareEvent проверяет даты рождения/смерти и место события
+func (s *EventService) prepareEvent(ctx context.Context, person *models.Person, e *models.Event, place string) error {
+	// Рождение и смерть попадут в персону — проверяем как даты персоны
+	if e.IsVital() && e.Date != nil {
+		birth, death := person.BirthDate, person.DeathDate
+		if e.Type == models.EventBirth {
+			birth = e.Date
+		} else {
+			death = e.Date
+		}
+		if err := validateLifeDates(birth, death); err != nil {
+			return err
+		}
+	}
+
+	// Место задаётся ссылкой (PlaceID) или, если ссылки нет, названием
+	var p *models.Place
+	var err error
+	switch {
+	case e.PlaceID != nil:
+		p, err = getTreePlace(ctx, s.repo, person.TreeID, *e.PlaceID)
+	case strings.TrimSpace(place) != "":
+		p, err = s.repo.GetOrCreatePlace(ctx, person.TreeID, strings.TrimSpace(place))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get place: %w", err)
+	}
+
+	e.PlaceID, e.Place = nil, ""
+	if p != nil {
+		e.PlaceID, e.Place = &p.ID, p.Name
+	}
+
+	return nil
+}
+
+// getPersonEvent получает событие и проверяет что оно принадлежит персоне или её союзу
+func (s *EventService) getPersonEvent(ctx context.Context, personID, eventID int) (*models.Event, error) {
+	if personID <= 0 || eventID <= 0 {
+		return nil, errors.New("invalid person or event id")
+	}
+
+	event, err := s.repo.GetEventByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.PersonID != nil {
+		if *event.PersonID != personID {
+			return nil, repo.ErrEventNotFound
+		}
+		return event, nil
+	}
+
+	// Событие союза: персона должна в нём участвовать
+	partnership, err := s.repo.GetPartnershipByID(ctx, *event.PartnershipID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partnership: %w", err)
+	}
+	if partnership.Person1ID != personID && partnership.Person2ID != personID {
+		return nil, repo.ErrEventNotFound
+	}
+
+	return event, nil
+}
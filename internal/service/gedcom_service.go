@@ -0,0 +1,581 @@
+package service
+
+import (
+	"GenealogyTree/internal/gedcom"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// GedcomService импортирует и экспортирует деревья в формате GEDCOM
+type GedcomService struct {
+	repo          *repo.Storage
+	persons       *PersonService
+	relationships *RelationshipService
+	privacy       *PrivacyService
+}
+
+func NewGedcomService(storage *repo.Storage, persons *PersonService, relationships *RelationshipService, privacy *PrivacyService) *GedcomService {
+	return &GedcomService{
+		repo:          storage,
+		persons:       persons,
+		relationships: relationships,
+		privacy:       privacy,
+	}
+}
+
+// Import разбирает GEDCOM и сохраняет INDI/FAM в дерево одной транзакцией.
+// Записи, не прошедшие валидацию, пропускаются и попадают в отчёт.
+func (s *GedcomService) Import(ctx context.Context, treeID int, r io.Reader) (*models.ImportReport, error) {
+	if treeID <= 0 {
+		return nil, errors.New("invalid tree id")
+	}
+
+	doc, err := gedcom.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse gedcom: %w", err)
+	}
+
+	report := &models.ImportReport{
+		Skipped:  []models.ImportIssue{},
+		Failures: []models.ImportIssue{},
+		Warnings: []models.ImportIssue{},
+	}
+
+	if charset := doc.Charset(); charset != "UTF-8" && charset != "UTF8" && charset != "ASCII" {
+		report.Warnings = append(report.Warnings, models.ImportIssue{
+			Line:    doc.Header.Line,
+			Message: fmt.Sprintf("charset %s is not supported, text is read as UTF-8", charset),
+		})
+	}
+
+	for _, rec := range doc.Records {
+		if rec.Tag != "INDI" && rec.Tag != "FAM" && rec.Tag != "NOTE" && rec.Tag != "SNOTE" {
+			report.Skipped = append(report.Skipped, models.ImportIssue{
+				XRef:    rec.XRef,
+				Line:    rec.Line,
+				Message: fmt.Sprintf("record type %s is not supported", rec.Tag),
+			})
+		}
+	}
+
+	families := doc.Families()
+	husbands := make(map[string]bool)
+	for _, fam := range families {
+		if fam.Husband != "" {
+			husbands[fam.Husband] = true
+		}
+	}
+
+	// 1. Персоны
+	individuals := doc.Individuals()
+	persons := make([]*models.Person, 0, len(individuals))
+	byXRef := make(map[string]*models.Person, len(individuals))
+	pedigrees := make(map[string]map[string]string) // xref ребенка → семья → PEDI
+	familyChildren := make(map[string][]string)     // семья → дети из INDI.FAMC
+
+	for _, ind := range individuals {
+		person, warnings := s.personFromIndividual(treeID, ind, husbands[ind.XRef])
+		report.Warnings = append(report.Warnings, warnings...)
+
+		if err := s.persons.validatePerson(ctx, person); err != nil {
+			report.Skipped = append(report.Skipped, models.ImportIssue{
+				XRef:    ind.XRef,
+				Line:    ind.Line,
+				Message: err.Error(),
+			})
+			continue
+		}
+
+		// Временный ID, чтобы правила связей различали ещё не сохранённых персон
+		person.ID = -(len(persons) + 1)
+		persons = append(persons, person)
+		if ind.XRef != "" {
+			byXRef[ind.XRef] = person
+		}
+
+		for _, famc := range ind.FamC {
+			if pedigrees[ind.XRef] == nil {
+				pedigrees[ind.XRef] = make(map[string]string)
+			}
+			pedigrees[ind.XRef][famc.Family] = famc.Pedigree
+			familyChildren[famc.Family] = append(familyChildren[famc.Family], ind.XRef)
+		}
+	}
+
+	// 2. Связи родитель-ребенок из семей — по тем же правилам, что и AddChild
+	rules, err := loadLinkRules(ctx, s.repo, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service import gedcom: %w", err)
+	}
+
+	var links []repo.ImportLink
+	parentsOf := make(map[*models.Person][]models.ParentLink)
+	graph := newFamilyGraph(nil)
+
+	for _, fam := range families {
+		children := fam.Children
+		for _, childXRef := range familyChildren[fam.XRef] {
+			if !slices.Contains(children, childXRef) {
+				children = append(children, childXRef)
+			}
+		}
+
+		for _, parentXRef := range []string{fam.Husband, fam.Wife} {
+			if parentXRef == "" {
+				continue
+			}
+			parent, ok := byXRef[parentXRef]
+			if !ok {
+				report.Failures = append(report.Failures, models.ImportIssue{
+					XRef:    fam.XRef,
+					Line:    fam.Line,
+					Message: fmt.Sprintf("parent @%s@ was not imported", parentXRef),
+				})
+				continue
+			}
+
+			for _, childXRef := range children {
+				child, ok := byXRef[childXRef]
+				if !ok {
+					report.Failures = append(report.Failures, models.ImportIssue{
+						XRef:    fam.XRef,
+						Line:    fam.Line,
+						Message: fmt.Sprintf("child @%s@ was not imported", childXRef),
+					})
+					continue
+				}
+
+				relType := relationshipTypeFromPedigree(pedigrees[childXRef][fam.XRef])
+
+				err := s.relationships.validateLink(parent, child, relType, parentsOf[child], rules)
+				if err == nil && graph.isAncestor(child.ID, parent.ID) {
+					err = repo.ErrRelationshipCycle
+				}
+				if err != nil {
+					report.Failures = append(report.Failures, models.ImportIssue{
+						XRef:    fam.XRef,
+						Line:    fam.Line,
+						Message: fmt.Sprintf("@%s@ → @%s@: %v", parentXRef, childXRef, err),
+					})
+					continue
+				}
+
+				parentsOf[child] = append(parentsOf[child], models.ParentLink{Parent: *parent, RelationshipType: relType})
+				graph.addEdge(parent.ID, child.ID)
+				links = append(links, repo.ImportLink{
+					Parent:           parent,
+					Child:            child,
+					RelationshipType: relType,
+				})
+			}
+		}
+	}
+
+	// 3. Сохраняем всё одной транзакцией
+	if err := s.repo.ImportPersons(ctx, persons, links); err != nil {
+		return nil, fmt.Errorf("service import gedcom: %w", err)
+	}
+
+	report.PersonsImported = len(persons)
+	report.RelationshipsImported = len(links)
+
+	return report, nil
+}
+
+// personFromIndividual переносит поля INDI в персону
+func (s *GedcomService) personFromIndividual(treeID int, ind gedcom.Individual, isHusband bool) (*models.Person, []models.ImportIssue) {
+	var warnings []models.ImportIssue
+	warn := func(format string, args ...any) {
+		warnings = append(warnings, models.ImportIssue{
+			XRef:    ind.XRef,
+			Line:    ind.Line,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	person := &models.Person{
+		FirstName: ind.GivenName,
+		LastName:  ind.Surname,
+		Biography: ind.Note,
+		TreeID:    treeID,
+	}
+
+	if person.FirstName == "" {
+		person.FirstName = unknownName
+		warn("given name is missing, imported as %q", unknownName)
+	}
+	if person.LastName == "" {
+		person.LastName = unknownName
+		warn("surname is missing, imported as %q", unknownName)
+	}
+
+	switch {
+	case ind.Sex == "M":
+		person.Sex = models.SexMale
+	case ind.Sex == "F":
+		person.Sex = models.SexFemale
+	case ind.Sex == "X":
+		person.Sex = models.SexOther
+	case isHusband:
+		// Пол не указан, но персона — HUSB в семье
+		person.Sex = models.SexMale
+	case ind.Sex == "" || ind.Sex == "U":
+		person.Sex = models.SexUnknown
+	default:
+		person.Sex = models.SexUnknown
+		warn("sex %q is not supported, imported as unknown", ind.Sex)
+	}
+
+	if ind.BirthDate != "" {
+		if date, err := models.ParseGenDate(ind.BirthDate); err == nil {
+			person.BirthDate = &date
+		} else {
+			warn("birth date ignored: %v", err)
+		}
+	}
+	if ind.DeathDate != "" {
+		if date, err := models.ParseGenDate(ind.DeathDate); err == nil {
+			person.DeathDate = &date
+		} else {
+			warn("death date ignored: %v", err)
+		}
+	}
+
+	return person, warnings
+}
+
+// unknownName подставляется вместо отсутствующих имени или фамилии
+const unknownName = "Unknown"
+
+// relationshipTypeFromPedigree переводит PEDI из GEDCOM в тип связи
+func relationshipTypeFromPedigree(pedigree string) string {
+	switch strings.ToLower(pedigree) {
+	case "", "birth":
+		return models.RelationshipBiological
+	case "adopted":
+		return models.RelationshipAdoptive
+	case "foster":
+		return models.RelationshipFoster
+	default:
+		return models.RelationshipNotBiological
+	}
+}
+
+// exportFamily — семья для экспорта, собранная из общих родителей детей
+type exportFamily struct {
+	xref        string
+	parents     []int
+	children    []int
+	partnership *models.Partnership
+}
+
+// Export пишет всё дерево в GEDCOM 5.5.1: INDI на каждую персону и FAM,
+// собранные из детей с одинаковым набором родителей.
+// redact скрывает данные живых и закрытых персон.
+func (s *GedcomService) Export(ctx context.Context, treeID int, w io.Writer, redact bool) error {
+	if treeID <= 0 {
+		return errors.New("invalid tree id")
+	}
+
+	tree, err := s.repo.GetTreeByID(ctx, treeID)
+	if err != nil {
+		return fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	treeGraph, err := s.repo.GetTreeGraph(ctx, treeID)
+	if err != nil {
+		return fmt.Errorf("service export gedcom: %w", err)
+	}
+
+	if redact {
+		s.privacy.RedactGraph(treeGraph)
+	}
+
+	types, err := loadRelationshipTypes(ctx, s.repo, treeID)
+	if err != nil {
+		return err
+	}
+
+	persons := treeGraph.Persons
+	families, famc, fams := buildExportFamilies(persons, treeGraph.Relationships, treeGraph.Partnerships, types)
+
+	gw := gedcom.NewWriter(w)
+
+	gw.Line(0, "", "HEAD", "")
+	gw.Line(1, "", "SOUR", "GenealogyTree")
+	gw.Line(2, "", "NAME", "Genealogy Tree API")
+	gw.Line(1, "", "DATE", gedcom.FormatDate(time.Now()))
+	gw.Pointer(1, "SUBM", "U1")
+	gw.Line(1, "", "GEDC", "")
+	gw.Line(2, "", "VERS", "5.5.1")
+	gw.Line(2, "", "FORM", "LINEAGE-LINKED")
+	gw.Line(1, "", "CHAR", "UTF-8")
+	gw.Line(1, "", "NOTE", tree.Name)
+
+	gw.Line(0, "U1", "SUBM", "")
+	gw.Line(1, "", "NAME", "Genealogy Tree API")
+
+	for _, p := range persons {
+		xref := personXRef(p.ID)
+		gw.Line(0, xref, "INDI", "")
+		gw.Line(1, "", "NAME", fmt.Sprintf("%s /%s/", p.FirstName, p.LastName))
+		gw.Line(2, "", "GIVN", p.FirstName)
+		gw.Line(2, "", "SURN", p.LastName)
+		gw.Line(1, "", "SEX", gedcomSex(p.Sex))
+		if p.BirthDate != nil {
+			gw.Line(1, "", "BIRT", "")
+			gw.Line(2, "", "DATE", p.BirthDate.String())
+		}
+		if p.DeathDate != nil {
+			gw.Line(1, "", "DEAT", "")
+			gw.Line(2, "", "DATE", p.DeathDate.String())
+		}
+		if p.Biography != "" {
+			gw.Line(1, "", "NOTE", p.Biography)
+		}
+		for _, link := range famc[p.ID] {
+			gw.Pointer(1, "FAMC", link.family)
+			if pedigree := pedigreeFromRelationshipType(link.relType); pedigree != "" {
+				gw.Line(2, "", "PEDI", pedigree)
+			}
+		}
+		for _, family := range fams[p.ID] {
+			gw.Pointer(1, "FAMS", family)
+		}
+	}
+
+	sexByID := make(map[int]string, len(persons))
+	for _, p := range persons {
+		sexByID[p.ID] = p.Sex
+	}
+
+	for _, family := range families {
+		gw.Line(0, family.xref, "FAM", "")
+		husband, wife := splitCouple(family.parents, sexByID)
+		if husband != 0 {
+			gw.Pointer(1, "HUSB", personXRef(husband))
+		}
+		if wife != 0 {
+			gw.Pointer(1, "WIFE", personXRef(wife))
+		}
+		if family.partnership != nil {
+			writePartnershipEvents(gw, family.partnership)
+		}
+		for _, childID := range family.children {
+			gw.Pointer(1, "CHIL", personXRef(childID))
+		}
+	}
+
+	gw.Line(0, "", "TRLR", "")
+
+	return gw.Flush()
+}
+
+// familyLink — ссылка INDI.FAMC на синтезированную семью
+type familyLink struct {
+	family  string
+	relType string
+}
+
+// buildExportFamilies группирует детей по набору родителей и добавляет союзы:
+// союз попадает в семью с теми же двумя родителями или становится семьёй без детей.
+// В FAM помещается не больше двух родителей, поэтому у ребенка с большим числом родителей
+// (отчим, опекун и т.п. сверх лимита) основная семья — первые два родителя, входящие
+// в лимит, а каждый следующий родитель даёт ребенку отдельную семью из одного родителя.
+// Возвращает семьи, ссылки FAMC по ребенку и FAMS по родителю.
+func buildExportFamilies(persons []models.Person, relationships []models.Relationship, partnerships []models.Partnership, types relationshipTypes) ([]*exportFamily, map[int][]familyLink, map[int][]string) {
+	exists := make(map[int]bool, len(persons))
+	for _, p := range persons {
+		exists[p.ID] = true
+	}
+
+	parentsOf := make(map[int][]models.Relationship)
+	var childOrder []int
+	for _, rel := range relationships {
+		if !exists[rel.ParentID] || !exists[rel.ChildID] {
+			continue
+		}
+		if _, seen := parentsOf[rel.ChildID]; !seen {
+			childOrder = append(childOrder, rel.ChildID)
+		}
+		parentsOf[rel.ChildID] = append(parentsOf[rel.ChildID], rel)
+	}
+
+	var families []*exportFamily
+	byKey := make(map[string]*exportFamily)
+	famc := make(map[int][]familyLink)
+	fams := make(map[int][]string)
+
+	for _, childID := range childOrder {
+		rels := parentsOf[childID]
+		// Родители, входящие в лимит, — первыми
+		slices.SortStableFunc(rels, func(a, b models.Relationship) int {
+			countsA, countsB := types.countsTowardParents(a.RelationshipType), types.countsTowardParents(b.RelationshipType)
+			switch {
+			case countsA == countsB:
+				return 0
+			case countsA:
+				return -1
+			default:
+				return 1
+			}
+		})
+
+		groups := [][]models.Relationship{rels[:min(len(rels), 2)]}
+		for i := 2; i < len(rels); i++ {
+			groups = append(groups, rels[i:i+1])
+		}
+
+		for _, group := range groups {
+			parents := make([]int, 0, len(group))
+			for _, rel := range group {
+				parents = append(parents, rel.ParentID)
+			}
+			slices.Sort(parents)
+
+			key := fmt.Sprint(parents)
+			family, ok := byKey[key]
+			if !ok {
+				family = &exportFamily{
+					xref:    fmt.Sprintf("F%d", len(families)+1),
+					parents: parents,
+				}
+				families = append(families, family)
+				byKey[key] = family
+				for _, parentID := range parents {
+					fams[parentID] = append(fams[parentID], family.xref)
+				}
+			}
+
+			// В GEDCOM тип родства задаётся для ребенка в семье целиком
+			relType := models.RelationshipBiological
+			for _, rel := range group {
+				if rel.RelationshipType != models.RelationshipBiological {
+					relType = rel.RelationshipType
+				}
+			}
+			family.children = append(family.children, childID)
+			famc[childID] = append(famc[childID], familyLink{family: family.xref, relType: relType})
+		}
+	}
+
+	for i := range partnerships {
+		partnership := &partnerships[i]
+		if !exists[partnership.Person1ID] || !exists[partnership.Person2ID] {
+			continue
+		}
+
+		// Person1ID < Person2ID, поэтому ключ совпадает с отсортированным набором родителей
+		parents := []int{partnership.Person1ID, partnership.Person2ID}
+		key := fmt.Sprint(parents)
+		family, ok := byKey[key]
+		if !ok || family.partnership != nil {
+			family = &exportFamily{
+				xref:    fmt.Sprintf("F%d", len(families)+1),
+				parents: parents,
+			}
+			families = append(families, family)
+			if !ok {
+				byKey[key] = family
+			}
+			for _, parentID := range parents {
+				fams[parentID] = append(fams[parentID], family.xref)
+			}
+		}
+		family.partnership = partnership
+	}
+
+	return families, famc, fams
+}
+
+// writePartnershipEvents пишет события союза в FAM: ENGA, MARR, DIV
+func writePartnershipEvents(gw *gedcom.Writer, p *models.Partnership) {
+	event := func(tag string, date *time.Time) {
+		gw.Line(1, "", tag, "")
+		if date != nil {
+			gw.Line(2, "", "DATE", gedcom.FormatDate(*date))
+		}
+	}
+
+	switch p.Type {
+	case "engagement":
+		event("ENGA", p.StartDate)
+		return
+	case "co_parents":
+		// Совместное родительство выражается самой семьёй, без событий
+		return
+	case "marriage":
+		event("MARR", p.StartDate)
+	default:
+		// Прочие союзы GEDCOM 5.5.1 описывает как MARR с уточнённым TYPE
+		event("MARR", p.StartDate)
+		gw.Line(2, "", "TYPE", strings.ReplaceAll(p.Type, "_", " "))
+	}
+
+	switch p.Status {
+	case "divorced", "annulled":
+		event("DIV", p.EndDate)
+	}
+}
+
+// splitCouple раскладывает родителей семьи (не больше двух) на HUSB и WIFE: сначала по полу,
+// затем родители неизвестного или одинакового пола занимают свободные места
+func splitCouple(parents []int, sex map[int]string) (husband, wife int) {
+	var rest []int
+	for _, id := range parents {
+		switch {
+		case sex[id] == models.SexMale && husband == 0:
+			husband = id
+		case sex[id] == models.SexFemale && wife == 0:
+			wife = id
+		default:
+			rest = append(rest, id)
+		}
+	}
+	for _, id := range rest {
+		switch {
+		case husband == 0:
+			husband = id
+		case wife == 0:
+			wife = id
+		}
+	}
+	return husband, wife
+}
+
+// gedcomSex переводит пол в SEX. В GEDCOM 5.5.1 нет значения для иного пола
+// (X появился только в GEDCOM 7), поэтому он выгружается как U.
+func gedcomSex(sex string) string {
+	switch sex {
+	case models.SexMale:
+		return "M"
+	case models.SexFemale:
+		return "F"
+	default:
+		return "U"
+	}
+}
+
+// pedigreeFromRelationshipType переводит тип связи в PEDI (пусто для кровного родства).
+// Для типов без аналога в GEDCOM 5.5.1 остаётся adopted, как и для not_biological.
+func pedigreeFromRelationshipType(relType string) string {
+	switch relType {
+	case models.RelationshipBiological:
+		return ""
+	case models.RelationshipFoster:
+		return "foster"
+	default:
+		return "adopted"
+	}
+}
+
+func personXRef(id int) string {
+	return fmt.Sprintf("I%d", id)
+}
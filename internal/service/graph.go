@@ -0,0 +1,157 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"slices"
+)
+
+// familyGraph — связи родитель-ребенок дерева в памяти
+type familyGraph struct {
+	parents  map[int][]int
+	children map[int][]int
+}
+
+func newFamilyGraph(relationships []models.Relationship) *familyGraph {
+	g := &familyGraph{
+		parents:  make(map[int][]int),
+		children: make(map[int][]int),
+	}
+	for _, rel := range relationships {
+		g.addEdge(rel.ParentID, rel.ChildID)
+	}
+	return g
+}
+
+// addEdge добавляет связь родитель → ребенок
+func (g *familyGraph) addEdge(parentID, childID int) {
+	g.parents[childID] = append(g.parents[childID], parentID)
+	g.children[parentID] = append(g.children[parentID], childID)
+}
+
+// isAncestor проверяет, является ли ancestorID предком personID
+func (g *familyGraph) isAncestor(ancestorID, personID int) bool {
+	dist, _ := g.ancestors(personID)
+	_, ok := dist[ancestorID]
+	return ok && ancestorID != personID
+}
+
+// ancestors обходит предков в ширину, включая саму персону (расстояние 0).
+// next[x] — следующая персона на кратчайшем пути от x обратно к start.
+func (g *familyGraph) ancestors(start int) (dist map[int]int, next map[int]int) {
+	dist = map[int]int{start: 0}
+	next = make(map[int]int)
+	queue := []int{start}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, parent := range g.parents[current] {
+			if _, seen := dist[parent]; seen {
+				continue
+			}
+			dist[parent] = dist[current] + 1
+			next[parent] = current
+			queue = append(queue, parent)
+		}
+	}
+
+	return dist, next
+}
+
+// cycles находит циклы в связях (персона оказывается собственным предком).
+// Для каждой сильно связной компоненты возвращается один конкретный цикл:
+// каждый следующий ID — ребенок предыдущего, последний — родитель первого.
+func (g *familyGraph) cycles() [][]int {
+	var nodes []int
+	for id := range g.children {
+		nodes = append(nodes, id)
+	}
+	slices.Sort(nodes)
+
+	// Алгоритм Тарьяна
+	index := make(map[int]int)
+	lowlink := make(map[int]int)
+	onStack := make(map[int]bool)
+	var stack []int
+	var components [][]int
+	counter := 0
+
+	var strongConnect func(v int)
+	strongConnect = func(v int) {
+		index[v] = counter
+		lowlink[v] = counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range g.children[v] {
+			if _, visited := index[w]; !visited {
+				strongConnect(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], index[w])
+			}
+		}
+
+		if lowlink[v] == index[v] {
+			var component []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			if len(component) > 1 || slices.Contains(g.children[v], v) {
+				components = append(components, component)
+			}
+		}
+	}
+
+	for _, v := range nodes {
+		if _, visited := index[v]; !visited {
+			strongConnect(v)
+		}
+	}
+
+	result := make([][]int, 0, len(components))
+	for _, component := range components {
+		result = append(result, g.cycleWithin(component))
+	}
+	return result
+}
+
+// cycleWithin ищет кратчайший цикл через минимальный ID компоненты
+func (g *familyGraph) cycleWithin(component []int) []int {
+	inComponent := make(map[int]bool, len(component))
+	for _, id := range component {
+		inComponent[id] = true
+	}
+	start := slices.Min(component)
+
+	prev := make(map[int]int)
+	queue := []int{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range g.children[current] {
+			if child == start {
+				cycle := []int{current}
+				for id := current; id != start; {
+					id = prev[id]
+					cycle = append(cycle, id)
+				}
+				slices.Reverse(cycle)
+				return cycle
+			}
+			if _, seen := prev[child]; seen || !inComponent[child] {
+				continue
+			}
+			prev[child] = current
+			queue = append(queue, child)
+		}
+	}
+	return []int{start}
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// KinshipService вычисляет степень родства между персонами дерева
+type KinshipService struct {
+	repo *repo.Storage
+}
+
+func NewKinshipService(storage *repo.Storage) *KinshipService {
+	return &KinshipService{
+		repo: storage,
+	}
+}
+
+// GetKinship находит ближайших общих предков A и B и называет родство B по отношению к A
+func (s *KinshipService) GetKinship(ctx context.Context, treeID, personAID, personBID int) (*models.Kinship, error) {
+	if treeID <= 0 {
+		return nil, errors.New("invalid tree id")
+	}
+	if personAID <= 0 || personBID <= 0 {
+		return nil, errors.New("invalid person id")
+	}
+
+	treeGraph, err := s.repo.GetTreeGraph(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get kinship: %w", err)
+	}
+
+	byID := make(map[int]*models.Person, len(treeGraph.Persons))
+	for i := range treeGraph.Persons {
+		byID[treeGraph.Persons[i].ID] = &treeGraph.Persons[i]
+	}
+
+	// Обе персоны должны быть в этом дереве
+	personB, ok := byID[personBID]
+	if _, okA := byID[personAID]; !okA || !ok {
+		return nil, repo.ErrPersonNotFound
+	}
+
+	// Родство кровное: отчимы, опекуны, приёмные родители и т.п. в расчёт не входят
+	types, err := loadRelationshipTypes(ctx, s.repo, treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := newFamilyGraph(types.blood(treeGraph.Relationships))
+	return graph.kinship(personAID, personB), nil
+}
+
+// kinship вычисляет родство по графу в памяти
+func (g *familyGraph) kinship(personAID int, personB *models.Person) *models.Kinship {
+	result := &models.Kinship{
+		PersonAID:       personAID,
+		PersonBID:       personB.ID,
+		CommonAncestors: []int{},
+		Path:            []int{},
+	}
+
+	distA, nextA := g.ancestors(personAID)
+	distB, nextB := g.ancestors(personB.ID)
+
+	// Ближайшие общие предки: минимальная сумма поколений, при равенстве — меньше шагов от A
+	best := -1
+	var common []int
+	for id, da := range distA {
+		db, ok := distB[id]
+		if !ok {
+			continue
+		}
+		if best < 0 || da+db < best || (da+db == best && da < distA[common[0]]) {
+			best = da + db
+			common = []int{id}
+		} else if da+db == best && da == distA[common[0]] {
+			common = append(common, id)
+		}
+	}
+
+	if best < 0 {
+		return result
+	}
+	slices.Sort(common)
+
+	up, down := distA[common[0]], distB[common[0]]
+	half := g.isHalfRelation(common, nextA, nextB, up, down)
+
+	result.Related = true
+	result.GenerationsA = up
+	result.GenerationsB = down
+	result.CommonAncestors = common
+	result.Relationship = kinshipName(up, down, personB.Sex, half)
+	result.Path = kinshipPath(common[0], personAID, personB.ID, nextA, nextB)
+
+	return result
+}
+
+// isHalfRelation — линии сходятся только на одном предке, а вторые родители
+// детей этого предка известны и различаются
+func (g *familyGraph) isHalfRelation(common []int, nextA, nextB map[int]int, up, down int) bool {
+	if up == 0 || down == 0 || len(common) != 1 {
+		return false
+	}
+
+	childA, childB := nextA[common[0]], nextB[common[0]]
+	parentsA, parentsB := g.parents[childA], g.parents[childB]
+	if len(parentsA) < 2 || len(parentsB) < 2 {
+		return false
+	}
+
+	shared := 0
+	for _, p := range parentsA {
+		if slices.Contains(parentsB, p) {
+			shared++
+		}
+	}
+	return shared == 1
+}
+
+// kinshipPath строит путь A → ... → общий предок → ... → B
+func kinshipPath(ancestorID, personAID, personBID int, nextA, nextB map[int]int) []int {
+	var up []int
+	for id := ancestorID; ; id = nextA[id] {
+		up = append(up, id)
+		if id == personAID {
+			break
+		}
+	}
+	slices.Reverse(up)
+
+	path := up
+	for id := ancestorID; id != personBID; {
+		id = nextB[id]
+		path = append(path, id)
+	}
+	return path
+}
+
+// kinshipName называет, кем B приходится A.
+// up — поколений от A до общего предка, down — от B до общего предка.
+// Для неизвестного и иного пола B родство называется без рода.
+func kinshipName(up, down int, sex string, half bool) string {
+	gendered := func(male, female, neutral string) string {
+		switch sex {
+		case models.SexMale:
+			return male
+		case models.SexFemale:
+			return female
+		default:
+			return neutral
+		}
+	}
+	halfPrefix := ""
+	if half {
+		halfPrefix = "half-"
+	}
+
+	switch {
+	case up == 0 && down == 0:
+		return "self"
+
+	// B — потомок A
+	case up == 0:
+		switch down {
+		case 1:
+			return gendered("son", "daughter", "child")
+		default:
+			return greatPrefix(down-2) + gendered("grandson", "granddaughter", "grandchild")
+		}
+
+	// B — предок A
+	case down == 0:
+		switch up {
+		case 1:
+			return gendered("father", "mother", "parent")
+		default:
+			return greatPrefix(up-2) + gendered("grandfather", "grandmother", "grandparent")
+		}
+
+	case up == 1 && down == 1:
+		return halfPrefix + gendered("brother", "sister", "sibling")
+
+	// B — потомок брата/сестры A
+	case up == 1:
+		return halfPrefix + greatPrefix(down-2) + gendered("nephew", "niece", "nephew or niece")
+
+	// B — брат/сестра предка A
+	case down == 1:
+		return halfPrefix + greatPrefix(up-2) + gendered("uncle", "aunt", "uncle or aunt")
+	}
+
+	degree := min(up, down) - 1
+	name := halfPrefix + ordinalWord(degree) + " cousin"
+	switch removed := max(up, down) - min(up, down); removed {
+	case 0:
+	case 1:
+		name += " once removed"
+	case 2:
+		name += " twice removed"
+	default:
+		name += fmt.Sprintf(" %d times removed", removed)
+	}
+	return name
+}
+
+// greatPrefix: 0 → "", 1 → "great-", 2 → "2nd great-", 3 → "3rd great-"...
+func greatPrefix(n int) string {
+	switch {
+	case n <= 0:
+		return ""
+	case n == 1:
+		return "great-"
+	default:
+		return ordinalNumber(n) + " great-"
+	}
+}
+
+var ordinalWords = []string{"", "first", "second", "third", "fourth", "fifth", "sixth", "seventh", "eighth", "ninth", "tenth"}
+
+// ordinalWord: 1 → "first", 2 → "second"... дальше десятого — цифрами
+func ordinalWord(n int) string {
+	if n > 0 && n < len(ordinalWords) {
+		return ordinalWords[n]
+	}
+	return ordinalNumber(n)
+}
+
+// ordinalNumber: 1 → "1st", 2 → "2nd", 11 → "11th", 23 → "23rd"
+func ordinalNumber(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
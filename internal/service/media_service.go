@@ -0,0 +1,394 @@
+package service
+
+import (
+	"GenealogyTree/internal/blob"
+	"GenealogyTree/internal/imaging"
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Типы файлов, которые можно прикрепить к персоне, и расширения ключей в хранилище
+var mediaExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+type MediaService struct {
+	repo          *repo.Storage
+	blobs         blob.BlobStore   // оригиналы
+	thumbnails    *blob.LocalStore // превью на локальном диске; пропавшие строятся заново
+	maxUploadSize int64
+}
+
+func NewMediaService(storage *repo.Storage, blobs blob.BlobStore, thumbnails *blob.LocalStore, maxUploadSize int64) *MediaService {
+	return &MediaService{
+		repo:          storage,
+		blobs:         blobs,
+		thumbnails:    thumbnails,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+// MaxUploadSize — предельный размер одного файла в байтах
+func (s *MediaService) MaxUploadSize() int64 {
+	return s.maxUploadSize
+}
+
+// UploadMedia сохраняет файл в хранилище и прикрепляет его к персоне.
+// Тип определяется по содержимому, а не по имени файла.
+// Первое загруженное фото JPEG/PNG становится портретом персоны.
+func (s *MediaService) UploadMedia(ctx context.Context, person *models.Person, content io.ReadSeeker, size int64, fileName, title string) (*models.Media, error) {
+	m, err := s.storeMedia(ctx, person, content, size, fileName, title)
+	if err != nil {
+		return nil, err
+	}
+
+	if person.PortraitID == nil && m.HasThumbnails() {
+		if err := s.repo.SetPersonPortrait(ctx, person.ID, &m.ID); err != nil {
+			return nil, fmt.Errorf("service set portrait: %w", err)
+		}
+		person.PortraitID = &m.ID
+	}
+
+	return m, nil
+}
+
+// UploadPortrait загружает фото JPEG/PNG и делает его основным портретом персоны.
+// Если это фото уже прикреплено к персоне, портретом становится существующий файл.
+func (s *MediaService) UploadPortrait(ctx context.Context, person *models.Person, content io.ReadSeeker, size int64, fileName string) (*models.Media, error) {
+	mimeType, err := detectMimeType(content)
+	if err != nil {
+		return nil, err
+	}
+	if !models.IsThumbnailMimeType(mimeType) {
+		return nil, errors.New("portrait must be a JPEG or PNG image")
+	}
+
+	m, err := s.storeMedia(ctx, person, content, size, fileName, "")
+	if errors.Is(err, repo.ErrMediaAlreadyExists) {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.SetPortrait(ctx, person, &m.ID); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// storeMedia кладёт файл и его превью в хранилище и сохраняет метаданные.
+// Если файл уже прикреплён к персоне, возвращает существующий вместе с ErrMediaAlreadyExists.
+func (s *MediaService) storeMedia(ctx context.Context, person *models.Person, content io.ReadSeeker, size int64, fileName, title string) (*models.Media, error) {
+	if size <= 0 {
+		return nil, errors.New("file is empty")
+	}
+	if size > s.maxUploadSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", s.maxUploadSize)
+	}
+
+	mimeType, err := detectMimeType(content)
+	if err != nil {
+		return nil, err
+	}
+	ext, ok := mediaExtensions[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported file type %s", mimeType)
+	}
+
+	// Превью строим до записи в хранилище: битое изображение отклоняем сразу
+	var thumbnails map[int][]byte
+	if models.IsThumbnailMimeType(mimeType) {
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+		if thumbnails, err = makeThumbnails(data); err != nil {
+			return nil, fmt.Errorf("invalid image: %w", err)
+		}
+		if _, err := content.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("rewind file: %w", err)
+		}
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return nil, fmt.Errorf("hash file: %w", err)
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("rewind file: %w", err)
+	}
+
+	contentHash := hex.EncodeToString(hash.Sum(nil))
+	existing, err := s.repo.GetMediaByHash(ctx, person.ID, contentHash)
+	if err == nil {
+		return existing, repo.ErrMediaAlreadyExists
+	}
+	if !errors.Is(err, repo.ErrMediaNotFound) {
+		return nil, err
+	}
+
+	m := &models.Media{
+		PersonID:    person.ID,
+		ContentHash: contentHash,
+		MimeType:    mimeType,
+		Size:        size,
+		FileName:    strings.TrimSpace(fileName),
+		Title:       strings.TrimSpace(title),
+	}
+	// Ключ зависит от содержимого: повторная загрузка того же файла перезапишет тот же объект
+	m.StorageKey = fmt.Sprintf("trees/%d/persons/%d/%s%s", person.TreeID, person.ID, m.ContentHash, ext)
+
+	if err := s.blobs.Put(ctx, m.StorageKey, content, size, mimeType); err != nil {
+		return nil, fmt.Errorf("service store media: %w", err)
+	}
+
+	if err := s.storeThumbnails(ctx, m, thumbnails); err != nil {
+		s.deleteBlobs(ctx, m)
+		return nil, err
+	}
+
+	if _, err := s.repo.CreateMedia(ctx, m); err != nil {
+		// При гонке дубликатов объекты принадлежат уже существующей записи — не трогаем их
+		if !errors.Is(err, repo.ErrMediaAlreadyExists) {
+			s.deleteBlobs(ctx, m)
+		}
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// GetMedia получает файлы персоны
+func (s *MediaService) GetMedia(ctx context.Context, personID int) ([]models.Media, error) {
+	media, err := s.repo.GetMediaByPersonID(ctx, personID)
+	if err != nil {
+		return nil, fmt.Errorf("service get media: %w", err)
+	}
+
+	return media, nil
+}
+
+// GetPersonMedia получает файл персоны; файл другой персоны считаем ненайденным
+func (s *MediaService) GetPersonMedia(ctx context.Context, personID, mediaID int) (*models.Media, error) {
+	if mediaID <= 0 {
+		return nil, errors.New("invalid media id")
+	}
+
+	m, err := s.repo.GetMediaByID(ctx, mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.PersonID != personID {
+		return nil, repo.ErrMediaNotFound
+	}
+
+	return m, nil
+}
+
+// GetTreeMedia получает файл вместе с его персоной; файл чужого дерева считаем ненайденным
+func (s *MediaService) GetTreeMedia(ctx context.Context, treeID, mediaID int) (*models.Media, *models.Person, error) {
+	if mediaID <= 0 {
+		return nil, nil, errors.New("invalid media id")
+	}
+
+	m, err := s.repo.GetMediaByID(ctx, mediaID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	person, err := s.repo.GetPersonByID(ctx, m.PersonID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if person.TreeID != treeID {
+		return nil, nil, repo.ErrMediaNotFound
+	}
+
+	return m, person, nil
+}
+
+// OpenMedia открывает содержимое файла; вызывающий закрывает ридер
+func (s *MediaService) OpenMedia(ctx context.Context, m *models.Media) (io.ReadCloser, error) {
+	content, err := s.blobs.Get(ctx, m.StorageKey)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, repo.ErrMediaNotFound
+		}
+		return nil, fmt.Errorf("service open media: %w", err)
+	}
+
+	return content, nil
+}
+
+// DeleteMedia удаляет файл персоны из базы и из хранилища
+func (s *MediaService) DeleteMedia(ctx context.Context, personID, mediaID int) error {
+	m, err := s.GetPersonMedia(ctx, personID, mediaID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteMedia(ctx, m.ID); err != nil {
+		return err
+	}
+
+	// Запись уже удалена: потерянный объект в хранилище не должен ломать запрос
+	s.deleteBlobs(ctx, m)
+	return nil
+}
+
+// SetPortrait назначает основной портрет персоны; nil убирает портрет
+func (s *MediaService) SetPortrait(ctx context.Context, person *models.Person, mediaID *int) error {
+	if mediaID != nil {
+		m, err := s.GetPersonMedia(ctx, person.ID, *mediaID)
+		if err != nil {
+			return err
+		}
+		if !m.HasThumbnails() {
+			return errors.New("portrait must be a JPEG or PNG image")
+		}
+	}
+
+	if err := s.repo.SetPersonPortrait(ctx, person.ID, mediaID); err != nil {
+		return err
+	}
+
+	person.PortraitID = mediaID
+	return nil
+}
+
+// OpenThumbnail открывает превью файла размера size.
+// Недостающие превью (например, удалённые из хранилища) строятся заново из оригинала.
+func (s *MediaService) OpenThumbnail(ctx context.Context, m *models.Media, size int) (io.ReadCloser, error) {
+	if !slices.Contains(models.ThumbnailSizes, size) {
+		return nil, fmt.Errorf("thumbnail size must be one of %v", models.ThumbnailSizes)
+	}
+	if !m.HasThumbnails() {
+		return nil, repo.ErrMediaNotFound
+	}
+
+	content, err := s.thumbnails.Get(ctx, thumbnailKey(m, size))
+	if err == nil {
+		return content, nil
+	}
+	if !errors.Is(err, blob.ErrNotFound) {
+		return nil, fmt.Errorf("service open thumbnail: %w", err)
+	}
+
+	original, err := s.OpenMedia(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	defer original.Close()
+
+	data, err := io.ReadAll(original)
+	if err != nil {
+		return nil, fmt.Errorf("service read media: %w", err)
+	}
+
+	thumbnails, err := makeThumbnails(data)
+	if err != nil {
+		return nil, fmt.Errorf("service make thumbnails: %w", err)
+	}
+	if err := s.storeThumbnails(ctx, m, thumbnails); err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(thumbnails[size])), nil
+}
+
+func (s *MediaService) storeThumbnails(ctx context.Context, m *models.Media, thumbnails map[int][]byte) error {
+	for size, data := range thumbnails {
+		if err := s.thumbnails.Put(ctx, thumbnailKey(m, size), bytes.NewReader(data), int64(len(data)), "image/jpeg"); err != nil {
+			return fmt.Errorf("service store thumbnail: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteFiles удаляет из хранилища объекты файлов, записи которых уже удалены из базы
+// (например, вместе с персоной при слиянии)
+func (s *MediaService) DeleteFiles(ctx context.Context, media []models.Media) {
+	for i := range media {
+		s.deleteBlobs(ctx, &media[i])
+	}
+}
+
+// deleteBlobs удаляет из хранилища файл, а с диска — его превью
+func (s *MediaService) deleteBlobs(ctx context.Context, m *models.Media) {
+	if err := s.blobs.Delete(ctx, m.StorageKey); err != nil {
+		slog.Error("failed to delete media blob", "key", m.StorageKey, "err", err)
+	}
+
+	if !m.HasThumbnails() {
+		return
+	}
+	for _, size := range models.ThumbnailSizes {
+		key := thumbnailKey(m, size)
+		if err := s.thumbnails.Delete(ctx, key); err != nil {
+			slog.Error("failed to delete thumbnail", "key", key, "err", err)
+		}
+		// Превью раньше хранились рядом с оригиналом
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			slog.Error("failed to delete media blob", "key", key, "err", err)
+		}
+	}
+}
+
+// thumbnailKey — ключ превью по пути оригинала: <hash>_<size>.jpg
+func thumbnailKey(m *models.Media, size int) string {
+	return fmt.Sprintf("%s_%d.jpg", strings.TrimSuffix(m.StorageKey, path.Ext(m.StorageKey)), size)
+}
+
+// makeThumbnails строит превью всех размеров из JPEG/PNG
+func makeThumbnails(data []byte) (map[int][]byte, error) {
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnails := make(map[int][]byte, len(models.ThumbnailSizes))
+	for _, size := range models.ThumbnailSizes {
+		var buf bytes.Buffer
+		if err := imaging.EncodeJPEG(&buf, imaging.Thumbnail(img, size)); err != nil {
+			return nil, fmt.Errorf("encode thumbnail: %w", err)
+		}
+		thumbnails[size] = buf.Bytes()
+	}
+
+	return thumbnails, nil
+}
+
+// detectMimeType определяет тип по первым байтам файла и возвращает ридер в начало
+func detectMimeType(content io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read file: %w", err)
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("rewind file: %w", err)
+	}
+
+	mimeType, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+	return mimeType, nil
+}
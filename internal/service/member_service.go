@@ -0,0 +1,154 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MemberService управляет участниками дерева и их ролями
+type MemberService struct {
+	repo *repo.Storage
+}
+
+func NewMemberService(storage *repo.Storage) *MemberService {
+	return &MemberService{
+		repo: storage,
+	}
+}
+
+// GetMembers получает приглашённых участников дерева
+func (s *MemberService) GetMembers(ctx context.Context, treeID int) ([]models.TreeMember, error) {
+	if treeID <= 0 {
+		return nil, errors.New("invalid tree id")
+	}
+
+	members, err := s.repo.GetTreeMembers(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get members: %w", err)
+	}
+
+	return members, nil
+}
+
+// AddMember приглашает существующего пользователя по email.
+// tree.Role — роль того, кто приглашает.
+func (s *MemberService) AddMember(ctx context.Context, tree *models.Tree, email, role string) (*models.TreeMember, error) {
+	if err := s.validateRole(role); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkManage(tree, role); err != nil {
+		return nil, err
+	}
+
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, errors.New("email is required")
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Владелец уже имеет полный доступ
+	if user.ID == tree.OwnerID {
+		return nil, repo.ErrMemberAlreadyExists
+	}
+
+	member := &models.TreeMember{
+		TreeID: tree.ID,
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   role,
+	}
+
+	if err := s.repo.AddTreeMember(ctx, member); err != nil {
+		return nil, fmt.Errorf("service add member: %w", err)
+	}
+
+	return member, nil
+}
+
+// UpdateMemberRole меняет роль участника
+func (s *MemberService) UpdateMemberRole(ctx context.Context, tree *models.Tree, userID int, role string) (*models.TreeMember, error) {
+	if err := s.validateRole(role); err != nil {
+		return nil, err
+	}
+
+	current, err := s.getMemberRole(ctx, tree.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Нужны права и на текущую, и на новую роль
+	if err := s.checkManage(tree, current); err != nil {
+		return nil, err
+	}
+	if err := s.checkManage(tree, role); err != nil {
+		return nil, err
+	}
+
+	member := &models.TreeMember{
+		TreeID: tree.ID,
+		UserID: userID,
+		Role:   role,
+	}
+
+	if err := s.repo.UpdateTreeMemberRole(ctx, member); err != nil {
+		return nil, fmt.Errorf("service update member role: %w", err)
+	}
+
+	return member, nil
+}
+
+// RemoveMember отзывает доступ участника к дереву
+func (s *MemberService) RemoveMember(ctx context.Context, tree *models.Tree, userID int) error {
+	current, err := s.getMemberRole(ctx, tree.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkManage(tree, current); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTreeMember(ctx, tree.ID, userID); err != nil {
+		return fmt.Errorf("service remove member: %w", err)
+	}
+
+	return nil
+}
+
+func (s *MemberService) getMemberRole(ctx context.Context, treeID, userID int) (string, error) {
+	if userID <= 0 {
+		return "", errors.New("invalid user id")
+	}
+
+	role, err := s.repo.GetTreeMemberRole(ctx, treeID, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get member: %w", err)
+	}
+
+	return role, nil
+}
+
+// checkManage — роль admin выдаёт и отзывает только владелец
+func (s *MemberService) checkManage(tree *models.Tree, role string) error {
+	if role == models.RoleAdmin && tree.Role != models.RoleOwner {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// validateRole проверяет роль участника
+func (s *MemberService) validateRole(role string) error {
+	if role != models.RoleViewer && role != models.RoleEditor && role != models.RoleAdmin {
+		return errors.New("role must be 'viewer', 'editor' or 'admin'")
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// MergePersons сливает персону sourceID в targetID. fields задаёт, чьё значение поля
+// остаётся: models.MergeKeepTarget (по умолчанию) или models.MergeKeepSource.
+// Если после слияния нарушились бы правила AddChild (не больше двух родителей разного пола,
+// родитель старше ребенка, без циклов) или даты жизни стали бы несовместимы,
+// возвращается ErrMergeConflict и список нарушений.
+func (s *PersonService) MergePersons(ctx context.Context, targetID, sourceID int, fields map[string]string) ([]models.MergeConflict, error) {
+	if targetID <= 0 || sourceID <= 0 {
+		return nil, errors.New("invalid person id")
+	}
+	if targetID == sourceID {
+		return nil, errors.New("cannot merge a person with itself")
+	}
+
+	for field, keep := range fields {
+		if !slices.Contains(models.MergeFields, field) {
+			return nil, fmt.Errorf("merge field must be one of %v", models.MergeFields)
+		}
+		if keep != models.MergeKeepTarget && keep != models.MergeKeepSource {
+			return nil, fmt.Errorf("merge choice for %s must be 'target' or 'source'", field)
+		}
+	}
+
+	var conflicts []models.MergeConflict
+	var removedMedia []models.Media
+	err := s.repo.WithTx(ctx, func(tx *repo.Storage) error {
+		// Сначала блокируем обе персоны: связь с новым ребенком создаётся только под
+		// блокировкой родителя, поэтому после неё список детей уже не меняется
+		if err := tx.LockPersons(ctx, targetID, sourceID); err != nil {
+			return fmt.Errorf("failed to lock persons: %w", err)
+		}
+
+		// Затем детей: у них меняется родитель, и параллельный AddChild не должен
+		// проверять их по старым связям
+		var children []int
+		for _, parentID := range []int{targetID, sourceID} {
+			list, err := tx.GetChildrenByParentID(ctx, parentID)
+			if err != nil {
+				return fmt.Errorf("failed to get children: %w", err)
+			}
+			for _, child := range list {
+				children = append(children, child.ID)
+			}
+		}
+		if err := tx.LockPersons(ctx, children...); err != nil {
+			return fmt.Errorf("failed to lock children: %w", err)
+		}
+
+		target, err := tx.GetPersonByID(ctx, targetID)
+		if err != nil {
+			return fmt.Errorf("service get merge target: %w", err)
+		}
+		source, err := tx.GetPersonByID(ctx, sourceID)
+		if err != nil {
+			return fmt.Errorf("service get merge source: %w", err)
+		}
+		// Персона из другого дерева для этого дерева не существует
+		if source.TreeID != target.TreeID {
+			return fmt.Errorf("service get merge source: %w", repo.ErrPersonNotFound)
+		}
+
+		merged := mergedPerson(target, source, fields)
+
+		graph, err := tx.GetTreeGraph(ctx, target.TreeID)
+		if err != nil {
+			return fmt.Errorf("service get tree graph: %w", err)
+		}
+		rules, err := loadLinkRules(ctx, tx, target.TreeID)
+		if err != nil {
+			return err
+		}
+		if conflicts = mergeConflicts(graph, rules, merged, sourceID); len(conflicts) > 0 {
+			return ErrMergeConflict
+		}
+
+		removedMedia, err = tx.MergePersons(ctx, merged, sourceID)
+		if err != nil {
+			return fmt.Errorf("service merge persons: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return conflicts, err
+	}
+
+	// Файлы удаляются из хранилища только после коммита: при откате записи о них остаются
+	s.media.DeleteFiles(ctx, removedMedia)
+	return nil, nil
+}
+
+// mergedPerson возвращает target с полями, выбранными у source
+func mergedPerson(target, source *models.Person, fields map[string]string) *models.Person {
+	merged := *target
+	fromSource := func(field string) bool {
+		return fields[field] == models.MergeKeepSource
+	}
+
+	if fromSource(models.MergeFieldFirstName) {
+		merged.FirstName = source.FirstName
+	}
+	if fromSource(models.MergeFieldLastName) {
+		merged.LastName = source.LastName
+	}
+	if fromSource(models.MergeFieldSex) {
+		merged.Sex = source.Sex
+	}
+	if fromSource(models.MergeFieldBirth) {
+		merged.BirthDate = source.BirthDate
+		merged.BirthPlaceID = source.BirthPlaceID
+	}
+	if fromSource(models.MergeFieldDeath) {
+		merged.DeathDate = source.DeathDate
+		merged.DeathPlaceID = source.DeathPlaceID
+	}
+	if fromSource(models.MergeFieldBiography) {
+		merged.Biography = source.Biography
+	}
+	if fromSource(models.MergeFieldPrivacy) {
+		merged.Privacy = source.Privacy
+	}
+	if fromSource(models.MergeFieldPortrait) {
+		merged.PortraitID = source.PortraitID
+	}
+	if fromSource(models.MergeFieldMetadata) {
+		merged.Metadata = source.Metadata
+	}
+
+	return &merged
+}
+
+// mergeConflicts проверяет даты жизни merged и правила AddChild на связях дерева после слияния:
+// sourceID заменяется на merged.ID, одинаковые связи схлопываются.
+// Лимит двух родителей и их пол проверяются только по связям, которые входят в лимит.
+// Проверяются только связи, в которых участвует оставшаяся персона.
+func mergeConflicts(graph *models.TreeGraph, rules *linkRules, merged *models.Person, sourceID int) []models.MergeConflict {
+	targetID := merged.ID
+	resolve := func(id int) int {
+		if id == sourceID {
+			return targetID
+		}
+		return id
+	}
+
+	persons := make(map[int]*models.Person, len(graph.Persons))
+	for i := range graph.Persons {
+		persons[graph.Persons[i].ID] = &graph.Persons[i]
+	}
+	persons[targetID] = merged
+
+	var conflicts []models.MergeConflict
+	conflict := func(personID int, format string, args ...any) {
+		conflicts = append(conflicts, models.MergeConflict{PersonID: personID, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	// Дата рождения одной персоны и дата смерти другой могут не сочетаться
+	if err := validateLifeDates(merged.BirthDate, merged.DeathDate); err != nil {
+		conflict(targetID, "%s", err)
+	}
+
+	type link struct{ parentID, childID int }
+	counts := make(map[link]bool) // связь входит в лимит двух родителей
+
+	family := &familyGraph{parents: make(map[int][]int), children: make(map[int][]int)}
+	for _, rel := range graph.Relationships {
+		parentID, childID := resolve(rel.ParentID), resolve(rel.ChildID)
+		if parentID == childID {
+			conflict(targetID, "persons %d and %d are parent and child", targetID, sourceID)
+			continue
+		}
+
+		// Из двух одинаковых связей остаётся связь merged, и учитывается её тип
+		l := link{parentID, childID}
+		fromSource := rel.ParentID == sourceID || rel.ChildID == sourceID
+		if _, seen := counts[l]; !seen || !fromSource {
+			counts[l] = rules.types.countsTowardParents(rel.RelationshipType)
+		}
+
+		if !slices.Contains(family.parents[childID], parentID) {
+			family.addEdge(parentID, childID)
+		}
+	}
+	for _, p := range graph.Partnerships {
+		if resolve(p.Person1ID) == resolve(p.Person2ID) {
+			conflict(targetID, "persons %d and %d are partners", targetID, sourceID)
+		}
+	}
+
+	// Оставшаяся персона как ребенок и каждый её ребенок
+	for _, childID := range append([]int{targetID}, family.children[targetID]...) {
+		var parents []int
+		for _, parentID := range family.parents[childID] {
+			if counts[link{parentID, childID}] {
+				parents = append(parents, parentID)
+			}
+		}
+
+		switch {
+		case len(parents) > 2:
+			conflict(childID, "person would have %d parents", len(parents))
+		case len(parents) == 2 && rules.sexConflict(persons[parents[0]].Sex, persons[parents[1]].Sex):
+			conflict(childID, "same-sex parents are not allowed in this tree")
+		}
+
+		for _, parentID := range family.parents[childID] {
+			if (parentID == targetID || childID == targetID) && !canBeParentOf(persons[parentID], persons[childID]) {
+				conflict(childID, "parent %d must be born before child", parentID)
+			}
+		}
+	}
+
+	for _, childID := range family.children[targetID] {
+		if family.isAncestor(childID, targetID) {
+			conflict(targetID, "person would become their own ancestor through %d", childID)
+			break
+		}
+	}
+
+	return conflicts
+}
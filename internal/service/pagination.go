@@ -0,0 +1,60 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Размер страницы постраничных списков
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// listPage ограничивает размер страницы и разбирает курсор клиента
+func listPage(sort string, desc bool, limit int, cursor string) (models.ListPage, error) {
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+
+	page := models.ListPage{
+		Sort:  sort,
+		Desc:  desc,
+		Limit: min(limit, maxPageLimit),
+	}
+
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return models.ListPage{}, err
+		}
+		page.After = after
+	}
+
+	return page, nil
+}
+
+// encodeCursor превращает курсор в непрозрачную строку для клиента; nil — пустая строка
+func encodeCursor(c *models.ListCursor) string {
+	if c == nil {
+		return ""
+	}
+	// Курсор состоит из строк и чисел, Marshal не возвращает ошибку
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor разбирает строку курсора из encodeCursor
+func decodeCursor(s string) (*models.ListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, repo.ErrInvalidCursor
+	}
+	var c models.ListCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, repo.ErrInvalidCursor
+	}
+	return &c, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Допустимые типы и статусы союзов
+var (
+	partnershipTypes    = []string{"marriage", "civil_union", "domestic_partnership", "engagement", "co_parents", "other"}
+	partnershipStatuses = []string{"active", "divorced", "separated", "widowed", "annulled", "ended"}
+)
+
+type PartnershipService struct {
+	repo *repo.Storage
+}
+
+func NewPartnershipService(storage *repo.Storage) *PartnershipService {
+	return &PartnershipService{
+		repo: storage,
+	}
+}
+
+// AddPartner создаёт союз персоны с существующим партнёром
+func (s *PartnershipService) AddPartner(ctx context.Context, personID, partnerID int, p *models.Partnership) (int, error) {
+	if personID <= 0 || partnerID <= 0 {
+		return 0, errors.New("invalid person or partner id")
+	}
+
+	// Персона не может состоять в союзе сама с собой
+	if personID == partnerID {
+		return 0, errors.New("person cannot be a partner of themselves")
+	}
+
+	if err := s.validatePartnership(p); err != nil {
+		return 0, err
+	}
+
+	person, err := s.repo.GetPersonByID(ctx, personID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get person: %w", err)
+	}
+
+	partner, err := s.repo.GetPersonByID(ctx, partnerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get partner: %w", err)
+	}
+
+	// Проверяем что они в одном дереве
+	if person.TreeID != partner.TreeID {
+		return 0, errors.New("partners must be in the same tree")
+	}
+
+	// Храним пару упорядоченной: person1_id < person2_id
+	p.Person1ID, p.Person2ID = min(personID, partnerID), max(personID, partnerID)
+
+	id, err := s.repo.CreatePartnership(ctx, p)
+	if err != nil {
+		return 0, fmt.Errorf("service add partner: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetPartners получает всех партнёров персоны
+func (s *PartnershipService) GetPartners(ctx context.Context, personID int) ([]models.Partner, error) {
+	if personID <= 0 {
+		return nil, errors.New("invalid person id")
+	}
+
+	// Проверяем что персона существует
+	if _, err := s.repo.GetPersonByID(ctx, personID); err != nil {
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+
+	partners, err := s.repo.GetPartnersByPersonID(ctx, personID)
+	if err != nil {
+		return nil, fmt.Errorf("service get partners: %w", err)
+	}
+
+	return partners, nil
+}
+
+// UpdatePartnership обновляет тип, статус и даты союза персоны
+func (s *PartnershipService) UpdatePartnership(ctx context.Context, personID int, p *models.Partnership) error {
+	if err := s.validatePartnership(p); err != nil {
+		return err
+	}
+
+	existing, err := s.getPersonPartnership(ctx, personID, p.ID)
+	if err != nil {
+		return err
+	}
+
+	p.Person1ID = existing.Person1ID
+	p.Person2ID = existing.Person2ID
+	p.CreatedAt = existing.CreatedAt
+
+	if err := s.repo.UpdatePartnership(ctx, p); err != nil {
+		return fmt.Errorf("service update partnership: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePartnership удаляет союз персоны
+func (s *PartnershipService) DeletePartnership(ctx context.Context, personID, partnershipID int) error {
+	if _, err := s.getPersonPartnership(ctx, personID, partnershipID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeletePartnership(ctx, partnershipID); err != nil {
+		return fmt.Errorf("service delete partnership: %w", err)
+	}
+
+	return nil
+}
+
+// getPersonPartnership получает союз и проверяет что персона в нём участвует
+func (s *PartnershipService) getPersonPartnership(ctx context.Context, personID, partnershipID int) (*models.Partnership, error) {
+	if personID <= 0 || partnershipID <= 0 {
+		return nil, errors.New("invalid person or partnership id")
+	}
+
+	p, err := s.repo.GetPartnershipByID(ctx, partnershipID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Союз чужой персоны считаем ненайденным
+	if p.Person1ID != personID && p.Person2ID != personID {
+		return nil, repo.ErrPartnershipNotFound
+	}
+
+	return p, nil
+}
+
+// validatePartnership проверяет тип, статус и даты союза
+func (s *PartnershipService) validatePartnership(p *models.Partnership) error {
+	if !slices.Contains(partnershipTypes, p.Type) {
+		return fmt.Errorf("partnership type must be one of %v", partnershipTypes)
+	}
+
+	if !slices.Contains(partnershipStatuses, p.Status) {
+		return fmt.Errorf("partnership status must be one of %v", partnershipStatuses)
+	}
+
+	if p.StartDate != nil && p.EndDate != nil {
+		if p.EndDate.Before(*p.StartDate) {
+			return errors.New("end date cannot be before start date")
+		}
+	}
+
+	return nil
+}
@@ -6,39 +6,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 )
 
 // PersonService содержит бизнес-логику для работы с персонами
 type PersonService struct {
-	repo *repo.Storage // Доступ к репозиторию
+	repo    *repo.Storage   // Доступ к репозиторию
+	privacy *PrivacyService // Порог «живых» для фильтров списка
+	media   *MediaService   // Удаление файлов персон, удалённых при слиянии
 }
 
 // NewPersonService создаёт новый сервис
-func NewPersonService(storage *repo.Storage) *PersonService {
+func NewPersonService(storage *repo.Storage, privacy *PrivacyService, media *MediaService) *PersonService {
 	return &PersonService{
-		repo: storage,
+		repo:    storage,
+		privacy: privacy,
+		media:   media,
 	}
 }
 
 // CreatePerson создаёт новую персону с валидацией
 func (s *PersonService) CreatePerson(ctx context.Context, p *models.Person) (int, error) {
-	// 1. Валидация структуры
-	if err := s.validatePerson(p); err != nil {
+	// 1. Валидация структуры и дат
+	if err := s.validatePerson(ctx, p); err != nil {
 		return 0, err
 	}
 
-	if p.BirthDate != nil && p.BirthDate.After(time.Now()) {
-		return 0, errors.New("birth date cannot be in the future")
-	}
-
-	if p.BirthDate != nil && p.DeathDate != nil {
-		if p.DeathDate.Before(*p.BirthDate) {
-			return 0, errors.New("death date cannot be before birth date")
-		}
-	}
-
-	// 4. Вызываем репозиторий для создания записи
+	// 2. Вызываем репозиторий для создания записи
 	id, err := s.repo.CreatePerson(ctx, p)
 	if err != nil {
 		return 0, fmt.Errorf("service create person: %w", err)
@@ -76,10 +71,83 @@ func (s *PersonService) GetPersonsByTreeID(ctx context.Context, treeID int) ([]m
 	return persons, nil
 }
 
+// ListPersons получает страницу персон дерева с фильтрами и сортировкой.
+// redact — список смотрит зритель: фильтры и сортировка по скрываемым данным
+// (датам, метаданным) не должны находить скрытых персон, а курсор — раскрывать их данные.
+func (s *PersonService) ListPersons(ctx context.Context, treeID int, query models.PersonListQuery, redact bool) (*models.PersonPage, error) {
+	if treeID <= 0 {
+		return nil, errors.New("invalid tree id")
+	}
+
+	sort := query.Sort
+	if sort == "" {
+		sort = models.PersonSortName
+	}
+	if !slices.Contains(models.PersonSorts, sort) {
+		return nil, fmt.Errorf("sort must be one of %v", models.PersonSorts)
+	}
+	if query.Sex != "" && !slices.Contains(models.Sexes, query.Sex) {
+		return nil, fmt.Errorf("sex must be one of %v", models.Sexes)
+	}
+	if query.BornFrom != 0 && query.BornTo != 0 && query.BornFrom > query.BornTo {
+		return nil, errors.New("born_from cannot be after born_to")
+	}
+
+	page, err := listPage(sort, query.Desc, query.Limit, query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	filter := models.PersonListFilter{
+		Sex:         query.Sex,
+		Living:      query.Living,
+		LivingSince: s.privacy.LivingSince(now),
+		Redact:      redact,
+	}
+	// Границы — с 1 января born_from до 1 января года после born_to
+	if query.BornFrom != 0 {
+		from := time.Date(query.BornFrom, time.January, 1, 0, 0, 0, 0, time.UTC)
+		filter.BornFrom = &from
+	}
+	if query.BornTo != 0 {
+		before := time.Date(query.BornTo+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+		filter.BornBefore = &before
+	}
+
+	if len(query.Metadata) > 0 {
+		fields, err := s.repo.GetCustomFieldsByTreeID(ctx, treeID)
+		if err != nil {
+			return nil, fmt.Errorf("service get custom fields: %w", err)
+		}
+		filter.MetadataMatch, filter.MetadataKeys, err = parseMetadataFilter(fields, query.Metadata)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	filter.VisibleOnly = redact && (filter.Living != nil ||
+		filter.BornFrom != nil ||
+		filter.BornBefore != nil ||
+		len(query.Metadata) > 0 ||
+		sort == models.PersonSortBirthDate)
+
+	persons, total, next, err := s.repo.ListPersons(ctx, treeID, filter, page)
+	if err != nil {
+		return nil, fmt.Errorf("service list persons: %w", err)
+	}
+
+	return &models.PersonPage{
+		Persons:    persons,
+		Total:      total,
+		NextCursor: encodeCursor(next),
+	}, nil
+}
+
 // UpdatePerson обновляет персону с валидацией
 func (s *PersonService) UpdatePerson(ctx context.Context, p *models.Person) error {
 	// Валидация
-	if err := s.validatePerson(p); err != nil {
+	if err := s.validatePerson(ctx, p); err != nil {
 		return err
 	}
 
@@ -87,16 +155,6 @@ func (s *PersonService) UpdatePerson(ctx context.Context, p *models.Person) erro
 		return errors.New("invalid person id")
 	}
 
-	if p.BirthDate != nil && p.BirthDate.After(time.Now()) {
-		return errors.New("birth date cannot be in the future")
-	}
-
-	if p.BirthDate != nil && p.DeathDate != nil {
-		if p.DeathDate.Before(*p.BirthDate) {
-			return errors.New("death date cannot be before birth date")
-		}
-	}
-
 	// Вызываем репозиторий
 	if err := s.repo.UpdatePerson(ctx, p); err != nil {
 		return fmt.Errorf("service update person: %w", err)
@@ -118,7 +176,7 @@ func (s *PersonService) DeletePerson(ctx context.Context, id int) error {
 	return nil
 }
 
-func (s *PersonService) validatePerson(p *models.Person) error {
+func (s *PersonService) validatePerson(ctx context.Context, p *models.Person) error {
 	if p.FirstName == "" {
 		return errors.New("first name is required")
 	}
@@ -129,5 +187,52 @@ func (s *PersonService) validatePerson(p *models.Person) error {
 		return errors.New("tree id is required")
 	}
 
+	if !slices.Contains(models.Sexes, p.Sex) {
+		return fmt.Errorf("sex must be one of %v", models.Sexes)
+	}
+
+	// Пустое значение — по умолчанию (auto) или без изменений при обновлении
+	if p.Privacy != "" && p.Privacy != models.PrivacyAuto &&
+		p.Privacy != models.PrivacyPublic && p.Privacy != models.PrivacyPrivate {
+		return errors.New("privacy must be 'auto', 'public' or 'private'")
+	}
+
+	// Места рождения и смерти должны быть из того же дерева
+	for _, placeID := range []*int{p.BirthPlaceID, p.DeathPlaceID} {
+		if placeID == nil {
+			continue
+		}
+		if _, err := getTreePlace(ctx, s.repo, p.TreeID, *placeID); err != nil {
+			return fmt.Errorf("invalid place %d: %w", *placeID, err)
+		}
+	}
+
+	// Метаданные — только поля из схемы дерева; nil при обновлении — без изменений
+	if len(p.Metadata) > 0 {
+		fields, err := s.repo.GetCustomFieldsByTreeID(ctx, p.TreeID)
+		if err != nil {
+			return fmt.Errorf("get custom fields: %w", err)
+		}
+		if err := validateMetadata(fields, p.Metadata); err != nil {
+			return err
+		}
+	}
+
+	return validateLifeDates(p.BirthDate, p.DeathDate)
+}
+
+// validateLifeDates проверяет даты рождения и смерти.
+// Даты сравниваются диапазонами: ошибка, только если противоречие неизбежно.
+func validateLifeDates(birth, death *models.GenDate) error {
+	if birth != nil && birth.DefinitelyAfterTime(time.Now()) {
+		return errors.New("birth date cannot be in the future")
+	}
+
+	if birth != nil && death != nil {
+		if death.DefinitelyBefore(*birth) {
+			return errors.New("death date cannot be before birth date")
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Допустимые типы мест
+var placeTypes = []string{
+	models.PlaceVillage, models.PlaceCity, models.PlaceDistrict,
+	models.PlaceRegion, models.PlaceCountry, models.PlaceOther,
+}
+
+type PlaceService struct {
+	repo *repo.Storage
+}
+
+func NewPlaceService(storage *repo.Storage) *PlaceService {
+	return &PlaceService{
+		repo: storage,
+	}
+}
+
+// GetPlaces получает все места дерева
+func (s *PlaceService) GetPlaces(ctx context.Context, treeID int) ([]models.Place, error) {
+	if treeID <= 0 {
+		return nil, errors.New("invalid tree id")
+	}
+
+	places, err := s.repo.GetPlacesByTreeID(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get places: %w", err)
+	}
+
+	return places, nil
+}
+
+// GetPlace получает место дерева
+func (s *PlaceService) GetPlace(ctx context.Context, treeID, placeID int) (*models.Place, error) {
+	return getTreePlace(ctx, s.repo, treeID, placeID)
+}
+
+// CreatePlace создаёт место в дереве
+func (s *PlaceService) CreatePlace(ctx context.Context, p *models.Place) (int, error) {
+	if err := s.validatePlace(ctx, p); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.CreatePlace(ctx, p)
+	if err != nil {
+		return 0, fmt.Errorf("service create place: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdatePlace обновляет место дерева
+func (s *PlaceService) UpdatePlace(ctx context.Context, p *models.Place) error {
+	existing, err := getTreePlace(ctx, s.repo, p.TreeID, p.ID)
+	if err != nil {
+		return err
+	}
+	p.CreatedAt = existing.CreatedAt
+
+	if err := s.validatePlace(ctx, p); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdatePlace(ctx, p); err != nil {
+		return fmt.Errorf("service update place: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePlace удаляет место дерева
+func (s *PlaceService) DeletePlace(ctx context.Context, treeID, placeID int) error {
+	if _, err := getTreePlace(ctx, s.repo, treeID, placeID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeletePlace(ctx, placeID); err != nil {
+		return fmt.Errorf("service delete place: %w", err)
+	}
+
+	return nil
+}
+
+// GetVitalPlaces находит места рождения и смерти персон дерева, у которых известны координаты.
+// Если у места нет координат, берутся координаты ближайшего родителя (деревня → район → ...).
+func (s *PlaceService) GetVitalPlaces(ctx context.Context, treeID int) ([]models.VitalPlace, error) {
+	places, err := s.GetPlaces(ctx, treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	persons, err := s.repo.GetPersonsByTreeID(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get vital places: %w", err)
+	}
+
+	byID := make(map[int]*models.Place, len(places))
+	for i := range places {
+		byID[places[i].ID] = &places[i]
+	}
+
+	var result []models.VitalPlace
+	for _, person := range persons {
+		for _, vital := range []struct {
+			eventType string
+			date      *models.GenDate
+			placeID   *int
+		}{
+			{models.EventBirth, person.BirthDate, person.BirthPlaceID},
+			{models.EventDeath, person.DeathDate, person.DeathPlaceID},
+		} {
+			if vital.placeID == nil {
+				continue
+			}
+			place, ok := byID[*vital.placeID]
+			if !ok {
+				continue
+			}
+			located := locatePlace(place, byID)
+			if located == nil {
+				continue
+			}
+			result = append(result, models.VitalPlace{
+				Person:    person,
+				EventType: vital.eventType,
+				Date:      vital.date,
+				Place:     *place,
+				Latitude:  *located.Latitude,
+				Longitude: *located.Longitude,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// locatePlace возвращает ближайшее к месту (включая его само) место с координатами
+func locatePlace(place *models.Place, byID map[int]*models.Place) *models.Place {
+	// Счётчик шагов страхует от циклов в старых данных
+	for steps := 0; place != nil && steps <= len(byID); steps++ {
+		if place.HasCoordinates() {
+			return place
+		}
+		if place.ParentID == nil {
+			return nil
+		}
+		place = byID[*place.ParentID]
+	}
+	return nil
+}
+
+// validatePlace проверяет название, тип, координаты, родителя и исторические названия места
+func (s *PlaceService) validatePlace(ctx context.Context, p *models.Place) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return errors.New("place name is required")
+	}
+	if p.TreeID <= 0 {
+		return errors.New("tree id is required")
+	}
+
+	if !slices.Contains(placeTypes, p.Type) {
+		return fmt.Errorf("place type must be one of %v", placeTypes)
+	}
+
+	if (p.Latitude == nil) != (p.Longitude == nil) {
+		return errors.New("latitude and longitude must be set together")
+	}
+	if p.HasCoordinates() {
+		if *p.Latitude < -90 || *p.Latitude > 90 {
+			return errors.New("latitude must be between -90 and 90")
+		}
+		if *p.Longitude < -180 || *p.Longitude > 180 {
+			return errors.New("longitude must be between -180 and 180")
+		}
+	}
+
+	for _, name := range p.Names {
+		if strings.TrimSpace(name.Name) == "" {
+			return errors.New("historical place name is required")
+		}
+		if name.FromYear != nil && name.ToYear != nil && *name.ToYear < *name.FromYear {
+			return errors.New("historical name cannot end before it starts")
+		}
+	}
+
+	if p.ParentID == nil {
+		return nil
+	}
+
+	// Родитель из того же дерева, и место не может оказаться своим же предком
+	seen := map[int]bool{}
+	for parentID := *p.ParentID; !seen[parentID]; {
+		if p.ID != 0 && parentID == p.ID {
+			return errors.New("place cannot be inside itself")
+		}
+		seen[parentID] = true
+		parent, err := getTreePlace(ctx, s.repo, p.TreeID, parentID)
+		if err != nil {
+			return fmt.Errorf("failed to get parent place: %w", err)
+		}
+		if parent.ParentID == nil {
+			return nil
+		}
+		parentID = *parent.ParentID
+	}
+
+	return nil
+}
+
+// getTreePlace получает место и проверяет что оно принадлежит дереву
+func getTreePlace(ctx context.Context, storage *repo.Storage, treeID, placeID int) (*models.Place, error) {
+	if treeID <= 0 || placeID <= 0 {
+		return nil, errors.New("invalid tree or place id")
+	}
+
+	place, err := storage.GetPlaceByID(ctx, placeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Место чужого дерева считаем ненайденным
+	if place.TreeID != treeID {
+		return nil, repo.ErrPlaceNotFound
+	}
+
+	return place, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"time"
+)
+
+// Имена, которые видны вместо скрытых персон
+const (
+	livingName  = "Living"
+	privateName = "Private"
+)
+
+// PrivacyService скрывает данные живых и закрытых персон от тех,
+// кто не может редактировать дерево (зрители и публичные ссылки)
+type PrivacyService struct {
+	livingYears int
+}
+
+func NewPrivacyService(livingYears int) *PrivacyService {
+	return &PrivacyService{
+		livingYears: livingYears,
+	}
+}
+
+// IsHidden определяет, нужно ли скрывать персону.
+// auto: без даты смерти и родилась менее livingYears лет назад (или дата рождения неизвестна).
+func (s *PrivacyService) IsHidden(p *models.Person, now time.Time) bool {
+	return p.Hidden(s.LivingSince(now))
+}
+
+// LivingSince — персоны без даты смерти, родившиеся позже, считаются живыми
+func (s *PrivacyService) LivingSince(now time.Time) time.Time {
+	return now.AddDate(-s.livingYears, 0, 0)
+}
+
+// RedactPerson скрывает персону, если нужно: остаются фамилия, пол и связи.
+// Возвращает true, если персона скрыта.
+func (s *PrivacyService) RedactPerson(p *models.Person, now time.Time) bool {
+	if !s.IsHidden(p, now) {
+		return false
+	}
+
+	p.FirstName = livingName
+	if p.Privacy == models.PrivacyPrivate {
+		p.FirstName = privateName
+	}
+	p.BirthDate = nil
+	p.DeathDate = nil
+	p.BirthPlaceID = nil
+	p.DeathPlaceID = nil
+	p.PortraitID = nil
+	p.Biography = ""
+	p.Metadata = nil
+	return true
+}
+
+// RedactPersons скрывает персоны списка
+func (s *PrivacyService) RedactPersons(persons []models.Person) {
+	now := time.Now()
+	for i := range persons {
+		s.RedactPerson(&persons[i], now)
+	}
+}
+
+// RedactRelatives скрывает предков или потомков
+func (s *PrivacyService) RedactRelatives(relatives []models.Relative) {
+	now := time.Now()
+	for i := range relatives {
+		s.RedactPerson(&relatives[i].Person, now)
+	}
+}
+
+// RedactPartners скрывает партнёров персоны и даты союзов, если скрыт хотя бы один из пары
+func (s *PrivacyService) RedactPartners(person *models.Person, partners []models.Partner) {
+	now := time.Now()
+	for i := range partners {
+		hidden := s.partnershipHidden(person, &partners[i].Person, now)
+		s.RedactPerson(&partners[i].Person, now)
+		if hidden {
+			partners[i].Partnership.StartDate = nil
+			partners[i].Partnership.EndDate = nil
+		}
+	}
+}
+
+// RedactEvents убирает события, которые скрываются от зрителя: у скрытой персоны — все,
+// у видимой — события союзов, даты которых скрывает RedactPartners
+func (s *PrivacyService) RedactEvents(person *models.Person, partners []models.Partner, events []models.Event) []models.Event {
+	now := time.Now()
+	if s.IsHidden(person, now) {
+		return nil
+	}
+
+	hidden := make(map[int]bool)
+	for i := range partners {
+		if s.partnershipHidden(person, &partners[i].Person, now) {
+			hidden[partners[i].Partnership.ID] = true
+		}
+	}
+
+	visible := events[:0]
+	for _, e := range events {
+		if e.PartnershipID != nil && hidden[*e.PartnershipID] {
+			continue
+		}
+		visible = append(visible, e)
+	}
+	return visible
+}
+
+// partnershipHidden — данные союза скрываются, если скрыт хотя бы один из пары
+func (s *PrivacyService) partnershipHidden(person, partner *models.Person, now time.Time) bool {
+	return s.IsHidden(person, now) || s.IsHidden(partner, now)
+}
+
+// RedactGraph скрывает персоны графа и даты их союзов
+func (s *PrivacyService) RedactGraph(graph *models.TreeGraph) {
+	now := time.Now()
+	hidden := make(map[int]bool)
+	for i := range graph.Persons {
+		if s.RedactPerson(&graph.Persons[i], now) {
+			hidden[graph.Persons[i].ID] = true
+		}
+	}
+
+	for i := range graph.Partnerships {
+		p := &graph.Partnerships[i]
+		if hidden[p.Person1ID] || hidden[p.Person2ID] {
+			p.StartDate = nil
+			p.EndDate = nil
+		}
+	}
+}
@@ -6,85 +6,82 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 )
 
 type RelationshipService struct {
-	repo *repo.Storage
+	repo    *repo.Storage
+	privacy *PrivacyService
 }
 
-func NewRelationshipService(storage *repo.Storage) *RelationshipService {
+func NewRelationshipService(storage *repo.Storage, privacy *PrivacyService) *RelationshipService {
 	return &RelationshipService{
-		repo: storage,
+		repo:    storage,
+		privacy: privacy,
 	}
 }
 
-// AddChild связывает существующего ребенка с родителем
+// AddChild связывает существующего ребенка с родителем.
+// Проверки и запись идут в одной транзакции с блокировкой родителя и ребенка,
+// иначе два параллельных запроса могут дать ребенку третьего родителя.
 func (s *RelationshipService) AddChild(ctx context.Context, parentID, childID int, relType string) (int, error) {
-	// Валидация
-	if err := s.validateRelationshipType(relType); err != nil {
-		return 0, err
-	}
-
-	// Получаем родителя и ребенка
-	parent, err := s.repo.GetPersonByID(ctx, parentID)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get parent: %w", err)
-	}
+	var id int
+	err := s.repo.WithTx(ctx, func(tx *repo.Storage) error {
+		if err := tx.LockPersons(ctx, parentID, childID); err != nil {
+			return fmt.Errorf("failed to lock persons: %w", err)
+		}
 
-	child, err := s.repo.GetPersonByID(ctx, childID)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get child: %w", err)
-	}
+		// Получаем родителя и ребенка
+		parent, err := tx.GetPersonByID(ctx, parentID)
+		if err != nil {
+			return fmt.Errorf("failed to get parent: %w", err)
+		}
 
-	// Проверяем что они в одном дереве
-	if parent.TreeID != child.TreeID {
-		return 0, errors.New("parent and child must be in the same tree")
-	}
+		child, err := tx.GetPersonByID(ctx, childID)
+		if err != nil {
+			return fmt.Errorf("failed to get child: %w", err)
+		}
 
-	// Проверяем возраст (родитель старше ребенка)
-	if err := s.validateAge(parent, child); err != nil {
-		return 0, err
-	}
+		existingParents, err := tx.GetParentLinksByChildID(ctx, childID)
+		if err != nil {
+			return fmt.Errorf("failed to get existing parents: %w", err)
+		}
 
-	// Проверяем количество родителей у ребенка
-	existingParents, err := s.repo.GetParentsByChildID(ctx, childID)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get existing parents: %w", err)
-	}
+		rules, err := loadLinkRules(ctx, tx, child.TreeID)
+		if err != nil {
+			return err
+		}
 
-	if len(existingParents) >= 2 {
-		return 0, errors.New("child already has 2 parents")
-	}
+		if err := s.validateLink(parent, child, relType, existingParents, rules); err != nil {
+			return err
+		}
 
-	// Если уже есть 1 родитель - проверяем пол
-	if len(existingParents) == 1 {
-		if existingParents[0].IsMale == parent.IsMale {
-			return 0, errors.New("parents must be of different gender")
+		// Ребенок не может оказаться предком своего родителя
+		isAncestor, err := tx.IsAncestor(ctx, childID, parentID)
+		if err != nil {
+			return fmt.Errorf("failed to check ancestry: %w", err)
 		}
-	}
 
-	// Проверяем что такой связи ещё нет
-	children, err := s.repo.GetChildrenByParentID(ctx, parentID)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get existing children: %w", err)
-	}
+		if isAncestor {
+			return repo.ErrRelationshipCycle
+		}
 
-	for _, c := range children {
-		if c.ID == childID {
-			return 0, errors.New("relationship already exists")
+		// Создаём связь
+		rel := &models.Relationship{
+			ParentID:         parentID,
+			ChildID:          childID,
+			RelationshipType: relType,
 		}
-	}
 
-	// Создаём связь
-	rel := &models.Relationship{
-		ParentID:         parentID,
-		ChildID:          childID,
-		RelationshipType: relType,
-	}
+		id, err = tx.CreateRelationship(ctx, rel)
+		if err != nil {
+			return fmt.Errorf("service add child: %w", err)
+		}
 
-	id, err := s.repo.CreateRelationship(ctx, rel)
+		return nil
+	})
 	if err != nil {
-		return 0, fmt.Errorf("service add child: %w", err)
+		return 0, err
 	}
 
 	return id, nil
@@ -96,102 +93,122 @@ func (s *RelationshipService) AddParent(ctx context.Context, childID, parentID i
 	return s.AddChild(ctx, parentID, childID, relType)
 }
 
-// CreateChildAndLink создаёт нового ребенка и связывает с родителем
+// CreateChildAndLink создаёт нового ребенка и связывает с родителем.
+// Персона и связь создаются в одной транзакции: без связи ребенок не остаётся.
 func (s *RelationshipService) CreateChildAndLink(ctx context.Context, parentID int, child *models.Person, relType string) (int, error) {
-	// Валидация
-	if err := s.validateRelationshipType(relType); err != nil {
-		return 0, err
-	}
+	var relID int
+	err := s.repo.WithTx(ctx, func(tx *repo.Storage) error {
+		// Родитель не должен измениться или исчезнуть до создания связи
+		if err := tx.LockPersons(ctx, parentID); err != nil {
+			return fmt.Errorf("failed to lock parent: %w", err)
+		}
 
-	// Получаем родителя
-	parent, err := s.repo.GetPersonByID(ctx, parentID)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get parent: %w", err)
-	}
+		// Получаем родителя
+		parent, err := tx.GetPersonByID(ctx, parentID)
+		if err != nil {
+			return fmt.Errorf("failed to get parent: %w", err)
+		}
 
-	// Устанавливаем TreeID от родителя
-	child.TreeID = parent.TreeID
+		// Устанавливаем TreeID от родителя
+		child.TreeID = parent.TreeID
 
-	// Проверяем возраст
-	if err := s.validateAge(parent, child); err != nil {
-		return 0, err
-	}
+		// У нового ребенка нет родителей, поэтому проверяем только, что тип есть в реестре
+		types, err := loadRelationshipTypes(ctx, tx, parent.TreeID)
+		if err != nil {
+			return err
+		}
+		if _, err := types.get(relType); err != nil {
+			return err
+		}
 
-	// Создаём ребенка
-	childID, err := s.repo.CreatePerson(ctx, child)
-	if err != nil {
-		return 0, fmt.Errorf("failed to create child: %w", err)
-	}
+		// Проверяем возраст
+		if err := s.validateAge(parent, child); err != nil {
+			return err
+		}
 
-	// Создаём связь
-	rel := &models.Relationship{
-		ParentID:         parentID,
-		ChildID:          childID,
-		RelationshipType: relType,
-	}
+		// Создаём ребенка
+		childID, err := tx.CreatePerson(ctx, child)
+		if err != nil {
+			return fmt.Errorf("failed to create child: %w", err)
+		}
+
+		// Создаём связь
+		rel := &models.Relationship{
+			ParentID:         parentID,
+			ChildID:          childID,
+			RelationshipType: relType,
+		}
+
+		relID, err = tx.CreateRelationship(ctx, rel)
+		if err != nil {
+			return fmt.Errorf("failed to create relationship: %w", err)
+		}
 
-	relID, err := s.repo.CreateRelationship(ctx, rel)
+		return nil
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to create relationship: %w", err)
+		return 0, err
 	}
 
 	return relID, nil
 }
 
-// CreateParentAndLink создаёт нового родителя и связывает с ребенком
+// CreateParentAndLink создаёт нового родителя и связывает с ребенком.
+// Ребенок блокируется до конца транзакции, чтобы число его родителей не изменилось после проверки.
 func (s *RelationshipService) CreateParentAndLink(ctx context.Context, childID int, parent *models.Person, relType string) (int, error) {
-	// Валидация
-	if err := s.validateRelationshipType(relType); err != nil {
-		return 0, err
-	}
-
-	// Получаем ребенка
-	child, err := s.repo.GetPersonByID(ctx, childID)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get child: %w", err)
-	}
+	var relID int
+	err := s.repo.WithTx(ctx, func(tx *repo.Storage) error {
+		if err := tx.LockPersons(ctx, childID); err != nil {
+			return fmt.Errorf("failed to lock child: %w", err)
+		}
 
-	// Проверяем количество родителей
-	existingParents, err := s.repo.GetParentsByChildID(ctx, childID)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get existing parents: %w", err)
-	}
+		// Получаем ребенка
+		child, err := tx.GetPersonByID(ctx, childID)
+		if err != nil {
+			return fmt.Errorf("failed to get child: %w", err)
+		}
 
-	if len(existingParents) >= 2 {
-		return 0, errors.New("child already has 2 parents")
-	}
+		existingParents, err := tx.GetParentLinksByChildID(ctx, childID)
+		if err != nil {
+			return fmt.Errorf("failed to get existing parents: %w", err)
+		}
 
-	// Если есть 1 родитель - проверяем пол
-	if len(existingParents) == 1 {
-		if existingParents[0].IsMale == parent.IsMale {
-			return 0, errors.New("parents must be of different gender")
+		rules, err := loadLinkRules(ctx, tx, child.TreeID)
+		if err != nil {
+			return err
 		}
-	}
 
-	// Устанавливаем TreeID от ребенка
-	parent.TreeID = child.TreeID
+		// Устанавливаем TreeID от ребенка
+		parent.TreeID = child.TreeID
 
-	// Проверяем возраст
-	if err := s.validateAge(parent, child); err != nil {
-		return 0, err
-	}
+		// Новый родитель ещё без ID, поэтому совпасть с ребенком или его родителями не может:
+		// проверяются тип, возраст, число родителей и пол
+		if err := s.validateLink(parent, child, relType, existingParents, rules); err != nil {
+			return err
+		}
 
-	// Создаём родителя
-	parentID, err := s.repo.CreatePerson(ctx, parent)
-	if err != nil {
-		return 0, fmt.Errorf("failed to create parent: %w", err)
-	}
+		// Создаём родителя
+		parentID, err := tx.CreatePerson(ctx, parent)
+		if err != nil {
+			return fmt.Errorf("failed to create parent: %w", err)
+		}
 
-	// Создаём связь
-	rel := &models.Relationship{
-		ParentID:         parentID,
-		ChildID:          childID,
-		RelationshipType: relType,
-	}
+		// Создаём связь
+		rel := &models.Relationship{
+			ParentID:         parentID,
+			ChildID:          childID,
+			RelationshipType: relType,
+		}
+
+		relID, err = tx.CreateRelationship(ctx, rel)
+		if err != nil {
+			return fmt.Errorf("failed to create relationship: %w", err)
+		}
 
-	relID, err := s.repo.CreateRelationship(ctx, rel)
+		return nil
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to create relationship: %w", err)
+		return 0, err
 	}
 
 	return relID, nil
@@ -210,60 +227,219 @@ func (s *RelationshipService) DeleteRelationship(ctx context.Context, parentID,
 	return nil
 }
 
-// GetAvailableChildren получает список доступных детей для персоны
-func (s *RelationshipService) GetAvailableChildren(ctx context.Context, parentID int) ([]models.Person, error) {
+// GetAvailableChildren получает список доступных детей для персоны.
+// При redact скрытые персоны сравниваются по скрытым данным: иначе то, попал ли
+// кандидат в список, выдавало бы зрителю диапазон его даты рождения.
+func (s *RelationshipService) GetAvailableChildren(ctx context.Context, parentID int, redact bool) ([]models.Person, error) {
 	if parentID <= 0 {
 		return nil, errors.New("invalid parent id")
 	}
 
 	// Проверяем что родитель существует
-	_, err := s.repo.GetPersonByID(ctx, parentID)
+	parent, err := s.repo.GetPersonByID(ctx, parentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parent: %w", err)
 	}
 
-	children, err := s.repo.GetAvailableChildren(ctx, parentID)
+	candidates, err := s.repo.GetAvailableChildren(ctx, parentID)
 	if err != nil {
 		return nil, fmt.Errorf("service get available children: %w", err)
 	}
 
+	if redact {
+		s.privacy.RedactPerson(parent, time.Now())
+		s.privacy.RedactPersons(candidates)
+	}
+
+	var children []models.Person
+	for _, child := range candidates {
+		if canBeParentOf(parent, &child) {
+			children = append(children, child)
+		}
+	}
+
 	return children, nil
 }
 
-// GetAvailableParents получает список доступных родителей для персоны
-func (s *RelationshipService) GetAvailableParents(ctx context.Context, childID int) ([]models.Person, error) {
+// GetAvailableParents получает список доступных родителей для персоны.
+// При redact фильтрует по скрытым данным, как GetAvailableChildren.
+func (s *RelationshipService) GetAvailableParents(ctx context.Context, childID int, redact bool) ([]models.Person, error) {
 	if childID <= 0 {
 		return nil, errors.New("invalid child id")
 	}
 
 	// Проверяем что ребенок существует
-	_, err := s.repo.GetPersonByID(ctx, childID)
+	child, err := s.repo.GetPersonByID(ctx, childID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get child: %w", err)
 	}
 
-	parents, err := s.repo.GetAvailableParents(ctx, childID)
+	candidates, err := s.repo.GetAvailableParents(ctx, childID)
 	if err != nil {
 		return nil, fmt.Errorf("service get available parents: %w", err)
 	}
 
+	if redact {
+		s.privacy.RedactPerson(child, time.Now())
+		s.privacy.RedactPersons(candidates)
+	}
+
+	var parents []models.Person
+	for _, parent := range candidates {
+		if canBeParentOf(&parent, child) {
+			parents = append(parents, parent)
+		}
+	}
+
 	return parents, nil
 }
 
-// validateAge проверяет что родитель старше ребенка
-func (s *RelationshipService) validateAge(parent, child *models.Person) error {
-	if parent.BirthDate != nil && child.BirthDate != nil {
-		if !parent.BirthDate.Before(*child.BirthDate) {
-			return errors.New("parent must be born before child")
+// maxRelativesDepth — максимальная глубина обхода предков/потомков
+const maxRelativesDepth = 50
+
+// GetAncestors получает предков персоны до depth поколений вверх
+func (s *RelationshipService) GetAncestors(ctx context.Context, personID, depth int) ([]models.Relative, []models.Relationship, error) {
+	if err := s.validateRelativesQuery(ctx, personID, depth); err != nil {
+		return nil, nil, err
+	}
+
+	ancestors, relationships, err := s.repo.GetAncestors(ctx, personID, depth)
+	if err != nil {
+		return nil, nil, fmt.Errorf("service get ancestors: %w", err)
+	}
+
+	return ancestors, relationships, nil
+}
+
+// GetDescendants получает потомков персоны до depth поколений вниз
+func (s *RelationshipService) GetDescendants(ctx context.Context, personID, depth int) ([]models.Relative, []models.Relationship, error) {
+	if err := s.validateRelativesQuery(ctx, personID, depth); err != nil {
+		return nil, nil, err
+	}
+
+	descendants, relationships, err := s.repo.GetDescendants(ctx, personID, depth)
+	if err != nil {
+		return nil, nil, fmt.Errorf("service get descendants: %w", err)
+	}
+
+	return descendants, relationships, nil
+}
+
+// validateRelativesQuery проверяет глубину и что персона существует
+func (s *RelationshipService) validateRelativesQuery(ctx context.Context, personID, depth int) error {
+	if personID <= 0 {
+		return errors.New("invalid person id")
+	}
+
+	if depth < 1 || depth > maxRelativesDepth {
+		return fmt.Errorf("depth must be between 1 and %d", maxRelativesDepth)
+	}
+
+	if _, err := s.repo.GetPersonByID(ctx, personID); err != nil {
+		return fmt.Errorf("failed to get person: %w", err)
+	}
+
+	return nil
+}
+
+// validateLink проверяет правила связи родитель-ребенок типа relType
+// (общие для AddChild и импорта, где связи ещё не сохранены в БД)
+func (s *RelationshipService) validateLink(parent, child *models.Person, relType string, existingParents []models.ParentLink, rules *linkRules) error {
+	linkType, err := rules.types.get(relType)
+	if err != nil {
+		return err
+	}
+
+	// Персона не может быть родителем самой себе
+	if parent.ID == child.ID {
+		return repo.ErrSelfParent
+	}
+
+	// Проверяем что они в одном дереве
+	if parent.TreeID != child.TreeID {
+		return repo.ErrCrossTreeRelationship
+	}
+
+	// Проверяем возраст (родитель старше ребенка)
+	if err := s.validateAge(parent, child); err != nil {
+		return err
+	}
+
+	// Проверяем что такой связи ещё нет
+	for _, link := range existingParents {
+		if link.Parent.ID == parent.ID {
+			return repo.ErrRelationshipExists
+		}
+	}
+
+	// Отчим, опекун, донор и т.п. не входят в лимит двух родителей и не сравниваются по полу
+	if !linkType.CountsTowardParents {
+		return nil
+	}
+
+	var counted []models.Person
+	for _, link := range existingParents {
+		if rules.types.countsTowardParents(link.RelationshipType) {
+			counted = append(counted, link.Parent)
+		}
+	}
+
+	// Проверяем количество родителей у ребенка
+	if len(counted) >= 2 {
+		return repo.ErrTooManyParents
+	}
+
+	// Если уже есть 1 родитель - проверяем пол по политике дерева
+	if len(counted) == 1 {
+		if rules.sexConflict(counted[0].Sex, parent.Sex) {
+			return repo.ErrSameSexParents
 		}
 	}
+
 	return nil
 }
 
-// validateRelationshipType проверяет тип связи
-func (s *RelationshipService) validateRelationshipType(relType string) error {
-	if relType != "biological" && relType != "not_biological" {
-		return errors.New("relationship type must be 'biological' or 'not_biological'")
+// linkRules — правила связей родитель-ребенок, заданные деревом
+type linkRules struct {
+	types               relationshipTypes
+	allowSameSexParents bool
+}
+
+// loadLinkRules получает реестр типов связей и политику дерева
+func loadLinkRules(ctx context.Context, storage *repo.Storage, treeID int) (*linkRules, error) {
+	tree, err := storage.GetTreeByID(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	types, err := loadRelationshipTypes(ctx, storage, treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &linkRules{types: types, allowSameSexParents: tree.AllowSameSexParents}, nil
+}
+
+// sexConflict — два родителя с полом a и b нарушают политику дерева.
+// Неизвестный и иной пол ни с кем не конфликтуют.
+func (r *linkRules) sexConflict(a, b string) bool {
+	return !r.allowSameSexParents && a == b && (a == models.SexMale || a == models.SexFemale)
+}
+
+// validateAge проверяет что родитель старше ребенка.
+// Для приблизительных дат достаточно, чтобы родитель мог родиться раньше.
+func (s *RelationshipService) validateAge(parent, child *models.Person) error {
+	if !canBeParentOf(parent, child) {
+		return errors.New("parent must be born before child")
 	}
 	return nil
 }
+
+// canBeParentOf — даты рождения не исключают, что parent старше child.
+// Если хотя бы одна дата неизвестна, исключить нельзя.
+func canBeParentOf(parent, child *models.Person) bool {
+	if parent.BirthDate == nil || child.BirthDate == nil {
+		return true
+	}
+	return parent.BirthDate.CanBeBefore(*child.BirthDate)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// relationshipTypeCode — код хранится в relationships.relationship_type (VARCHAR(20))
+var relationshipTypeCode = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+
+type RelationshipTypeService struct {
+	repo *repo.Storage
+}
+
+func NewRelationshipTypeService(storage *repo.Storage) *RelationshipTypeService {
+	return &RelationshipTypeService{
+		repo: storage,
+	}
+}
+
+// GetRelationshipTypes получает встроенные типы связей и, если treeID > 0, типы дерева
+func (s *RelationshipTypeService) GetRelationshipTypes(ctx context.Context, treeID int) ([]models.RelationshipType, error) {
+	if treeID < 0 {
+		return nil, errors.New("invalid tree id")
+	}
+
+	types, err := s.repo.GetRelationshipTypes(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get relationship types: %w", err)
+	}
+
+	return types, nil
+}
+
+// CreateRelationshipType добавляет тип связи в реестр дерева
+func (s *RelationshipTypeService) CreateRelationshipType(ctx context.Context, t *models.RelationshipType) (int, error) {
+	if !relationshipTypeCode.MatchString(t.Code) {
+		return 0, errors.New("code must start with a lowercase letter and contain only a-z, 0-9 and _ (up to 20 characters)")
+	}
+
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return 0, errors.New("name is required")
+	}
+	if len(t.Name) > 100 {
+		return 0, errors.New("name is too long (max 100 characters)")
+	}
+
+	id, err := s.repo.CreateRelationshipType(ctx, t)
+	if err != nil {
+		return 0, fmt.Errorf("service create relationship type: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteRelationshipType удаляет тип дерева. Встроенный тип или тип чужого дерева
+// считаем ненайденным; тип, которым размечены связи, не удаляется.
+func (s *RelationshipTypeService) DeleteRelationshipType(ctx context.Context, treeID, typeID int) error {
+	if typeID <= 0 {
+		return errors.New("invalid relationship type id")
+	}
+
+	t, err := s.repo.GetRelationshipTypeByID(ctx, typeID)
+	if err != nil {
+		return err
+	}
+
+	if t.TreeID == nil || *t.TreeID != treeID {
+		return repo.ErrRelationshipTypeNotFound
+	}
+
+	if err := s.repo.DeleteRelationshipType(ctx, t); err != nil {
+		return fmt.Errorf("service delete relationship type: %w", err)
+	}
+
+	return nil
+}
+
+// relationshipTypes — реестр типов связей дерева по коду
+type relationshipTypes map[string]models.RelationshipType
+
+// loadRelationshipTypes получает реестр дерева: встроенные типы и расширения дерева
+func loadRelationshipTypes(ctx context.Context, storage *repo.Storage, treeID int) (relationshipTypes, error) {
+	list, err := storage.GetRelationshipTypes(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relationship types: %w", err)
+	}
+
+	types := make(relationshipTypes, len(list))
+	for _, t := range list {
+		types[t.Code] = t
+	}
+	return types, nil
+}
+
+// get возвращает тип по коду
+func (t relationshipTypes) get(code string) (models.RelationshipType, error) {
+	rt, ok := t[code]
+	if !ok {
+		return models.RelationshipType{}, fmt.Errorf("%w %q", repo.ErrUnknownRelationshipType, code)
+	}
+	return rt, nil
+}
+
+// legacy возвращает тип по коду для уже сохранённых связей. Тип вне реестра (старые данные)
+// считается обычным родительским: входит в лимит родителей, как и в БД
+// (relationship_counts_toward_parents), и учитывается в кровном родстве.
+func (t relationshipTypes) legacy(code string) models.RelationshipType {
+	if rt, ok := t[code]; ok {
+		return rt
+	}
+	return models.RelationshipType{Code: code, Name: code, CountsTowardParents: true, Blood: true}
+}
+
+// countsTowardParents — связь входит в лимит двух родителей
+func (t relationshipTypes) countsTowardParents(code string) bool {
+	return t.legacy(code).CountsTowardParents
+}
+
+// blood оставляет связи, которые учитываются в кровном родстве
+func (t relationshipTypes) blood(relationships []models.Relationship) []models.Relationship {
+	var blood []models.Relationship
+	for _, rel := range relationships {
+		if t.legacy(rel.RelationshipType).Blood {
+			blood = append(blood, rel)
+		}
+	}
+	return blood
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/phonetic"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Вес совпадения слова запроса со словом имени
+const (
+	matchExact   = 100 // совпало написание (после транслитерации)
+	matchPrefix  = 80  // запрос — начало имени: "ivan" → "ivanova"
+	matchDM      = 60  // Daitch–Mokotoff: славянские и еврейские фамилии
+	matchSoundex = 50
+)
+
+const (
+	// minPrefixLength — короче этого запрос ищется только целым словом
+	minPrefixLength    = 2
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+// nameKey — слово имени в формах для сравнения
+type nameKey struct {
+	word    string
+	soundex string
+	dm      []string
+}
+
+func newNameKey(word string) nameKey {
+	return nameKey{
+		word:    word,
+		soundex: phonetic.Soundex(word),
+		dm:      phonetic.DaitchMokotoff(word),
+	}
+}
+
+// RankPersons ищет персоны по имени и году рождения и сортирует их по убыванию оценки.
+// Каждое слово запроса должно совпасть с каким-нибудь словом имени или фамилии:
+// без учёта регистра, в другой письменности ("Иванов" — "Ivanov") или по звучанию.
+// Персоны с неизвестной датой рождения при фильтре по году не попадают в выдачу.
+// Возвращает не больше limit лучших совпадений и общее число совпадений до ограничения.
+func RankPersons(persons []models.Person, search models.PersonSearch) ([]models.PersonMatch, int, error) {
+	var query []nameKey
+	for _, word := range phonetic.Tokens(search.Query) {
+		query = append(query, newNameKey(word))
+	}
+
+	if len(query) == 0 && search.BornFrom == 0 && search.BornTo == 0 {
+		return nil, 0, errors.New("search query or birth year range is required")
+	}
+	if search.BornFrom != 0 && search.BornTo != 0 && search.BornFrom > search.BornTo {
+		return nil, 0, errors.New("born_from cannot be after born_to")
+	}
+
+	limit := search.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+
+	var matches []models.PersonMatch
+	for _, p := range persons {
+		if !bornWithin(p.BirthDate, search.BornFrom, search.BornTo) {
+			continue
+		}
+
+		score := matchName(query, p.FirstName+" "+p.LastName)
+		if score == 0 {
+			continue
+		}
+		matches = append(matches, models.PersonMatch{Person: p, Score: score})
+	}
+
+	slices.SortStableFunc(matches, func(a, b models.PersonMatch) int {
+		if a.Score != b.Score {
+			return b.Score - a.Score
+		}
+		if c := strings.Compare(a.LastName, b.LastName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.FirstName, b.FirstName)
+	})
+
+	total := len(matches)
+	if total > limit {
+		matches = matches[:limit]
+	}
+	return matches, total, nil
+}
+
+// matchName возвращает среднюю оценку слов запроса или 0, если какое-то слово не нашлось.
+// Пустой запрос подходит любому имени.
+func matchName(query []nameKey, name string) int {
+	if len(query) == 0 {
+		return matchExact
+	}
+	return matchWords(query, nameKeys(name))
+}
+
+// nameKeys разбивает имя на слова для сравнения
+func nameKeys(name string) []nameKey {
+	var words []nameKey
+	for _, word := range phonetic.Tokens(name) {
+		words = append(words, newNameKey(word))
+	}
+	return words
+}
+
+// matchWords — средняя оценка слов query среди words или 0, если какое-то слово не нашлось
+func matchWords(query, words []nameKey) int {
+	total := 0
+	for _, q := range query {
+		best := 0
+		for _, w := range words {
+			best = max(best, matchWord(q, w))
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+
+	return total / len(query)
+}
+
+func matchWord(q, w nameKey) int {
+	switch {
+	case q.word == w.word:
+		return matchExact
+	case len(q.word) >= minPrefixLength && strings.HasPrefix(w.word, q.word):
+		return matchPrefix
+	case slices.ContainsFunc(q.dm, func(code string) bool { return slices.Contains(w.dm, code) }):
+		return matchDM
+	case q.soundex != "" && q.soundex == w.soundex:
+		return matchSoundex
+	default:
+		return 0
+	}
+}
+
+// bornWithin — персона могла родиться в годы [from, to]; 0 — граница не задана
+func bornWithin(birth *models.GenDate, from, to int) bool {
+	if from == 0 && to == 0 {
+		return true
+	}
+	if birth == nil {
+		return false
+	}
+
+	if from != 0 && !birth.CanBeAfterTime(time.Date(from, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)) {
+		return false
+	}
+	if to != 0 && !birth.CanBeBeforeTime(time.Date(to+1, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		return false
+	}
+	return true
+}
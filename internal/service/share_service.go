@@ -0,0 +1,115 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// shareTokenBytes — длина случайной части токена ссылки
+const shareTokenBytes = 32
+
+// ShareService управляет публичными ссылками на деревья
+type ShareService struct {
+	repo *repo.Storage
+}
+
+func NewShareService(storage *repo.Storage) *ShareService {
+	return &ShareService{
+		repo: storage,
+	}
+}
+
+// CreateShareLink создаёт ссылку на дерево. Возвращает токен —
+// он показывается только один раз, в БД хранится его хэш.
+func (s *ShareService) CreateShareLink(ctx context.Context, treeID, userID int, expiresAt *time.Time) (string, *models.ShareLink, error) {
+	if treeID <= 0 {
+		return "", nil, errors.New("invalid tree id")
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return "", nil, errors.New("expiration must be in the future")
+	}
+
+	raw := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	link := &models.ShareLink{
+		TreeID:    treeID,
+		TokenHash: hashShareToken(token),
+		CreatedBy: userID,
+		ExpiresAt: expiresAt,
+	}
+
+	if _, err := s.repo.CreateShareLink(ctx, link); err != nil {
+		return "", nil, fmt.Errorf("service create share link: %w", err)
+	}
+
+	return token, link, nil
+}
+
+// GetShareLinks получает все ссылки дерева
+func (s *ShareService) GetShareLinks(ctx context.Context, treeID int) ([]models.ShareLink, error) {
+	if treeID <= 0 {
+		return nil, errors.New("invalid tree id")
+	}
+
+	links, err := s.repo.GetShareLinksByTreeID(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get share links: %w", err)
+	}
+
+	return links, nil
+}
+
+// RevokeShareLink отзывает ссылку дерева
+func (s *ShareService) RevokeShareLink(ctx context.Context, treeID, linkID int) error {
+	if treeID <= 0 || linkID <= 0 {
+		return errors.New("invalid tree or link id")
+	}
+
+	if err := s.repo.RevokeShareLink(ctx, treeID, linkID); err != nil {
+		return fmt.Errorf("service revoke share link: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveToken находит дерево по токену ссылки.
+// Неизвестный, отозванный и истёкший токены неотличимы (ErrShareLinkNotFound).
+func (s *ShareService) ResolveToken(ctx context.Context, token string) (*models.Tree, error) {
+	if token == "" {
+		return nil, repo.ErrShareLinkNotFound
+	}
+
+	link, err := s.repo.GetShareLinkByTokenHash(ctx, hashShareToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("resolve share token: %w", err)
+	}
+
+	if !link.IsActive(time.Now()) {
+		return nil, repo.ErrShareLinkNotFound
+	}
+
+	tree, err := s.repo.GetTreeByID(ctx, link.TreeID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve share token: %w", err)
+	}
+
+	return tree, nil
+}
+
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,477 @@
+package service
+
+import (
+	"GenealogyTree/internal/models"
+	"GenealogyTree/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Факты персоны, которые может подтверждать цитата
+var personFactFields = []string{
+	models.FactName, models.FactSex,
+	models.FactBirthDate, models.FactBirthPlace,
+	models.FactDeathDate, models.FactDeathPlace,
+}
+
+// Шкала качества цитаты (GEDCOM QUAY)
+const (
+	minCitationQuality = 0
+	maxCitationQuality = 3
+)
+
+type SourceService struct {
+	repo    *repo.Storage
+	privacy *PrivacyService // Скрытие цитат живых и закрытых персон
+}
+
+func NewSourceService(storage *repo.Storage, privacy *PrivacyService) *SourceService {
+	return &SourceService{
+		repo:    storage,
+		privacy: privacy,
+	}
+}
+
+// GetSources получает все источники дерева
+func (s *SourceService) GetSources(ctx context.Context, treeID int) ([]models.Source, error) {
+	if treeID <= 0 {
+		return nil, errors.New("invalid tree id")
+	}
+
+	sources, err := s.repo.GetSourcesByTreeID(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get sources: %w", err)
+	}
+
+	return sources, nil
+}
+
+// GetSource получает источник дерева
+func (s *SourceService) GetSource(ctx context.Context, treeID, sourceID int) (*models.Source, error) {
+	if treeID <= 0 || sourceID <= 0 {
+		return nil, errors.New("invalid tree or source id")
+	}
+
+	src, err := s.repo.GetSourceByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Источник чужого дерева считаем ненайденным
+	if src.TreeID != treeID {
+		return nil, repo.ErrSourceNotFound
+	}
+
+	return src, nil
+}
+
+// CreateSource создаёт источник в дереве
+func (s *SourceService) CreateSource(ctx context.Context, src *models.Source) (int, error) {
+	if err := s.validateSource(src); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.CreateSource(ctx, src)
+	if err != nil {
+		return 0, fmt.Errorf("service create source: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateSource обновляет источник дерева
+func (s *SourceService) UpdateSource(ctx context.Context, src *models.Source) error {
+	existing, err := s.GetSource(ctx, src.TreeID, src.ID)
+	if err != nil {
+		return err
+	}
+	src.CreatedAt = existing.CreatedAt
+
+	if err := s.validateSource(src); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateSource(ctx, src); err != nil {
+		return fmt.Errorf("service update source: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSource удаляет источник дерева вместе с цитатами
+func (s *SourceService) DeleteSource(ctx context.Context, treeID, sourceID int) error {
+	if _, err := s.GetSource(ctx, treeID, sourceID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteSource(ctx, sourceID); err != nil {
+		return fmt.Errorf("service delete source: %w", err)
+	}
+
+	return nil
+}
+
+// GetCitations получает цитаты дерева; personID или relationshipID > 0 оставляют только цитаты персоны или связи.
+// redact — цитаты смотрит зритель: цитаты скрытых персон и связей со скрытой персоной не отдаются.
+func (s *SourceService) GetCitations(ctx context.Context, treeID, personID, relationshipID int, redact bool) ([]models.Citation, error) {
+	if treeID <= 0 {
+		return nil, errors.New("invalid tree id")
+	}
+
+	citations, err := s.repo.GetCitationsByTreeID(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get citations: %w", err)
+	}
+
+	var hidden *hiddenFacts
+	if redact {
+		graph, err := s.repo.GetTreeGraph(ctx, treeID)
+		if err != nil {
+			return nil, fmt.Errorf("service get citations: %w", err)
+		}
+		hidden = s.hiddenFacts(graph)
+	}
+
+	filtered := citations[:0]
+	for _, c := range citations {
+		if personID > 0 && (c.PersonID == nil || *c.PersonID != personID) {
+			continue
+		}
+		if relationshipID > 0 && (c.RelationshipID == nil || *c.RelationshipID != relationshipID) {
+			continue
+		}
+		if hidden.citation(&c) {
+			continue
+		}
+		filtered = append(filtered, c)
+	}
+
+	return filtered, nil
+}
+
+// hiddenFacts — персоны дерева, скрытые от зрителя, и связи, у которых скрыт родитель
+// или ребенок. Цитаты и факты скрываются по одному правилу; nil ничего не скрывает.
+type hiddenFacts struct {
+	persons       map[int]bool
+	relationships map[int]bool
+}
+
+// hiddenFacts находит скрытые персоны и связи графа дерева
+func (s *SourceService) hiddenFacts(graph *models.TreeGraph) *hiddenFacts {
+	now := time.Now()
+	h := &hiddenFacts{
+		persons:       make(map[int]bool),
+		relationships: make(map[int]bool),
+	}
+	for i := range graph.Persons {
+		if s.privacy.IsHidden(&graph.Persons[i], now) {
+			h.persons[graph.Persons[i].ID] = true
+		}
+	}
+	for _, rel := range graph.Relationships {
+		if h.persons[rel.ParentID] || h.persons[rel.ChildID] {
+			h.relationships[rel.ID] = true
+		}
+	}
+	return h
+}
+
+// hides — персона personID или связь relationshipID скрыта
+func (h *hiddenFacts) hides(personID, relationshipID *int) bool {
+	if h == nil {
+		return false
+	}
+	return (personID != nil && h.persons[*personID]) ||
+		(relationshipID != nil && h.relationships[*relationshipID])
+}
+
+// citation — цитата относится к скрытой персоне или связи
+func (h *hiddenFacts) citation(c *models.Citation) bool {
+	return h.hides(c.PersonID, c.RelationshipID)
+}
+
+// fact — факт относится к скрытой персоне или связи
+func (h *hiddenFacts) fact(f *models.Fact) bool {
+	var personID, relationshipID *int
+	if f.Person != nil {
+		personID = &f.Person.ID
+	}
+	if f.Relationship != nil {
+		relationshipID = &f.Relationship.ID
+	}
+	return h.hides(personID, relationshipID)
+}
+
+// CreateCitation прикрепляет источник к персоне или связи дерева
+func (s *SourceService) CreateCitation(ctx context.Context, treeID int, c *models.Citation) (int, error) {
+	if _, err := s.GetSource(ctx, treeID, c.SourceID); err != nil {
+		return 0, err
+	}
+
+	if err := validateCitationQuality(c.Quality); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case c.PersonID != nil && c.RelationshipID != nil:
+		return 0, errors.New("citation must be attached to a person or a relationship, not both")
+
+	case c.PersonID != nil:
+		person, err := s.repo.GetPersonByID(ctx, *c.PersonID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get person: %w", err)
+		}
+		if person.TreeID != treeID {
+			return 0, repo.ErrPersonNotFound
+		}
+		if c.Field != "" && !slices.Contains(personFactFields, c.Field) {
+			return 0, fmt.Errorf("citation field must be one of %v", personFactFields)
+		}
+
+	case c.RelationshipID != nil:
+		rel, err := s.repo.GetRelationshipByID(ctx, *c.RelationshipID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get relationship: %w", err)
+		}
+		parent, err := s.repo.GetPersonByID(ctx, rel.ParentID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get parent: %w", err)
+		}
+		if parent.TreeID != treeID {
+			return 0, repo.ErrRelationshipNotFound
+		}
+		if c.Field != "" {
+			return 0, errors.New("relationship citation cannot have a field")
+		}
+
+	default:
+		return 0, errors.New("citation must be attached to a person or a relationship")
+	}
+
+	id, err := s.repo.CreateCitation(ctx, c)
+	if err != nil {
+		return 0, fmt.Errorf("service create citation: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateCitation обновляет страницу и качество цитаты; к чему она прикреплена, не меняется
+func (s *SourceService) UpdateCitation(ctx context.Context, treeID int, c *models.Citation) error {
+	existing, err := s.getTreeCitation(ctx, treeID, c.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateCitationQuality(c.Quality); err != nil {
+		return err
+	}
+
+	c.SourceID = existing.SourceID
+	c.PersonID = existing.PersonID
+	c.RelationshipID = existing.RelationshipID
+	c.Field = existing.Field
+	c.CreatedAt = existing.CreatedAt
+
+	if err := s.repo.UpdateCitation(ctx, c); err != nil {
+		return fmt.Errorf("service update citation: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCitation удаляет цитату дерева
+func (s *SourceService) DeleteCitation(ctx context.Context, treeID, citationID int) error {
+	if _, err := s.getTreeCitation(ctx, treeID, citationID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteCitation(ctx, citationID); err != nil {
+		return fmt.Errorf("service delete citation: %w", err)
+	}
+
+	return nil
+}
+
+// GetSourceFacts получает все факты дерева, которые подтверждает источник.
+// redact — как в GetCitations: факты скрытых персон и их связей не отдаются.
+func (s *SourceService) GetSourceFacts(ctx context.Context, treeID, sourceID int, redact bool) ([]models.CitedFact, error) {
+	if _, err := s.GetSource(ctx, treeID, sourceID); err != nil {
+		return nil, err
+	}
+
+	citations, err := s.repo.GetCitationsBySourceID(ctx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("service get source facts: %w", err)
+	}
+
+	graph, err := s.repo.GetTreeGraph(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get source facts: %w", err)
+	}
+
+	persons := make(map[int]*models.Person, len(graph.Persons))
+	for i := range graph.Persons {
+		persons[graph.Persons[i].ID] = &graph.Persons[i]
+	}
+	relationships := make(map[int]*models.Relationship, len(graph.Relationships))
+	for i := range graph.Relationships {
+		relationships[graph.Relationships[i].ID] = &graph.Relationships[i]
+	}
+
+	var hidden *hiddenFacts
+	if redact {
+		hidden = s.hiddenFacts(graph)
+	}
+
+	var facts []models.CitedFact
+	for _, c := range citations {
+		if hidden.citation(&c) {
+			continue
+		}
+		fact := models.CitedFact{Citation: c}
+		switch {
+		case c.PersonID != nil:
+			fact.Person = persons[*c.PersonID]
+			fact.Field = c.Field
+		case c.RelationshipID != nil:
+			fact.Relationship = relationships[*c.RelationshipID]
+			fact.Field = models.FactRelationship
+		}
+		if fact.Person == nil && fact.Relationship == nil {
+			continue
+		}
+		facts = append(facts, fact)
+	}
+
+	return facts, nil
+}
+
+// GetUncitedFacts находит факты дерева без единой цитаты.
+// Цитата персоны без поля подтверждает все её факты.
+// redact — факты скрытых персон и их связей не отдаются.
+func (s *SourceService) GetUncitedFacts(ctx context.Context, treeID int, redact bool) ([]models.Fact, error) {
+	citations, err := s.GetCitations(ctx, treeID, 0, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := s.repo.GetTreeGraph(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get uncited facts: %w", err)
+	}
+
+	var hidden *hiddenFacts
+	if redact {
+		hidden = s.hiddenFacts(graph)
+	}
+
+	type personFact struct {
+		personID int
+		field    string
+	}
+	citedPersons := make(map[int]bool)
+	citedFacts := make(map[personFact]bool)
+	citedRelationships := make(map[int]bool)
+	for _, c := range citations {
+		switch {
+		case c.PersonID != nil && c.Field == "":
+			citedPersons[*c.PersonID] = true
+		case c.PersonID != nil:
+			citedFacts[personFact{*c.PersonID, c.Field}] = true
+		case c.RelationshipID != nil:
+			citedRelationships[*c.RelationshipID] = true
+		}
+	}
+
+	var facts []models.Fact
+	for i := range graph.Persons {
+		person := &graph.Persons[i]
+		if citedPersons[person.ID] {
+			continue
+		}
+		for _, field := range personFacts(person) {
+			if !citedFacts[personFact{person.ID, field}] {
+				facts = append(facts, models.Fact{Person: person, Field: field})
+			}
+		}
+	}
+	for i := range graph.Relationships {
+		rel := &graph.Relationships[i]
+		if !citedRelationships[rel.ID] {
+			facts = append(facts, models.Fact{Relationship: rel, Field: models.FactRelationship})
+		}
+	}
+
+	visible := facts[:0]
+	for i := range facts {
+		if !hidden.fact(&facts[i]) {
+			visible = append(visible, facts[i])
+		}
+	}
+
+	return visible, nil
+}
+
+// personFacts возвращает известные факты персоны: имя и пол есть всегда, даты и места — если заданы
+func personFacts(p *models.Person) []string {
+	facts := []string{models.FactName, models.FactSex}
+	if p.BirthDate != nil {
+		facts = append(facts, models.FactBirthDate)
+	}
+	if p.BirthPlaceID != nil {
+		facts = append(facts, models.FactBirthPlace)
+	}
+	if p.DeathDate != nil {
+		facts = append(facts, models.FactDeathDate)
+	}
+	if p.DeathPlaceID != nil {
+		facts = append(facts, models.FactDeathPlace)
+	}
+	return facts
+}
+
+// getTreeCitation получает цитату и проверяет что её источник из этого дерева
+func (s *SourceService) getTreeCitation(ctx context.Context, treeID, citationID int) (*models.Citation, error) {
+	if treeID <= 0 || citationID <= 0 {
+		return nil, errors.New("invalid tree or citation id")
+	}
+
+	c, err := s.repo.GetCitationByID(ctx, citationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetSource(ctx, treeID, c.SourceID); err != nil {
+		if errors.Is(err, repo.ErrSourceNotFound) {
+			return nil, repo.ErrCitationNotFound
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// validateSource проверяет источник
+func (s *SourceService) validateSource(src *models.Source) error {
+	src.Title = strings.TrimSpace(src.Title)
+	if src.Title == "" {
+		return errors.New("source title is required")
+	}
+	if src.TreeID <= 0 {
+		return errors.New("tree id is required")
+	}
+	return nil
+}
+
+// validateCitationQuality проверяет качество цитаты; nil — не оценено
+func validateCitationQuality(quality *int) error {
+	if quality != nil && (*quality < minCitationQuality || *quality > maxCitationQuality) {
+		return fmt.Errorf("citation quality must be between %d and %d", minCitationQuality, maxCitationQuality)
+	}
+	return nil
+}
@@ -50,17 +50,28 @@ func (s *TreeService) GetTreeByID(ctx context.Context, id int) (*models.Tree, er
 	return tree, nil
 }
 
-func (s *TreeService) GetTreesByOwnerID(ctx context.Context, ownerID int) ([]models.Tree, error) {
-	if ownerID <= 0 {
-		return nil, errors.New("invalid owner id")
+// GetTreesByUserID получает страницу деревьев пользователя: свои и те, куда его пригласили.
+// Деревья идут от новых к старым.
+func (s *TreeService) GetTreesByUserID(ctx context.Context, userID int, query models.TreeListQuery) (*models.TreePage, error) {
+	if userID <= 0 {
+		return nil, errors.New("invalid user id")
 	}
 
-	trees, err := s.repo.GetTreesByOwnerID(ctx, ownerID)
+	page, err := listPage("", true, query.Limit, query.Cursor)
 	if err != nil {
-		return nil, fmt.Errorf("service get trees by owner: %w", err)
+		return nil, err
 	}
 
-	return trees, nil
+	trees, total, next, err := s.repo.GetTreesByUserID(ctx, userID, page)
+	if err != nil {
+		return nil, fmt.Errorf("service get trees by user: %w", err)
+	}
+
+	return &models.TreePage{
+		Trees:      trees,
+		Total:      total,
+		NextCursor: encodeCursor(next),
+	}, nil
 }
 
 func (s *TreeService) UpdateTree(ctx context.Context, t *models.Tree) error {
@@ -91,23 +102,64 @@ func (s *TreeService) DeleteTree(ctx context.Context, id int) error {
 	return nil
 }
 
-// GetTreeGraph получает граф дерева (персоны + связи)
-func (s *TreeService) GetTreeGraph(ctx context.Context, treeID int) ([]models.Person, []models.Relationship, error) {
+// GetTreeGraph получает граф дерева (персоны + связи + союзы)
+func (s *TreeService) GetTreeGraph(ctx context.Context, treeID int) (*models.TreeGraph, error) {
 	if treeID <= 0 {
-		return nil, nil, errors.New("invalid tree id")
+		return nil, errors.New("invalid tree id")
 	}
 
 	_, err := s.repo.GetTreeByID(ctx, treeID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get tree: %w", err)
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	graph, err := s.repo.GetTreeGraph(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get tree graph: %w", err)
+	}
+
+	return graph, nil
+}
+
+// GetIntegrityReport ищет циклы в связях дерева (например, в старых данных)
+func (s *TreeService) GetIntegrityReport(ctx context.Context, treeID int) (*models.IntegrityReport, error) {
+	if treeID <= 0 {
+		return nil, errors.New("invalid tree id")
+	}
+
+	graph, err := s.repo.GetTreeGraph(ctx, treeID)
+	if err != nil {
+		return nil, fmt.Errorf("service get integrity report: %w", err)
+	}
+
+	return &models.IntegrityReport{
+		TreeID: treeID,
+		Cycles: newFamilyGraph(graph.Relationships).cycles(),
+	}, nil
+}
+
+// FindDuplicates ищет в дереве возможные дубли персон и возвращает не больше limit
+// самых вероятных пар вместе с общим числом найденных
+func (s *TreeService) FindDuplicates(ctx context.Context, treeID, limit int) ([]models.DuplicatePair, int, error) {
+	if treeID <= 0 {
+		return nil, 0, errors.New("invalid tree id")
+	}
+	if limit <= 0 {
+		limit = defaultPageLimit
 	}
 
-	persons, relationships, err := s.repo.GetTreeGraph(ctx, treeID)
+	graph, err := s.repo.GetTreeGraph(ctx, treeID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("service get tree graph: %w", err)
+		return nil, 0, fmt.Errorf("service find duplicates: %w", err)
+	}
+
+	pairs := FindDuplicates(graph)
+	total := len(pairs)
+	if len(pairs) > min(limit, maxPageLimit) {
+		pairs = pairs[:min(limit, maxPageLimit)]
 	}
 
-	return persons, relationships, nil
+	return pairs, total, nil
 }
 
 func (s *TreeService) validateTree(t *models.Tree) error {
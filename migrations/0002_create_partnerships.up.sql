@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS partnerships
+(
+    id               SERIAL PRIMARY KEY,
+    person1_id       INTEGER     NOT NULL REFERENCES persons (id) ON DELETE CASCADE,
+    person2_id       INTEGER     NOT NULL REFERENCES persons (id) ON DELETE CASCADE,
+    partnership_type VARCHAR(20) NOT NULL,
+    status           VARCHAR(20) NOT NULL,
+    start_date       DATE,
+    end_date         DATE,
+    created_at       TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMP   NOT NULL DEFAULT NOW(),
+    -- пара хранится упорядоченно, чтобы (A, B) и (B, A) не различались
+    CHECK (person1_id < person2_id)
+);
+
+CREATE INDEX idx_partnerships_person1 ON partnerships (person1_id);
+CREATE INDEX idx_partnerships_person2 ON partnerships (person2_id);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS tree_members
+(
+    tree_id    INTEGER     NOT NULL REFERENCES trees (id) ON DELETE CASCADE,
+    user_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       VARCHAR(20) NOT NULL CHECK (role IN ('viewer', 'editor', 'admin')),
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    -- владелец хранится в trees.owner_id, здесь только приглашённые
+    PRIMARY KEY (tree_id, user_id)
+);
+
+CREATE INDEX idx_tree_members_user ON tree_members (user_id);
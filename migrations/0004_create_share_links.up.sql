@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS share_links
+(
+    id         SERIAL PRIMARY KEY,
+    tree_id    INTEGER   NOT NULL REFERENCES trees (id) ON DELETE CASCADE,
+    -- хранится только SHA-256 токена, сам токен показывается один раз
+    token_hash CHAR(64)  NOT NULL UNIQUE,
+    created_by INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_share_links_tree ON share_links (tree_id);
@@ -0,0 +1,3 @@
+ALTER TABLE persons
+    ADD COLUMN privacy VARCHAR(10) NOT NULL DEFAULT 'auto'
+        CHECK (privacy IN ('auto', 'public', 'private'));
@@ -0,0 +1,9 @@
+ALTER TABLE persons
+    DROP CONSTRAINT IF EXISTS persons_birth_date_range,
+    DROP CONSTRAINT IF EXISTS persons_death_date_range,
+    DROP COLUMN IF EXISTS birth_date_end,
+    DROP COLUMN IF EXISTS birth_date_qualifier,
+    DROP COLUMN IF EXISTS birth_date_precision,
+    DROP COLUMN IF EXISTS death_date_end,
+    DROP COLUMN IF EXISTS death_date_qualifier,
+    DROP COLUMN IF EXISTS death_date_precision;
@@ -0,0 +1,24 @@
+ALTER TABLE persons
+    ADD COLUMN birth_date_end DATE,
+    ADD COLUMN birth_date_qualifier VARCHAR(10)
+        CHECK (birth_date_qualifier IN ('', 'about', 'calculated', 'estimated', 'before', 'after', 'between')),
+    ADD COLUMN birth_date_precision VARCHAR(5)
+        CHECK (birth_date_precision IN ('day', 'month', 'year')),
+    ADD COLUMN death_date_end DATE,
+    ADD COLUMN death_date_qualifier VARCHAR(10)
+        CHECK (death_date_qualifier IN ('', 'about', 'calculated', 'estimated', 'before', 'after', 'between')),
+    ADD COLUMN death_date_precision VARCHAR(5)
+        CHECK (death_date_precision IN ('day', 'month', 'year'));
+
+-- Существующие даты точные
+UPDATE persons
+SET birth_date_end = birth_date, birth_date_qualifier = '', birth_date_precision = 'day'
+WHERE birth_date IS NOT NULL;
+
+UPDATE persons
+SET death_date_end = death_date, death_date_qualifier = '', death_date_precision = 'day'
+WHERE death_date IS NOT NULL;
+
+ALTER TABLE persons
+    ADD CONSTRAINT persons_birth_date_range CHECK (birth_date_end >= birth_date),
+    ADD CONSTRAINT persons_death_date_range CHECK (death_date_end >= death_date);
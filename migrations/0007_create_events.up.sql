@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS places
+(
+    id         SERIAL PRIMARY KEY,
+    tree_id    INTEGER      NOT NULL REFERENCES trees (id) ON DELETE CASCADE,
+    name       VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    UNIQUE (tree_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS events
+(
+    id             SERIAL PRIMARY KEY,
+    person_id      INTEGER REFERENCES persons (id) ON DELETE CASCADE,
+    partnership_id INTEGER REFERENCES partnerships (id) ON DELETE CASCADE,
+    event_type     VARCHAR(20) NOT NULL,
+    date           DATE,
+    date_end       DATE,
+    date_qualifier VARCHAR(10)
+        CHECK (date_qualifier IN ('', 'about', 'calculated', 'estimated', 'before', 'after', 'between')),
+    date_precision VARCHAR(5)
+        CHECK (date_precision IN ('day', 'month', 'year')),
+    place_id       INTEGER REFERENCES places (id) ON DELETE SET NULL,
+    description    TEXT        NOT NULL DEFAULT '',
+    created_at     TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMP   NOT NULL DEFAULT NOW(),
+    -- событие принадлежит либо персоне, либо союзу
+    CHECK ((person_id IS NULL) <> (partnership_id IS NULL)),
+    CHECK (date_end >= date)
+);
+
+CREATE INDEX idx_events_person ON events (person_id);
+CREATE INDEX idx_events_partnership ON events (partnership_id);
+
+-- Рождение и смерть у персоны одни; они синхронизированы с persons.birth_date/death_date
+CREATE UNIQUE INDEX idx_events_vital ON events (person_id, event_type)
+    WHERE event_type IN ('birth', 'death');
+
+INSERT INTO events (person_id, event_type, date, date_end, date_qualifier, date_precision)
+SELECT id, 'birth', birth_date, birth_date_end, birth_date_qualifier, birth_date_precision
+FROM persons
+WHERE birth_date IS NOT NULL;
+
+INSERT INTO events (person_id, event_type, date, date_end, date_qualifier, date_precision)
+SELECT id, 'death', death_date, death_date_end, death_date_qualifier, death_date_precision
+FROM persons
+WHERE death_date IS NOT NULL;
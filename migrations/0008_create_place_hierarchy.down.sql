@@ -0,0 +1,14 @@
+ALTER TABLE persons
+    DROP COLUMN IF EXISTS birth_place_id,
+    DROP COLUMN IF EXISTS death_place_id;
+
+DROP TABLE IF EXISTS place_names;
+
+ALTER TABLE places
+    DROP CONSTRAINT IF EXISTS places_tree_parent_name_key,
+    DROP COLUMN IF EXISTS parent_id,
+    DROP COLUMN IF EXISTS place_type,
+    DROP COLUMN IF EXISTS latitude,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS updated_at,
+    ADD CONSTRAINT places_tree_id_name_key UNIQUE (tree_id, name);
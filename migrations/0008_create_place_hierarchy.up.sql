@@ -0,0 +1,42 @@
+ALTER TABLE places
+    DROP CONSTRAINT IF EXISTS places_tree_id_name_key,
+    ADD COLUMN parent_id  INTEGER REFERENCES places (id) ON DELETE SET NULL,
+    ADD COLUMN place_type VARCHAR(20) NOT NULL DEFAULT 'other'
+        CHECK (place_type IN ('village', 'city', 'district', 'region', 'country', 'other')),
+    ADD COLUMN latitude   DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN longitude  DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- координаты задаются парой
+    ADD CHECK ((latitude IS NULL) = (longitude IS NULL)),
+    -- одноимённые места допустимы только у разных родителей
+    ADD CONSTRAINT places_tree_parent_name_key UNIQUE NULLS NOT DISTINCT (tree_id, parent_id, name);
+
+CREATE INDEX idx_places_parent ON places (parent_id);
+
+-- Исторические названия места: "Кёнигсберг" до 1946 года
+CREATE TABLE IF NOT EXISTS place_names
+(
+    id        SERIAL PRIMARY KEY,
+    place_id  INTEGER      NOT NULL REFERENCES places (id) ON DELETE CASCADE,
+    name      VARCHAR(255) NOT NULL,
+    from_year INTEGER,
+    to_year   INTEGER,
+    CHECK (to_year >= from_year)
+);
+
+CREATE INDEX idx_place_names_place ON place_names (place_id);
+
+ALTER TABLE persons
+    ADD COLUMN birth_place_id INTEGER REFERENCES places (id) ON DELETE SET NULL,
+    ADD COLUMN death_place_id INTEGER REFERENCES places (id) ON DELETE SET NULL;
+
+-- Места рождения и смерти синхронизированы с событиями birth/death
+UPDATE persons p
+SET birth_place_id = e.place_id
+FROM events e
+WHERE e.person_id = p.id AND e.event_type = 'birth';
+
+UPDATE persons p
+SET death_place_id = e.place_id
+FROM events e
+WHERE e.person_id = p.id AND e.event_type = 'death';
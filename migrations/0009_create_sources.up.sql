@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS sources
+(
+    id          SERIAL PRIMARY KEY,
+    tree_id     INTEGER   NOT NULL REFERENCES trees (id) ON DELETE CASCADE,
+    title       TEXT      NOT NULL,
+    author      TEXT      NOT NULL DEFAULT '',
+    repository  TEXT      NOT NULL DEFAULT '',
+    url         TEXT      NOT NULL DEFAULT '',
+    archive_ref TEXT      NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sources_tree ON sources (tree_id);
+
+CREATE TABLE IF NOT EXISTS citations
+(
+    id              SERIAL PRIMARY KEY,
+    source_id       INTEGER   NOT NULL REFERENCES sources (id) ON DELETE CASCADE,
+    person_id       INTEGER REFERENCES persons (id) ON DELETE CASCADE,
+    relationship_id INTEGER REFERENCES relationships (id) ON DELETE CASCADE,
+    -- факт персоны, который подтверждает цитата; NULL — персона целиком
+    field           VARCHAR(20)
+        CHECK (field IN ('name', 'sex', 'birth_date', 'birth_place', 'death_date', 'death_place')),
+    page            TEXT      NOT NULL DEFAULT '',
+    -- качество по шкале GEDCOM QUAY: 0 — ненадёжно ... 3 — прямое свидетельство
+    quality         SMALLINT  CHECK (quality BETWEEN 0 AND 3),
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- цитата относится либо к персоне, либо к связи родитель-ребенок
+    CHECK ((person_id IS NULL) <> (relationship_id IS NULL)),
+    CHECK (field IS NULL OR person_id IS NOT NULL)
+);
+
+CREATE INDEX idx_citations_source ON citations (source_id);
+CREATE INDEX idx_citations_person ON citations (person_id);
+CREATE INDEX idx_citations_relationship ON citations (relationship_id);
@@ -0,0 +1,4 @@
+ALTER TABLE persons
+    DROP COLUMN IF EXISTS portrait_id;
+
+DROP TABLE IF EXISTS media;
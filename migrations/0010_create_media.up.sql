@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS media
+(
+    id           SERIAL PRIMARY KEY,
+    person_id    INTEGER      NOT NULL REFERENCES persons (id) ON DELETE CASCADE,
+    -- ключ файла в хранилище (локальный диск или S3)
+    storage_key  TEXT         NOT NULL,
+    -- SHA-256 содержимого в hex
+    content_hash CHAR(64)     NOT NULL,
+    mime_type    VARCHAR(100) NOT NULL,
+    size_bytes   BIGINT       NOT NULL CHECK (size_bytes > 0),
+    file_name    TEXT         NOT NULL DEFAULT '',
+    title        TEXT         NOT NULL DEFAULT '',
+    created_at   TIMESTAMP    NOT NULL DEFAULT NOW(),
+    -- один и тот же файл не загружается к персоне дважды
+    CONSTRAINT media_person_hash_key UNIQUE (person_id, content_hash)
+);
+
+CREATE INDEX idx_media_person ON media (person_id);
+
+-- Основной портрет персоны
+ALTER TABLE persons
+    ADD COLUMN portrait_id INTEGER REFERENCES media (id) ON DELETE SET NULL;
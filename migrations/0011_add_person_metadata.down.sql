@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_persons_metadata;
+
+ALTER TABLE persons
+    DROP COLUMN IF EXISTS metadata;
+
+DROP TABLE IF EXISTS custom_fields;
@@ -0,0 +1,22 @@
+-- Схема пользовательских полей дерева: клан, полк, номер метрической записи и т.п.
+CREATE TABLE IF NOT EXISTS custom_fields
+(
+    id             SERIAL PRIMARY KEY,
+    tree_id        INTEGER     NOT NULL REFERENCES trees (id) ON DELETE CASCADE,
+    -- ключ в persons.metadata
+    name           VARCHAR(50) NOT NULL CHECK (name ~ '^[a-z][a-z0-9_]*$'),
+    field_type     VARCHAR(10) NOT NULL
+        CHECK (field_type IN ('string', 'number', 'boolean', 'date', 'enum')),
+    -- допустимые значения для enum
+    allowed_values TEXT[]      NOT NULL DEFAULT '{}',
+    created_at     TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMP   NOT NULL DEFAULT NOW(),
+    CONSTRAINT custom_fields_tree_name_key UNIQUE (tree_id, name),
+    CHECK ((field_type = 'enum') = (cardinality(allowed_values) > 0))
+);
+
+ALTER TABLE persons
+    ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(metadata) = 'object');
+
+-- Фильтр персон по метаданным: metadata @> '{"clan": "MacLeod"}' и metadata ?& ARRAY['regiment']
+CREATE INDEX idx_persons_metadata ON persons USING GIN (metadata);
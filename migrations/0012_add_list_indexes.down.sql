@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_trees_owner;
+DROP INDEX IF EXISTS idx_persons_tree_updated_at;
+DROP INDEX IF EXISTS idx_persons_tree_birth_date;
+DROP INDEX IF EXISTS idx_persons_tree_name;
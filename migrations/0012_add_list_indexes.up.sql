@@ -0,0 +1,5 @@
+-- Постраничные списки: сортировки персон по ключу + id и деревья пользователя
+CREATE INDEX idx_persons_tree_name ON persons (tree_id, last_name, first_name, id);
+CREATE INDEX idx_persons_tree_birth_date ON persons (tree_id, COALESCE(birth_date, 'infinity'::date), id);
+CREATE INDEX idx_persons_tree_updated_at ON persons (tree_id, updated_at, id);
+CREATE INDEX idx_trees_owner ON trees (owner_id, created_at, id);
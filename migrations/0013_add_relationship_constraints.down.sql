@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS persons_check_relationships ON persons;
+DROP FUNCTION IF EXISTS check_person_relationships();
+
+DROP TRIGGER IF EXISTS relationships_check ON relationships;
+DROP FUNCTION IF EXISTS check_relationship();
+
+ALTER TABLE relationships
+    DROP CONSTRAINT IF EXISTS relationships_no_self_parent;
@@ -0,0 +1,80 @@
+-- Возвращаем правила 0013 без реестра типов
+CREATE OR REPLACE FUNCTION check_person_relationships() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NEW.tree_id <> OLD.tree_id AND EXISTS (
+        SELECT 1 FROM relationships WHERE NEW.id IN (parent_id, child_id)
+    ) THEN
+        RAISE EXCEPTION 'parent and child must be in the same tree'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_same_tree';
+    END IF;
+
+    IF NEW.is_male <> OLD.is_male AND EXISTS (
+        SELECT 1
+        FROM relationships r
+                 INNER JOIN relationships o ON o.child_id = r.child_id AND o.parent_id <> r.parent_id
+                 INNER JOIN persons p ON p.id = o.parent_id
+        WHERE r.parent_id = NEW.id
+          AND p.is_male = NEW.is_male
+    ) THEN
+        RAISE EXCEPTION 'parents must be of different gender'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_parents_sex';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION check_relationship() RETURNS TRIGGER AS
+$$
+DECLARE
+    child_tree  INTEGER;
+    parent_tree INTEGER;
+    parent_male BOOLEAN;
+    others      INTEGER;
+    same_sex    INTEGER;
+BEGIN
+    SELECT tree_id INTO child_tree FROM persons WHERE id = NEW.child_id FOR UPDATE;
+    SELECT tree_id, is_male INTO parent_tree, parent_male FROM persons WHERE id = NEW.parent_id;
+
+    IF child_tree IS NULL OR parent_tree IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF parent_tree <> child_tree THEN
+        RAISE EXCEPTION 'parent and child must be in the same tree'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_same_tree';
+    END IF;
+
+    SELECT COUNT(*), COUNT(*) FILTER (WHERE p.is_male = parent_male)
+    INTO others, same_sex
+    FROM relationships r
+             INNER JOIN persons p ON p.id = r.parent_id
+    WHERE r.child_id = NEW.child_id
+      AND r.id <> NEW.id
+      AND r.parent_id <> NEW.parent_id;
+
+    IF others >= 2 THEN
+        RAISE EXCEPTION 'child already has 2 parents'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_max_two_parents';
+    END IF;
+
+    IF same_sex > 0 THEN
+        RAISE EXCEPTION 'parents must be of different gender'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_parents_sex';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS relationships_check ON relationships;
+CREATE TRIGGER relationships_check
+    BEFORE INSERT OR UPDATE OF parent_id, child_id
+    ON relationships
+    FOR EACH ROW
+EXECUTE FUNCTION check_relationship();
+
+DROP FUNCTION IF EXISTS relationship_counts_toward_parents(TEXT, INTEGER);
+
+DROP TABLE IF EXISTS relationship_types;
@@ -0,0 +1,139 @@
+-- Реестр типов связей родитель-ребенок: встроенные типы (tree_id IS NULL) и расширения дерева
+CREATE TABLE IF NOT EXISTS relationship_types
+(
+    id                    SERIAL PRIMARY KEY,
+    tree_id               INTEGER REFERENCES trees (id) ON DELETE CASCADE,
+    -- значение relationships.relationship_type
+    code                  VARCHAR(20)  NOT NULL CHECK (code ~ '^[a-z][a-z0-9_]*$'),
+    name                  VARCHAR(100) NOT NULL,
+    -- связь входит в лимит двух родителей и в проверку их пола
+    counts_toward_parents BOOLEAN      NOT NULL,
+    -- связь учитывается при расчёте кровного родства
+    blood                 BOOLEAN      NOT NULL,
+    created_at            TIMESTAMP    NOT NULL DEFAULT NOW(),
+    CONSTRAINT relationship_types_tree_code_key UNIQUE (tree_id, code)
+);
+
+-- UNIQUE не сравнивает NULL, поэтому коды встроенных типов уникальны отдельным индексом
+CREATE UNIQUE INDEX idx_relationship_types_builtin_code ON relationship_types (code) WHERE tree_id IS NULL;
+
+INSERT INTO relationship_types (code, name, counts_toward_parents, blood)
+VALUES ('biological', 'Biological', TRUE, TRUE),
+       ('not_biological', 'Not biological', TRUE, FALSE),
+       ('adoptive', 'Adoptive', TRUE, FALSE),
+       ('step', 'Step', FALSE, FALSE),
+       ('foster', 'Foster', FALSE, FALSE),
+       ('guardian', 'Guardian', FALSE, FALSE),
+       ('surrogate', 'Surrogate', FALSE, FALSE),
+       ('donor', 'Donor', FALSE, TRUE);
+
+-- Входит ли связь типа rel_type в лимит двух родителей в дереве tree.
+-- Тип вне реестра (старые данные) считается, как и в сервисе.
+CREATE OR REPLACE FUNCTION relationship_counts_toward_parents(rel_type TEXT, tree INTEGER) RETURNS BOOLEAN AS
+$$
+SELECT COALESCE((SELECT counts_toward_parents
+                 FROM relationship_types
+                 WHERE code = rel_type
+                   AND (tree_id IS NULL OR tree_id = tree)
+                 LIMIT 1), TRUE);
+$$ LANGUAGE sql STABLE;
+
+-- Правила 0013 с учётом реестра: тип должен быть в реестре, а лимит и пол
+-- проверяются только среди связей, которые входят в лимит
+CREATE OR REPLACE FUNCTION check_relationship() RETURNS TRIGGER AS
+$$
+DECLARE
+    child_tree  INTEGER;
+    parent_tree INTEGER;
+    parent_male BOOLEAN;
+    counts      BOOLEAN;
+    others      INTEGER;
+    same_sex    INTEGER;
+BEGIN
+    -- Блокировка ребенка выстраивает в очередь параллельные вставки его родителей
+    SELECT tree_id INTO child_tree FROM persons WHERE id = NEW.child_id FOR UPDATE;
+    SELECT tree_id, is_male INTO parent_tree, parent_male FROM persons WHERE id = NEW.parent_id;
+
+    -- Несуществующую персону отклонит внешний ключ
+    IF child_tree IS NULL OR parent_tree IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF parent_tree <> child_tree THEN
+        RAISE EXCEPTION 'parent and child must be in the same tree'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_same_tree';
+    END IF;
+
+    SELECT counts_toward_parents
+    INTO counts
+    FROM relationship_types
+    WHERE code = NEW.relationship_type
+      AND (tree_id IS NULL OR tree_id = child_tree);
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'unknown relationship type %', NEW.relationship_type
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_type';
+    END IF;
+
+    IF NOT counts THEN
+        RETURN NEW;
+    END IF;
+
+    -- Повтор той же пары отклонит UNIQUE (parent_id, child_id)
+    SELECT COUNT(*), COUNT(*) FILTER (WHERE p.is_male = parent_male)
+    INTO others, same_sex
+    FROM relationships r
+             INNER JOIN persons p ON p.id = r.parent_id
+    WHERE r.child_id = NEW.child_id
+      AND r.id <> NEW.id
+      AND r.parent_id <> NEW.parent_id
+      AND relationship_counts_toward_parents(r.relationship_type, child_tree);
+
+    IF others >= 2 THEN
+        RAISE EXCEPTION 'child already has 2 parents'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_max_two_parents';
+    END IF;
+
+    IF same_sex > 0 THEN
+        RAISE EXCEPTION 'parents must be of different gender'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_parents_sex';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS relationships_check ON relationships;
+CREATE TRIGGER relationships_check
+    BEFORE INSERT OR UPDATE OF parent_id, child_id, relationship_type
+    ON relationships
+    FOR EACH ROW
+EXECUTE FUNCTION check_relationship();
+
+CREATE OR REPLACE FUNCTION check_person_relationships() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NEW.tree_id <> OLD.tree_id AND EXISTS (
+        SELECT 1 FROM relationships WHERE NEW.id IN (parent_id, child_id)
+    ) THEN
+        RAISE EXCEPTION 'parent and child must be in the same tree'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_same_tree';
+    END IF;
+
+    IF NEW.is_male <> OLD.is_male AND EXISTS (
+        SELECT 1
+        FROM relationships r
+                 INNER JOIN relationships o ON o.child_id = r.child_id AND o.parent_id <> r.parent_id
+                 INNER JOIN persons p ON p.id = o.parent_id
+        WHERE r.parent_id = NEW.id
+          AND p.is_male = NEW.is_male
+          AND relationship_counts_toward_parents(r.relationship_type, NEW.tree_id)
+          AND relationship_counts_toward_parents(o.relationship_type, NEW.tree_id)
+    ) THEN
+        RAISE EXCEPTION 'parents must be of different gender'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_parents_sex';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
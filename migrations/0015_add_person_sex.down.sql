@@ -0,0 +1,117 @@
+DROP TRIGGER IF EXISTS trees_check_parents_policy ON trees;
+DROP FUNCTION IF EXISTS check_tree_parents_policy();
+
+DROP TRIGGER IF EXISTS persons_check_relationships ON persons;
+
+ALTER TABLE persons
+    ADD COLUMN is_male BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- unknown и other в старой схеме не выразить и становятся false
+UPDATE persons
+SET is_male = (sex = 'male');
+
+ALTER TABLE persons
+    ALTER COLUMN is_male DROP DEFAULT;
+
+-- Возвращаем правила 0014
+CREATE OR REPLACE FUNCTION check_relationship() RETURNS TRIGGER AS
+$$
+DECLARE
+    child_tree  INTEGER;
+    parent_tree INTEGER;
+    parent_male BOOLEAN;
+    counts      BOOLEAN;
+    others      INTEGER;
+    same_sex    INTEGER;
+BEGIN
+    SELECT tree_id INTO child_tree FROM persons WHERE id = NEW.child_id FOR UPDATE;
+    SELECT tree_id, is_male INTO parent_tree, parent_male FROM persons WHERE id = NEW.parent_id;
+
+    IF child_tree IS NULL OR parent_tree IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF parent_tree <> child_tree THEN
+        RAISE EXCEPTION 'parent and child must be in the same tree'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_same_tree';
+    END IF;
+
+    SELECT counts_toward_parents
+    INTO counts
+    FROM relationship_types
+    WHERE code = NEW.relationship_type
+      AND (tree_id IS NULL OR tree_id = child_tree);
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'unknown relationship type %', NEW.relationship_type
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_type';
+    END IF;
+
+    IF NOT counts THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT COUNT(*), COUNT(*) FILTER (WHERE p.is_male = parent_male)
+    INTO others, same_sex
+    FROM relationships r
+             INNER JOIN persons p ON p.id = r.parent_id
+    WHERE r.child_id = NEW.child_id
+      AND r.id <> NEW.id
+      AND r.parent_id <> NEW.parent_id
+      AND relationship_counts_toward_parents(r.relationship_type, child_tree);
+
+    IF others >= 2 THEN
+        RAISE EXCEPTION 'child already has 2 parents'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_max_two_parents';
+    END IF;
+
+    IF same_sex > 0 THEN
+        RAISE EXCEPTION 'parents must be of different gender'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_parents_sex';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION check_person_relationships() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NEW.tree_id <> OLD.tree_id AND EXISTS (
+        SELECT 1 FROM relationships WHERE NEW.id IN (parent_id, child_id)
+    ) THEN
+        RAISE EXCEPTION 'parent and child must be in the same tree'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_same_tree';
+    END IF;
+
+    IF NEW.is_male <> OLD.is_male AND EXISTS (
+        SELECT 1
+        FROM relationships r
+                 INNER JOIN relationships o ON o.child_id = r.child_id AND o.parent_id <> r.parent_id
+                 INNER JOIN persons p ON p.id = o.parent_id
+        WHERE r.parent_id = NEW.id
+          AND p.is_male = NEW.is_male
+          AND relationship_counts_toward_parents(r.relationship_type, NEW.tree_id)
+          AND relationship_counts_toward_parents(o.relationship_type, NEW.tree_id)
+    ) THEN
+        RAISE EXCEPTION 'parents must be of different gender'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_parents_sex';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER persons_check_relationships
+    BEFORE UPDATE OF tree_id, is_male
+    ON persons
+    FOR EACH ROW
+EXECUTE FUNCTION check_person_relationships();
+
+DROP FUNCTION IF EXISTS parents_sex_conflict(TEXT, TEXT, INTEGER);
+
+ALTER TABLE persons
+    DROP COLUMN sex;
+
+ALTER TABLE trees
+    DROP COLUMN allow_same_sex_parents;
@@ -0,0 +1,154 @@
+-- Пол персоны: male, female, unknown (не указан в источниках) или other
+ALTER TABLE persons
+    ADD COLUMN sex VARCHAR(10) NOT NULL DEFAULT 'unknown'
+        CHECK (sex IN ('male', 'female', 'unknown', 'other'));
+
+-- is_male = false записывался и для неизвестного пола, но отличить их уже нельзя
+UPDATE persons
+SET sex = CASE WHEN is_male THEN 'male' ELSE 'female' END;
+
+-- Триггер 0013 ссылается на is_male и пересоздаётся ниже
+DROP TRIGGER IF EXISTS persons_check_relationships ON persons;
+
+ALTER TABLE persons
+    DROP COLUMN is_male;
+
+-- Разрешены ли в дереве два родителя одного пола
+ALTER TABLE trees
+    ADD COLUMN allow_same_sex_parents BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Пара родителей нарушает политику дерева: оба мужчины или обе женщины.
+-- Неизвестный и иной пол ни с кем не конфликтует.
+CREATE OR REPLACE FUNCTION parents_sex_conflict(a TEXT, b TEXT, tree INTEGER) RETURNS BOOLEAN AS
+$$
+SELECT a = b
+   AND a IN ('male', 'female')
+   AND NOT (SELECT allow_same_sex_parents FROM trees WHERE id = tree);
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION check_relationship() RETURNS TRIGGER AS
+$$
+DECLARE
+    child_tree  INTEGER;
+    parent_tree INTEGER;
+    parent_sex  TEXT;
+    counts      BOOLEAN;
+    others      INTEGER;
+    same_sex    INTEGER;
+BEGIN
+    -- Блокировка ребенка выстраивает в очередь параллельные вставки его родителей
+    SELECT tree_id INTO child_tree FROM persons WHERE id = NEW.child_id FOR UPDATE;
+    SELECT tree_id, sex INTO parent_tree, parent_sex FROM persons WHERE id = NEW.parent_id;
+
+    -- Несуществующую персону отклонит внешний ключ
+    IF child_tree IS NULL OR parent_tree IS NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF parent_tree <> child_tree THEN
+        RAISE EXCEPTION 'parent and child must be in the same tree'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_same_tree';
+    END IF;
+
+    SELECT counts_toward_parents
+    INTO counts
+    FROM relationship_types
+    WHERE code = NEW.relationship_type
+      AND (tree_id IS NULL OR tree_id = child_tree);
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'unknown relationship type %', NEW.relationship_type
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_type';
+    END IF;
+
+    IF NOT counts THEN
+        RETURN NEW;
+    END IF;
+
+    -- Повтор той же пары отклонит UNIQUE (parent_id, child_id)
+    SELECT COUNT(*), COUNT(*) FILTER (WHERE parents_sex_conflict(p.sex, parent_sex, child_tree))
+    INTO others, same_sex
+    FROM relationships r
+             INNER JOIN persons p ON p.id = r.parent_id
+    WHERE r.child_id = NEW.child_id
+      AND r.id <> NEW.id
+      AND r.parent_id <> NEW.parent_id
+      AND relationship_counts_toward_parents(r.relationship_type, child_tree);
+
+    IF others >= 2 THEN
+        RAISE EXCEPTION 'child already has 2 parents'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_max_two_parents';
+    END IF;
+
+    IF same_sex > 0 THEN
+        RAISE EXCEPTION 'same-sex parents are not allowed in this tree'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_parents_sex';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION check_person_relationships() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NEW.tree_id <> OLD.tree_id AND EXISTS (
+        SELECT 1 FROM relationships WHERE NEW.id IN (parent_id, child_id)
+    ) THEN
+        RAISE EXCEPTION 'parent and child must be in the same tree'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_same_tree';
+    END IF;
+
+    IF NEW.sex <> OLD.sex AND EXISTS (
+        SELECT 1
+        FROM relationships r
+                 INNER JOIN relationships o ON o.child_id = r.child_id AND o.parent_id <> r.parent_id
+                 INNER JOIN persons p ON p.id = o.parent_id
+        WHERE r.parent_id = NEW.id
+          AND parents_sex_conflict(p.sex, NEW.sex, NEW.tree_id)
+          AND relationship_counts_toward_parents(r.relationship_type, NEW.tree_id)
+          AND relationship_counts_toward_parents(o.relationship_type, NEW.tree_id)
+    ) THEN
+        RAISE EXCEPTION 'same-sex parents are not allowed in this tree'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_parents_sex';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER persons_check_relationships
+    BEFORE UPDATE OF tree_id, sex
+    ON persons
+    FOR EACH ROW
+EXECUTE FUNCTION check_person_relationships();
+
+-- Запретить однополых родителей можно, только если таких пар в дереве нет
+CREATE OR REPLACE FUNCTION check_tree_parents_policy() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF OLD.allow_same_sex_parents AND NOT NEW.allow_same_sex_parents AND EXISTS (
+        SELECT 1
+        FROM relationships r
+                 INNER JOIN relationships o ON o.child_id = r.child_id AND o.parent_id < r.parent_id
+                 INNER JOIN persons a ON a.id = r.parent_id
+                 INNER JOIN persons b ON b.id = o.parent_id
+        WHERE a.tree_id = NEW.id
+          AND a.sex = b.sex
+          AND a.sex IN ('male', 'female')
+          AND relationship_counts_toward_parents(r.relationship_type, NEW.id)
+          AND relationship_counts_toward_parents(o.relationship_type, NEW.id)
+    ) THEN
+        RAISE EXCEPTION 'tree already has same-sex parents'
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'relationships_parents_sex';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trees_check_parents_policy
+    BEFORE UPDATE OF allow_same_sex_parents
+    ON trees
+    FOR EACH ROW
+EXECUTE FUNCTION check_tree_parents_policy();
@@ -0,0 +1,6 @@
+ALTER TABLE events
+    DROP COLUMN IF EXISTS date_end_precision;
+
+ALTER TABLE persons
+    DROP COLUMN IF EXISTS birth_date_end_precision,
+    DROP COLUMN IF EXISTS death_date_end_precision;
@@ -0,0 +1,23 @@
+-- Точность второй даты BET: "BET 1820 AND MAR 1825". У остальных дат NULL.
+ALTER TABLE persons
+    ADD COLUMN birth_date_end_precision VARCHAR(5)
+        CHECK (birth_date_end_precision IN ('day', 'month', 'year')),
+    ADD COLUMN death_date_end_precision VARCHAR(5)
+        CHECK (death_date_end_precision IN ('day', 'month', 'year'));
+
+ALTER TABLE events
+    ADD COLUMN date_end_precision VARCHAR(5)
+        CHECK (date_end_precision IN ('day', 'month', 'year'));
+
+-- Существующие диапазоны форматировались с точностью первой даты
+UPDATE persons
+SET birth_date_end_precision = birth_date_precision
+WHERE birth_date_qualifier = 'between';
+
+UPDATE persons
+SET death_date_end_precision = death_date_precision
+WHERE death_date_qualifier = 'between';
+
+UPDATE events
+SET date_end_precision = date_precision
+WHERE date_qualifier = 'between';
@@ -0,0 +1,2 @@
+ALTER TABLE share_links
+    ALTER COLUMN expires_at TYPE TIMESTAMP USING expires_at AT TIME ZONE 'UTC';
@@ -0,0 +1,5 @@
+-- Срок ссылки приходит с часовым поясом, а TIMESTAMP его отбрасывал:
+-- "2026-01-01T00:00:00+03:00" сохранялся как полночь UTC.
+-- Прежние значения читались как UTC, так и переводим.
+ALTER TABLE share_links
+    ALTER COLUMN expires_at TYPE TIMESTAMPTZ USING expires_at AT TIME ZONE 'UTC';